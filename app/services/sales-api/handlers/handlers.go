@@ -10,10 +10,39 @@ import (
 
 	"github.com/jmoiron/sqlx"
 	chkgrp "github.com/tcmhoang/sservices/app/services/sales-api/handlers/debug"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/assignmentgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/availabilitygrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/blockgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/foliogrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/guestgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/holdgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/housekeepinggrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/inventorygrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/jwksgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/maintenancegrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/productgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/rateplangrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/reservationgrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/salegrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/taxgrp"
 	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/testgrp"
 	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/usergrp"
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers/v1/waitlistgrp"
+	"github.com/tcmhoang/sservices/business/core/assignment"
+	foliocore "github.com/tcmhoang/sservices/business/core/folio"
+	guestcore "github.com/tcmhoang/sservices/business/core/guest"
+	hkcore "github.com/tcmhoang/sservices/business/core/housekeeping"
+	"github.com/tcmhoang/sservices/business/core/inventory"
+	"github.com/tcmhoang/sservices/business/core/maintenance"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	productcore "github.com/tcmhoang/sservices/business/core/product"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
+	salecore "github.com/tcmhoang/sservices/business/core/sale"
+	taxcore "github.com/tcmhoang/sservices/business/core/tax"
 	usercore "github.com/tcmhoang/sservices/business/core/user"
 	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/payment"
 	"github.com/tcmhoang/sservices/business/web/mids"
 	"github.com/tcmhoang/sservices/foundation/web"
 	"go.opentelemetry.io/otel/trace"
@@ -49,11 +78,13 @@ func DebugMux(build string, log *zap.SugaredLogger, db *sqlx.DB) http.Handler {
 }
 
 type APIMuxConfig struct {
-	Shutdown chan os.Signal
-	Log      *zap.SugaredLogger
-	Auth     *auth.Auth
-	DB       *sqlx.DB
-	Tracer   trace.Tracer
+	Shutdown    chan os.Signal
+	Log         *zap.SugaredLogger
+	Auth        *auth.Auth
+	Revocations *revcore.Core
+	DB          *sqlx.DB
+	Payment     payment.Gateway
+	Tracer      trace.Tracer
 }
 
 func APIMux(cfg APIMuxConfig) *web.App {
@@ -67,6 +98,7 @@ func APIMux(cfg APIMuxConfig) *web.App {
 	)
 
 	v1(app, cfg)
+	wellKnown(app, cfg)
 
 	return app
 
@@ -86,12 +118,153 @@ func v1(app *web.App, cfg APIMuxConfig) {
 		mids.Authorize(auth.Admin),
 	)
 
-	ugh := usergrp.New(usercore.NewCore(cfg.Log, cfg.DB), cfg.Auth)
+	ugh := usergrp.New(usercore.NewCore(cfg.Log, cfg.DB), cfg.Revocations, cfg.Auth)
 	app.Handle(http.MethodGet, ver, "/users/token", ugh.Token)
+	app.Handle(http.MethodPost, ver, "/users/token/refresh", ugh.Refresh)
+	app.Handle(http.MethodPost, ver, "/users/logout", ugh.Logout)
+	app.Handle(http.MethodPost, ver, "/users/logout/all", ugh.LogoutAll, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/users/token/revoke", ugh.RevokeToken, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/users/:user_id/revoke", ugh.RevokeUser, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/users/:user_id/unlock", ugh.Unlock, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
 	app.Handle(http.MethodGet, ver, "/users", ugh.Query, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
 	app.Handle(http.MethodGet, ver, "/users/:user_id", ugh.QueryByID, mids.Authenticate(cfg.Auth))
 	app.Handle(http.MethodPost, ver, "/users", ugh.Create, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
 	app.Handle(http.MethodPut, ver, "/users/:user_id", ugh.Update, mids.Authenticate(cfg.Auth))
 	app.Handle(http.MethodDelete, ver, "/users/:user_id", ugh.Delete, mids.Authenticate(cfg.Auth))
 
+	prdCore := productcore.NewCore(cfg.Log, cfg.DB)
+
+	pgh := productgrp.New(prdCore)
+	app.Handle(http.MethodGet, ver, "/products", pgh.Query, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/products/:product_id", pgh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/users/:user_id/products", pgh.QueryByUserID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/products", pgh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPut, ver, "/products/:product_id", pgh.Update, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodDelete, ver, "/products/:product_id", pgh.Delete, mids.Authenticate(cfg.Auth))
+
+	sgh := salegrp.New(salecore.NewCore(cfg.Log, cfg.DB, cfg.Payment), prdCore)
+	app.Handle(http.MethodPost, ver, "/sales", sgh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/sales/:sale_id", sgh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/users/:user_id/sales", sgh.QueryByUserID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/products/:product_id/sales", sgh.QueryByProductID, mids.Authenticate(cfg.Auth))
+
+	igh := inventorygrp.New(inventory.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/properties", igh.QueryProperties)
+	app.Handle(http.MethodGet, ver, "/properties/:property_id", igh.QueryPropertyByID)
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/roomtypes", igh.QueryRoomTypes)
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/rooms", igh.QueryRooms)
+	app.Handle(http.MethodPost, ver, "/properties", igh.CreateProperty, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/properties/:property_id", igh.UpdateProperty, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/properties/:property_id", igh.DeleteProperty, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/roomtypes/:room_type_id", igh.QueryRoomTypeByID)
+	app.Handle(http.MethodPost, ver, "/roomtypes", igh.CreateRoomType, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/roomtypes/:room_type_id", igh.UpdateRoomType, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/roomtypes/:room_type_id", igh.DeleteRoomType, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/rooms/:room_id", igh.QueryRoomByID)
+	app.Handle(http.MethodPost, ver, "/rooms", igh.CreateRoom, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/rooms/:room_id", igh.UpdateRoom, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/rooms/:room_id", igh.DeleteRoom, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	rpgh := rateplangrp.New(pricing.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/roomtypes/:room_type_id/rateplans", rpgh.QueryByRoomTypeID)
+	app.Handle(http.MethodGet, ver, "/rateplans/:rate_plan_id", rpgh.QueryByID)
+	app.Handle(http.MethodGet, ver, "/rateplans/:rate_plan_id/quote", rpgh.Quote)
+	app.Handle(http.MethodGet, ver, "/rateplans/:rate_plan_id/calendar", rpgh.QueryOverrides)
+	app.Handle(http.MethodPost, ver, "/rateplans", rpgh.Create, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/rateplans/:rate_plan_id", rpgh.Update, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/rateplans/:rate_plan_id", rpgh.Delete, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/rateplans/:rate_plan_id/weekdays", rpgh.SetWeekdayRate, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/rateplans/:rate_plan_id/calendar", rpgh.SetOverride, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	resCore := rescore.NewCore(cfg.Log, cfg.DB, cfg.Payment)
+
+	agh := availabilitygrp.New(resCore)
+	app.Handle(http.MethodGet, ver, "/availability", agh.Query)
+
+	hgh := holdgrp.New(resCore)
+	app.Handle(http.MethodPost, ver, "/holds", hgh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/holds/:hold_id", hgh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/holds/:hold_id/extend", hgh.Extend, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/holds/:hold_id/confirm", hgh.Confirm, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodDelete, ver, "/holds/:hold_id", hgh.Release, mids.Authenticate(cfg.Auth))
+
+	wgh := waitlistgrp.New(resCore)
+	app.Handle(http.MethodPost, ver, "/waitlist", wgh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/waitlist/:waitlist_id", wgh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/users/:user_id/waitlist", wgh.QueryByUserID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodDelete, ver, "/waitlist/:waitlist_id", wgh.Delete, mids.Authenticate(cfg.Auth))
+
+	bgh := blockgrp.New(resCore)
+	app.Handle(http.MethodPost, ver, "/blocks", bgh.Create, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/blocks/:block_id", bgh.QueryByID, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/blocks", bgh.QueryByPropertyID, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/blocks/:block_id/reservations", bgh.QueryReservations, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/blocks/:block_id/release", bgh.Release, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/pickups", bgh.PickUp, mids.Authenticate(cfg.Auth))
+
+	rgh := reservationgrp.New(resCore)
+	app.Handle(http.MethodPost, ver, "/reservations", rgh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/reservations/:reservation_id", rgh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/users/:user_id/reservations", rgh.QueryByUserID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/reservations/:reservation_id/history", rgh.QueryHistory, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/confirm", rgh.Confirm, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/cancel", rgh.Cancel, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/checkin", rgh.CheckIn, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/checkout", rgh.CheckOut, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/noshow", rgh.NoShow, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	ggh := guestgrp.New(guestcore.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/guests", ggh.Query, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/guests/:guest_id", ggh.QueryByID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/users/:user_id/guests", ggh.QueryByUserID, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/guests", ggh.Create, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPut, ver, "/guests/:guest_id", ggh.Update, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodDelete, ver, "/guests/:guest_id", ggh.Delete, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodGet, ver, "/reservations/:reservation_id/guests", ggh.QueryOccupants, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/guests", ggh.AddOccupant, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodDelete, ver, "/reservations/:reservation_id/guests/:guest_id", ggh.RemoveOccupant, mids.Authenticate(cfg.Auth))
+
+	txgh := taxgrp.New(taxcore.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/taxrules", txgh.Query)
+	app.Handle(http.MethodGet, ver, "/taxrules/:tax_rule_id", txgh.QueryByID)
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/taxrules", txgh.QueryByPropertyID)
+	app.Handle(http.MethodPost, ver, "/taxrules", txgh.Create, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/taxrules/:tax_rule_id", txgh.Update, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/taxrules/:tax_rule_id", txgh.Delete, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	fgh := foliogrp.New(foliocore.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/reservations/:reservation_id/folio", fgh.Query, mids.Authenticate(cfg.Auth))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/folio/extras", fgh.PostExtra, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/folio/charges", fgh.PostCharge, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/folio/payments", fgh.PostPayment, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPost, ver, "/reservations/:reservation_id/folio/refunds", fgh.PostRefund, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	hkgh := housekeepinggrp.New(hkcore.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/housekeeping", hkgh.Board, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin, auth.Housekeeping))
+	app.Handle(http.MethodPut, ver, "/rooms/:room_id/housekeeping", hkgh.SetStatus, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin, auth.Housekeeping))
+	app.Handle(http.MethodGet, ver, "/housekeeping/tasks", hkgh.QueryTasks, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Housekeeping))
+	app.Handle(http.MethodPost, ver, "/housekeeping/tasks", hkgh.Assign, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/housekeeping/tasks/:task_id", hkgh.DeleteTask, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	asgh := assignmentgrp.New(assignment.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodPost, ver, "/properties/:property_id/assignments", asgh.Arrivals, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodPut, ver, "/reservations/:reservation_id/room", asgh.Override, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+	mgh := maintenancegrp.New(maintenance.NewCore(cfg.Log, cfg.DB))
+	app.Handle(http.MethodPost, ver, "/outoforder", mgh.Create, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodGet, ver, "/properties/:property_id/outoforder", mgh.QueryByPropertyID, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+	app.Handle(http.MethodDelete, ver, "/outoforder/:period_id", mgh.Delete, mids.Authenticate(cfg.Auth), mids.Authorize(auth.Admin))
+
+}
+
+// wellKnown registers the unversioned documents other services discover us
+// by. The keys are only published when the key store can describe them.
+func wellKnown(app *web.App, cfg APIMuxConfig) {
+	keys, ok := cfg.Auth.KeyLookup.(jwksgrp.KeySet)
+	if !ok {
+		return
+	}
+
+	jgh := jwksgrp.New(keys)
+	app.Handle(http.MethodGet, "", "/.well-known/jwks.json", jgh.Query)
 }
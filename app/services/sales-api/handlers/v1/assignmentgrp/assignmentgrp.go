@@ -0,0 +1,87 @@
+// Package assignmentgrp maintains the group of handlers for assigning rooms
+// to arriving reservations.
+package assignmentgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/assignment"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	assignment *assignment.Core
+}
+
+func New(assignment *assignment.Core) *Handlers {
+	return &Handlers{
+		assignment: assignment,
+	}
+}
+
+// Arrivals is restricted to staff by its route. It assigns rooms to the
+// property's arrivals on the day in the query string, today by default.
+func (h *Handlers) Arrivals(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	now := time.Now()
+	day := now
+
+	if qs := r.URL.Query().Get("day"); qs != "" {
+		if day, err = time.Parse(date.Layout, qs); err != nil {
+			return validation.NewRequestError(fmt.Errorf("invalid day format [%s]", qs), http.StatusBadRequest)
+		}
+	}
+
+	p, err := h.assignment.Arrivals(ctx, propertyID, day, now)
+	if err != nil {
+		return fmt.Errorf("arrivals: propertyID[%s] day[%s]: %w", propertyID, day.Format(date.Layout), err)
+	}
+
+	return web.Respond(ctx, w, p, http.StatusOK)
+}
+
+// Override is restricted to staff by its route. It puts the reservation in
+// the room of the body, or takes its room away when the body names none.
+func (h *Handlers) Override(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	var ar reservation.AssignRoom
+	if err := web.Decode(r, &ar); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	res, err := h.assignment.Override(ctx, reservationID, ar, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound),
+			errors.Is(err, room.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, assignment.ErrWrongRoom):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, assignment.ErrNotAssignable),
+			errors.Is(err, assignment.ErrOutOfService),
+			errors.Is(err, assignment.ErrRoomTaken):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("override: reservationID[%s] ar[%+v]: %w", reservationID, ar, err)
+		}
+	}
+
+	return web.Respond(ctx, w, res, http.StatusOK)
+}
@@ -0,0 +1,96 @@
+// Package availabilitygrp maintains the group of handlers for searching room
+// availability.
+package availabilitygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/availability"
+	"github.com/tcmhoang/sservices/business/data/store/fxrate"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	reservation *rescore.Core
+}
+
+func New(reservation *rescore.Core) *Handlers {
+	return &Handlers{
+		reservation: reservation,
+	}
+}
+
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qs := r.URL.Query()
+
+	page := qs.Get("page")
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid page format [%s]", page), http.StatusBadRequest)
+	}
+
+	rows := qs.Get("rows")
+	rowsPerPage, err := strconv.Atoi(rows)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid rows format [%s]", rows), http.StatusBadRequest)
+	}
+
+	if pageNumber < 1 || rowsPerPage < 1 {
+		return validation.NewRequestError(fmt.Errorf("page [%d] and rows [%d] must be at least 1", pageNumber, rowsPerPage), http.StatusBadRequest)
+	}
+
+	var filter availability.Filter
+
+	checkIn := qs.Get("checkin")
+	if filter.CheckIn, err = time.Parse(date.Layout, checkIn); err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid checkin format [%s]", checkIn), http.StatusBadRequest)
+	}
+
+	checkOut := qs.Get("checkout")
+	if filter.CheckOut, err = time.Parse(date.Layout, checkOut); err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid checkout format [%s]", checkOut), http.StatusBadRequest)
+	}
+
+	if filter.CheckOut.After(filter.CheckIn.AddDate(0, 0, availability.MaxNights)) {
+		return validation.NewRequestError(fmt.Errorf("stay cannot be longer than %d nights", availability.MaxNights), http.StatusBadRequest)
+	}
+
+	filter.Guests = 1
+	if guests := qs.Get("guests"); guests != "" {
+		if filter.Guests, err = strconv.Atoi(guests); err != nil {
+			return validation.NewRequestError(fmt.Errorf("invalid guests format [%s]", guests), http.StatusBadRequest)
+		}
+	}
+
+	filter.Currency = strings.ToUpper(qs.Get("currency"))
+
+	if prop := qs.Get("property"); prop != "" {
+		propertyID, err := uuid.Parse(prop)
+		if err != nil {
+			return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+		}
+		filter.PropertyID = &propertyID
+	}
+
+	offers, err := h.reservation.Search(ctx, filter, pageNumber, rowsPerPage)
+	if err != nil {
+		switch {
+		case errors.Is(err, fxrate.ErrNotFound):
+			return validation.NewRequestError(fmt.Errorf("no exchange rate to [%s]", filter.Currency), http.StatusBadRequest)
+		default:
+			return fmt.Errorf("search: filter[%+v]: %w", filter, err)
+		}
+	}
+
+	return web.Respond(ctx, w, offers, http.StatusOK)
+}
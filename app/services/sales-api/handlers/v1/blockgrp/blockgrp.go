@@ -0,0 +1,157 @@
+// Package blockgrp maintains the group of handlers for room blocks and the
+// rooms attendees pick up from them.
+package blockgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/block"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	reservation *rescore.Core
+}
+
+func New(reservation *rescore.Core) *Handlers {
+	return &Handlers{
+		reservation: reservation,
+	}
+}
+
+// Create is restricted to staff by its route.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nb block.NewBlock
+	if err := web.Decode(r, &nb); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	b, err := h.reservation.CreateBlock(ctx, nb)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrUnavailable),
+			errors.Is(err, block.ErrCodeTaken):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("createblock: nb[%+v]: %w", nb, err)
+		}
+	}
+
+	return web.Respond(ctx, w, b, http.StatusCreated)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	blockID, err := uuid.Parse(web.Param(r, "block_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	b, err := h.reservation.Block.QueryByID(ctx, blockID)
+	if err != nil {
+		switch {
+		case errors.Is(err, block.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", blockID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, b, http.StatusOK)
+}
+
+func (h *Handlers) QueryByPropertyID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	bs, err := h.reservation.Block.QueryByPropertyID(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s]: %w", propertyID, err)
+	}
+
+	return web.Respond(ctx, w, bs, http.StatusOK)
+}
+
+// QueryReservations lists the reservations picked up from the block.
+func (h *Handlers) QueryReservations(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	blockID, err := uuid.Parse(web.Param(r, "block_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	ress, err := h.reservation.Store.QueryByBlockID(ctx, blockID)
+	if err != nil {
+		return fmt.Errorf("blockID[%s]: %w", blockID, err)
+	}
+
+	return web.Respond(ctx, w, ress, http.StatusOK)
+}
+
+// Release gives the rooms nobody picked up back ahead of the cutoff.
+func (h *Handlers) Release(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	blockID, err := uuid.Parse(web.Param(r, "block_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	b, err := h.reservation.ReleaseBlock(ctx, blockID)
+	if err != nil {
+		switch {
+		case errors.Is(err, block.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrBlockReleased):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("releaseblock: ID[%s]: %w", blockID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, b, http.StatusOK)
+}
+
+// PickUp books a room out of a block for the caller with the block's code.
+func (h *Handlers) PickUp(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var p block.Pickup
+	if err := web.Decode(r, &p); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	p.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation.PickUp(ctx, p)
+	if err != nil {
+		switch {
+		case errors.Is(err, block.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrCapacity),
+			errors.Is(err, rescore.ErrOutsideBlock):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rescore.ErrBlockReleased),
+			errors.Is(err, rescore.ErrBlockFull):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("pickup: userID[%s]: %w", p.UserID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, res, http.StatusCreated)
+}
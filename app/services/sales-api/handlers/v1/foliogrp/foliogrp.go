@@ -0,0 +1,181 @@
+// Package foliogrp maintains the group of handlers for the folios of
+// reservations.
+package foliogrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	foliocore "github.com/tcmhoang/sservices/business/core/folio"
+	"github.com/tcmhoang/sservices/business/data/store/folio"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	folio *foliocore.Core
+}
+
+func New(folio *foliocore.Core) *Handlers {
+	return &Handlers{
+		folio: folio,
+	}
+}
+
+// Query returns the folio of a reservation as JSON, or as a printable plain
+// text invoice with format=text.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	res, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		st, err := h.folio.Statement(ctx, res.ID)
+		if err != nil {
+			return fmt.Errorf("statement: reservationID[%s]: %w", res.ID, err)
+		}
+
+		return web.Respond(ctx, w, st, http.StatusOK)
+
+	case "text":
+		inv, err := h.folio.Invoice(ctx, res.ID)
+		if err != nil {
+			return fmt.Errorf("invoice: reservationID[%s]: %w", res.ID, err)
+		}
+
+		var b strings.Builder
+		if err := foliocore.Render(&b, inv); err != nil {
+			return fmt.Errorf("render: reservationID[%s]: %w", res.ID, err)
+		}
+
+		return web.RespondText(ctx, w, b.String(), http.StatusOK)
+
+	default:
+		return validation.NewRequestError(fmt.Errorf("invalid format [%s]", format), http.StatusBadRequest)
+	}
+}
+
+// PostExtra is restricted to staff by its route.
+func (h *Handlers) PostExtra(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ne folio.NewExtra
+	if err := web.Decode(r, &ne); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.post(ctx, w, r, func(reservationID uuid.UUID, userID uuid.UUID) (foliocore.Statement, error) {
+		return h.folio.PostExtra(ctx, reservationID, ne, userID, time.Now())
+	})
+}
+
+// PostCharge is restricted to staff by its route.
+func (h *Handlers) PostCharge(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nc folio.NewCharge
+	if err := web.Decode(r, &nc); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.post(ctx, w, r, func(reservationID uuid.UUID, userID uuid.UUID) (foliocore.Statement, error) {
+		return h.folio.PostCharge(ctx, reservationID, nc, userID, time.Now())
+	})
+}
+
+// PostPayment is restricted to staff by its route.
+func (h *Handlers) PostPayment(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var np folio.NewPayment
+	if err := web.Decode(r, &np); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.post(ctx, w, r, func(reservationID uuid.UUID, userID uuid.UUID) (foliocore.Statement, error) {
+		return h.folio.PostPayment(ctx, reservationID, np, userID, time.Now())
+	})
+}
+
+// PostRefund is restricted to staff by its route.
+func (h *Handlers) PostRefund(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var np folio.NewPayment
+	if err := web.Decode(r, &np); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	return h.post(ctx, w, r, func(reservationID uuid.UUID, userID uuid.UUID) (foliocore.Statement, error) {
+		return h.folio.PostRefund(ctx, reservationID, np, userID, time.Now())
+	})
+}
+
+// post runs a posting against the folio of the reservation in the request
+// path on behalf of the caller.
+func (h *Handlers) post(ctx context.Context, w http.ResponseWriter, r *http.Request, fn func(uuid.UUID, uuid.UUID) (foliocore.Statement, error)) error {
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	st, err := fn(reservationID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound),
+			errors.Is(err, product.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, foliocore.ErrNotBillable),
+			errors.Is(err, foliocore.ErrOverRefund):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("post: reservationID[%s]: %w", reservationID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, st, http.StatusCreated)
+}
+
+// authorize loads the reservation in the request path and checks the caller
+// is either an admin or the guest who owns it.
+func (h *Handlers) authorize(ctx context.Context, r *http.Request) (reservation.Reservation, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return reservation.Reservation{}, errors.New("claims missing from ctx")
+	}
+
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return reservation.Reservation{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.folio.Reservation.QueryByID(ctx, reservationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound):
+			return reservation.Reservation{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return reservation.Reservation{}, fmt.Errorf("ID[%s]: %w", reservationID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != res.UserID.String() {
+		return reservation.Reservation{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,336 @@
+// Package guestgrp maintains the group of handlers for guest profiles and the
+// guests staying on reservations.
+package guestgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	guestcore "github.com/tcmhoang/sservices/business/core/guest"
+	"github.com/tcmhoang/sservices/business/data/store/guest"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	guest *guestcore.Core
+}
+
+func New(guest *guestcore.Core) *Handlers {
+	return &Handlers{
+		guest: guest,
+	}
+}
+
+// =============================================================================
+// Guest profiles
+
+// Query searches guest profiles. It is restricted to staff by its route.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	qs := r.URL.Query()
+
+	page := qs.Get("page")
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid page format [%s]", page), http.StatusBadRequest)
+	}
+
+	rows := qs.Get("rows")
+	rowsPerPage, err := strconv.Atoi(rows)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid rows format [%s]", rows), http.StatusBadRequest)
+	}
+
+	if pageNumber < 1 || rowsPerPage < 1 {
+		return validation.NewRequestError(fmt.Errorf("page [%d] and rows [%d] must be at least 1", pageNumber, rowsPerPage), http.StatusBadRequest)
+	}
+
+	filter := guest.Filter{
+		Name:           qs.Get("name"),
+		Email:          qs.Get("email"),
+		Phone:          qs.Get("phone"),
+		DocumentNumber: database.Secret(qs.Get("document")),
+	}
+
+	gs, err := h.guest.Store.Query(ctx, filter, pageNumber, rowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: filter[%+v]: %w", filter, err)
+	}
+
+	return web.Respond(ctx, w, gs, http.StatusOK)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	g, err := h.profile(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, g, http.StatusOK)
+}
+
+func (h *Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != userID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	gs, err := h.guest.Store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, gs, http.StatusOK)
+}
+
+// Create adds a guest profile. Staff may link it to any account, everyone
+// else only creates profiles managed by their own account.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var ng guest.NewGuest
+	if err := web.Decode(r, &ng); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) {
+		userID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+		}
+		ng.UserID = &userID
+	}
+
+	g, err := h.guest.Store.Create(ctx, ng)
+	if err != nil {
+		return fmt.Errorf("create: ng[%+v]: %w", ng, err)
+	}
+
+	return web.Respond(ctx, w, g, http.StatusCreated)
+}
+
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ug guest.UpdateGuest
+	if err := web.Decode(r, &ug); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	g, err := h.profile(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	g, err = h.guest.Store.Update(ctx, g, ug)
+	if err != nil {
+		return fmt.Errorf("update: guestID[%s] ug[%+v]: %w", g.ID, ug, err)
+	}
+
+	return web.Respond(ctx, w, g, http.StatusOK)
+}
+
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	guestID, err := uuid.Parse(web.Param(r, "guest_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	g, err := h.guest.Store.QueryByID(ctx, guestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, guest.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: guestID[%s]: %w", guestID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && (g.UserID == nil || claims.Subject != g.UserID.String()) {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	if err := h.guest.Store.Delete(ctx, g); err != nil {
+		switch {
+		case errors.Is(err, guest.ErrInUse):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("delete: guestID[%s]: %w", g.ID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+// Reservation guests
+
+func (h *Handlers) QueryOccupants(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	res, err := h.reservation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	ocs, err := h.guest.Store.QueryOccupants(ctx, res.ID)
+	if err != nil {
+		return fmt.Errorf("queryoccupants: reservationID[%s]: %w", res.ID, err)
+	}
+
+	return web.Respond(ctx, w, ocs, http.StatusOK)
+}
+
+// AddOccupant puts a guest on a reservation. Guests may only add profiles
+// their own account manages to their own reservations.
+func (h *Handlers) AddOccupant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var no guest.NewOccupant
+	if err := web.Decode(r, &no); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if !claims.Authorized(auth.Admin) {
+		g, err := h.guest.Store.QueryByID(ctx, no.GuestID)
+		if err != nil {
+			switch {
+			case errors.Is(err, guest.ErrNotFound):
+				return validation.NewRequestError(err, http.StatusNotFound)
+			default:
+				return fmt.Errorf("querybyid: guestID[%s]: %w", no.GuestID, err)
+			}
+		}
+
+		if g.UserID == nil || claims.Subject != g.UserID.String() {
+			return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+		}
+	}
+
+	ocs, err := h.guest.AddOccupant(ctx, res.ID, no)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound),
+			errors.Is(err, guest.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, guest.ErrAlreadyAttached),
+			errors.Is(err, guestcore.ErrTooManyGuests),
+			errors.Is(err, guestcore.ErrPrimaryTaken):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("addoccupant: reservationID[%s] no[%+v]: %w", res.ID, no, err)
+		}
+	}
+
+	return web.Respond(ctx, w, ocs, http.StatusCreated)
+}
+
+func (h *Handlers) RemoveOccupant(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	res, err := h.reservation(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	guestID, err := uuid.Parse(web.Param(r, "guest_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if err := h.guest.Store.RemoveOccupant(ctx, res.ID, guestID); err != nil {
+		return fmt.Errorf("removeoccupant: reservationID[%s] guestID[%s]: %w", res.ID, guestID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+// profile loads the guest profile in the request path and checks the caller
+// is either an admin or the account that manages it.
+func (h *Handlers) profile(ctx context.Context, r *http.Request) (guest.Guest, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return guest.Guest{}, errors.New("claims missing from ctx")
+	}
+
+	guestID, err := uuid.Parse(web.Param(r, "guest_id"))
+	if err != nil {
+		return guest.Guest{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	g, err := h.guest.Store.QueryByID(ctx, guestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, guest.ErrNotFound):
+			return guest.Guest{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return guest.Guest{}, fmt.Errorf("querybyid: guestID[%s]: %w", guestID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && (g.UserID == nil || claims.Subject != g.UserID.String()) {
+		return guest.Guest{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return g, nil
+}
+
+// reservation loads the reservation in the request path and checks the
+// caller is either an admin or the guest who owns it.
+func (h *Handlers) reservation(ctx context.Context, r *http.Request) (reservation.Reservation, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return reservation.Reservation{}, errors.New("claims missing from ctx")
+	}
+
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return reservation.Reservation{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.guest.Reservation.QueryByID(ctx, reservationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound):
+			return reservation.Reservation{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return reservation.Reservation{}, fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != res.UserID.String() {
+		return reservation.Reservation{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return res, nil
+}
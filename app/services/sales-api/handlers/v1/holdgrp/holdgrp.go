@@ -0,0 +1,190 @@
+// Package holdgrp maintains the group of handlers for room holds.
+package holdgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/hold"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	reservation *rescore.Core
+}
+
+func New(reservation *rescore.Core) *Handlers {
+	return &Handlers{
+		reservation: reservation,
+	}
+}
+
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var nh hold.NewHold
+	if err := web.Decode(r, &nh); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	nh.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	hld, err := h.reservation.PlaceHold(ctx, nh)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound),
+			errors.Is(err, rateplan.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrCapacity),
+			errors.Is(err, rescore.ErrRatePlanMismatch),
+			errors.Is(err, pricing.ErrMinStay),
+			errors.Is(err, pricing.ErrClosedToArrival):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rescore.ErrUnavailable):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("placehold: nh[%+v]: %w", nh, err)
+		}
+	}
+
+	return web.Respond(ctx, w, hld, http.StatusCreated)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	hld, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, hld, http.StatusOK)
+}
+
+func (h *Handlers) Extend(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	hld, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	var eh hold.ExtendHold
+	if err := web.Decode(r, &eh); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	ext, err := h.reservation.ExtendHold(ctx, hld.ID, eh)
+	if err != nil {
+		switch {
+		case errors.Is(err, rescore.ErrHoldInactive):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("extendhold: ID[%s]: %w", hld.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, ext, http.StatusOK)
+}
+
+// Release gives the room back. Releasing a hold that is no longer active is
+// not an error since the room is free either way.
+func (h *Handlers) Release(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	hld, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.reservation.ReleaseHold(ctx, hld.ID); err != nil {
+		switch {
+		case errors.Is(err, rescore.ErrHoldInactive):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("releasehold: ID[%s]: %w", hld.ID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// Confirm books the held room and authorizes its price on the payment source
+// in the body. Declined payments surface as a *payment.DeclineError.
+func (h *Handlers) Confirm(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var cr reservation.ConfirmReservation
+	if err := web.Decode(r, &cr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	hld, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation.ConfirmHold(ctx, hld.ID, userID, cr)
+	if err != nil {
+		switch {
+		case errors.Is(err, rescore.ErrHoldInactive):
+			return validation.NewRequestError(err, http.StatusConflict)
+		case errors.Is(err, rescore.ErrUnavailable):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("confirmhold: ID[%s]: %w", hld.ID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, res, http.StatusCreated)
+}
+
+// authorize loads the hold in the request path and checks the caller is
+// either an admin or the guest who placed it.
+func (h *Handlers) authorize(ctx context.Context, r *http.Request) (hold.Hold, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return hold.Hold{}, errors.New("claims missing from ctx")
+	}
+
+	holdID, err := uuid.Parse(web.Param(r, "hold_id"))
+	if err != nil {
+		return hold.Hold{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	hld, err := h.reservation.Hold.QueryByID(ctx, holdID)
+	if err != nil {
+		switch {
+		case errors.Is(err, hold.ErrNotFound):
+			return hold.Hold{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return hold.Hold{}, fmt.Errorf("ID[%s]: %w", holdID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != hld.UserID.String() {
+		return hold.Hold{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return hld, nil
+}
@@ -0,0 +1,188 @@
+// Package housekeepinggrp maintains the group of handlers for the cleaning
+// status of rooms and the tasks of housekeeping staff.
+package housekeepinggrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	hkcore "github.com/tcmhoang/sservices/business/core/housekeeping"
+	"github.com/tcmhoang/sservices/business/data/store/housekeeping"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	housekeeping *hkcore.Core
+}
+
+func New(housekeeping *hkcore.Core) *Handlers {
+	return &Handlers{
+		housekeeping: housekeeping,
+	}
+}
+
+// Board lists every room of the property with its housekeeping status on the
+// day in the query string, today by default.
+func (h *Handlers) Board(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	day, err := parseDay(r)
+	if err != nil {
+		return err
+	}
+
+	rs, err := h.housekeeping.Board(ctx, propertyID, day)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s] day[%s]: %w", propertyID, day.Format(date.Layout), err)
+	}
+
+	return web.Respond(ctx, w, rs, http.StatusOK)
+}
+
+// SetStatus is restricted to staff by its route. Only admins may mark a room
+// inspected. Illegal moves surface as a *hkcore.TransitionError.
+func (h *Handlers) SetStatus(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	roomID, err := uuid.Parse(web.Param(r, "room_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	var uh room.UpdateHousekeeping
+	if err := web.Decode(r, &uh); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if uh.Status == room.HousekeepingInspected && !claims.Authorized(auth.Admin) {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	rm, err := h.housekeeping.SetStatus(ctx, roomID, uh, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, room.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("setstatus: roomID[%s] uh[%+v]: %w", roomID, uh, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rm, http.StatusOK)
+}
+
+// Assign is restricted to staff by its route.
+func (h *Handlers) Assign(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var nt housekeeping.NewTask
+	if err := web.Decode(r, &nt); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if nt.UserID, err = uuid.Parse(claims.Subject); err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	t, err := h.housekeeping.Assign(ctx, nt)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound),
+			errors.Is(err, housekeeping.ErrUnknownRoom):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, hkcore.ErrNotHousekeeper):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, housekeeping.ErrAlreadyTasked):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("assign: nt[%+v]: %w", nt, err)
+		}
+	}
+
+	return web.Respond(ctx, w, t, http.StatusCreated)
+}
+
+// QueryTasks lists the caller's own tasks on the day in the query string,
+// today by default.
+func (h *Handlers) QueryTasks(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	assigneeID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	day, err := parseDay(r)
+	if err != nil {
+		return err
+	}
+
+	ts, err := h.housekeeping.Store.QueryByAssignee(ctx, assigneeID, day)
+	if err != nil {
+		return fmt.Errorf("assigneeID[%s] day[%s]: %w", assigneeID, day.Format(date.Layout), err)
+	}
+
+	return web.Respond(ctx, w, ts, http.StatusOK)
+}
+
+// DeleteTask is restricted to staff by its route.
+func (h *Handlers) DeleteTask(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	taskID, err := uuid.Parse(web.Param(r, "task_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	t, err := h.housekeeping.Store.QueryByID(ctx, taskID)
+	if err != nil {
+		switch {
+		case errors.Is(err, housekeeping.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: taskID[%s]: %w", taskID, err)
+		}
+	}
+
+	if err := h.housekeeping.Store.Delete(ctx, t); err != nil {
+		return fmt.Errorf("delete: taskID[%s]: %w", taskID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// parseDay reads the day parameter of the query string, defaulting to today.
+func parseDay(r *http.Request) (time.Time, error) {
+	qs := r.URL.Query().Get("day")
+	if qs == "" {
+		return time.Now(), nil
+	}
+
+	day, err := time.Parse(date.Layout, qs)
+	if err != nil {
+		return time.Time{}, validation.NewRequestError(fmt.Errorf("invalid day format [%s]", qs), http.StatusBadRequest)
+	}
+
+	return day, nil
+}
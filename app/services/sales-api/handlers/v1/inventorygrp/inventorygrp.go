@@ -0,0 +1,374 @@
+// Package inventorygrp maintains the group of handlers for properties, room
+// types and rooms.
+package inventorygrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/inventory"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	inventory *inventory.Core
+}
+
+func New(inventory *inventory.Core) *Handlers {
+	return &Handlers{
+		inventory: inventory,
+	}
+}
+
+// =============================================================================
+// Properties
+
+func (h *Handlers) QueryProperties(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page := r.URL.Query().Get("page")
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid page format [%s]", page), http.StatusBadRequest)
+	}
+
+	rows := r.URL.Query().Get("rows")
+	rowsPerPage, err := strconv.Atoi(rows)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid rows format [%s]", rows), http.StatusBadRequest)
+	}
+
+	props, err := h.inventory.Property.Query(ctx, pageNumber, rowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, props, http.StatusOK)
+}
+
+func (h *Handlers) QueryPropertyByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prop, err := h.property(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, prop, http.StatusOK)
+}
+
+func (h *Handlers) CreateProperty(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var np property.NewProperty
+	if err := web.Decode(r, &np); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	prop, err := h.inventory.Property.Create(ctx, np)
+	if err != nil {
+		return fmt.Errorf("create: np[%+v]: %w", np, err)
+	}
+
+	return web.Respond(ctx, w, prop, http.StatusCreated)
+}
+
+func (h *Handlers) UpdateProperty(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var up property.UpdateProperty
+	if err := web.Decode(r, &up); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	prop, err := h.property(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	prop, err = h.inventory.Property.Update(ctx, prop, up)
+	if err != nil {
+		return fmt.Errorf("update: propertyID[%s] up[%+v]: %w", prop.ID, up, err)
+	}
+
+	return web.Respond(ctx, w, prop, http.StatusOK)
+}
+
+func (h *Handlers) DeleteProperty(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prop, err := h.inventory.Property.QueryByID(ctx, propertyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, property.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: propertyID[%s]: %w", propertyID, err)
+		}
+	}
+
+	if err := h.inventory.Property.Delete(ctx, prop); err != nil {
+		switch {
+		case errors.Is(err, property.ErrInUse):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("delete: propertyID[%s]: %w", propertyID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+// Room types
+
+func (h *Handlers) QueryRoomTypes(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prop, err := h.property(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	rts, err := h.inventory.RoomType.QueryByPropertyID(ctx, prop.ID)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s]: %w", prop.ID, err)
+	}
+
+	return web.Respond(ctx, w, rts, http.StatusOK)
+}
+
+func (h *Handlers) QueryRoomTypeByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rt, err := h.roomType(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, rt, http.StatusOK)
+}
+
+func (h *Handlers) CreateRoomType(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nrt roomtype.NewRoomType
+	if err := web.Decode(r, &nrt); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rt, err := h.inventory.CreateRoomType(ctx, nrt)
+	if err != nil {
+		switch {
+		case errors.Is(err, property.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("create: nrt[%+v]: %w", nrt, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rt, http.StatusCreated)
+}
+
+func (h *Handlers) UpdateRoomType(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var urt roomtype.UpdateRoomType
+	if err := web.Decode(r, &urt); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rt, err := h.roomType(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	rt, err = h.inventory.RoomType.Update(ctx, rt, urt)
+	if err != nil {
+		return fmt.Errorf("update: roomTypeID[%s] urt[%+v]: %w", rt.ID, urt, err)
+	}
+
+	return web.Respond(ctx, w, rt, http.StatusOK)
+}
+
+func (h *Handlers) DeleteRoomType(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	roomTypeID, err := uuid.Parse(web.Param(r, "room_type_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rt, err := h.inventory.RoomType.QueryByID(ctx, roomTypeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: roomTypeID[%s]: %w", roomTypeID, err)
+		}
+	}
+
+	if err := h.inventory.RoomType.Delete(ctx, rt); err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrInUse):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("delete: roomTypeID[%s]: %w", roomTypeID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+// Rooms
+
+func (h *Handlers) QueryRooms(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	prop, err := h.property(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	rms, err := h.inventory.Room.QueryByPropertyID(ctx, prop.ID)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s]: %w", prop.ID, err)
+	}
+
+	return web.Respond(ctx, w, rms, http.StatusOK)
+}
+
+func (h *Handlers) QueryRoomByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rm, err := h.room(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, rm, http.StatusOK)
+}
+
+func (h *Handlers) CreateRoom(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nr room.NewRoom
+	if err := web.Decode(r, &nr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rm, err := h.inventory.CreateRoom(ctx, nr)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound),
+			errors.Is(err, room.ErrUnknownConnecting):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, room.ErrUniqueNumber):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("create: nr[%+v]: %w", nr, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rm, http.StatusCreated)
+}
+
+func (h *Handlers) UpdateRoom(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ur room.UpdateRoom
+	if err := web.Decode(r, &ur); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rm, err := h.room(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	rm, err = h.inventory.UpdateRoom(ctx, rm, ur)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound),
+			errors.Is(err, room.ErrUnknownConnecting):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, inventory.ErrPropertyMismatch):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, room.ErrUniqueNumber):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("update: roomID[%s] ur[%+v]: %w", rm.ID, ur, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rm, http.StatusOK)
+}
+
+func (h *Handlers) DeleteRoom(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	roomID, err := uuid.Parse(web.Param(r, "room_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rm, err := h.inventory.Room.QueryByID(ctx, roomID)
+	if err != nil {
+		switch {
+		case errors.Is(err, room.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: roomID[%s]: %w", roomID, err)
+		}
+	}
+
+	if err := h.inventory.Room.Delete(ctx, rm); err != nil {
+		return fmt.Errorf("delete: roomID[%s]: %w", roomID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// =============================================================================
+
+func (h *Handlers) property(ctx context.Context, r *http.Request) (property.Property, error) {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return property.Property{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prop, err := h.inventory.Property.QueryByID(ctx, propertyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, property.ErrNotFound):
+			return property.Property{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return property.Property{}, fmt.Errorf("querybyid: propertyID[%s]: %w", propertyID, err)
+		}
+	}
+
+	return prop, nil
+}
+
+func (h *Handlers) roomType(ctx context.Context, r *http.Request) (roomtype.RoomType, error) {
+	roomTypeID, err := uuid.Parse(web.Param(r, "room_type_id"))
+	if err != nil {
+		return roomtype.RoomType{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rt, err := h.inventory.RoomType.QueryByID(ctx, roomTypeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound):
+			return roomtype.RoomType{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return roomtype.RoomType{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", roomTypeID, err)
+		}
+	}
+
+	return rt, nil
+}
+
+func (h *Handlers) room(ctx context.Context, r *http.Request) (room.Room, error) {
+	roomID, err := uuid.Parse(web.Param(r, "room_id"))
+	if err != nil {
+		return room.Room{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rm, err := h.inventory.Room.QueryByID(ctx, roomID)
+	if err != nil {
+		switch {
+		case errors.Is(err, room.ErrNotFound):
+			return room.Room{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return room.Room{}, fmt.Errorf("querybyid: roomID[%s]: %w", roomID, err)
+		}
+	}
+
+	return rm, nil
+}
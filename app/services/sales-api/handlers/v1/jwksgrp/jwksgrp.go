@@ -0,0 +1,33 @@
+// Package jwksgrp maintains the group of handlers publishing the public keys
+// our tokens are signed with, so other services can verify them.
+package jwksgrp
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/tcmhoang/sservices/foundation/keystore"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+// KeySet is a store of keys that can describe their public halves.
+type KeySet interface {
+	JWKS() keystore.JWKS
+}
+
+type Handlers struct {
+	keys KeySet
+}
+
+func New(keys KeySet) *Handlers {
+	return &Handlers{
+		keys: keys,
+	}
+}
+
+// Query returns the JWKS document of the keys tokens are verified with.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	return web.Respond(ctx, w, h.keys.JWKS(), http.StatusOK)
+}
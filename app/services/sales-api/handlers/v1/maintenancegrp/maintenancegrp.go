@@ -0,0 +1,113 @@
+// Package maintenancegrp maintains the group of handlers for the periods
+// rooms are out of order.
+package maintenancegrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/maintenance"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	maintenance *maintenance.Core
+}
+
+func New(maintenance *maintenance.Core) *Handlers {
+	return &Handlers{
+		maintenance: maintenance,
+	}
+}
+
+// Create is restricted to staff by its route. It answers with the new period
+// and the reservations it clashes with.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var np outoforder.NewPeriod
+	if err := web.Decode(r, &np); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if np.UserID, err = uuid.Parse(claims.Subject); err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	cl, err := h.maintenance.Create(ctx, np)
+	if err != nil {
+		switch {
+		case errors.Is(err, room.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("create: np[%+v]: %w", np, err)
+		}
+	}
+
+	return web.Respond(ctx, w, cl, http.StatusCreated)
+}
+
+// QueryByPropertyID lists the periods at the property covering any night
+// between the from and to parameters.
+func (h *Handlers) QueryByPropertyID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	qs := r.URL.Query()
+
+	from, err := time.Parse(date.Layout, qs.Get("from"))
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid from format [%s]", qs.Get("from")), http.StatusBadRequest)
+	}
+
+	to, err := time.Parse(date.Layout, qs.Get("to"))
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid to format [%s]", qs.Get("to")), http.StatusBadRequest)
+	}
+
+	ps, err := h.maintenance.Query(ctx, propertyID, from, to)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s]: %w", propertyID, err)
+	}
+
+	return web.Respond(ctx, w, ps, http.StatusOK)
+}
+
+// Delete is restricted to staff by its route. It puts the room back in
+// inventory for the nights of the period.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	periodID, err := uuid.Parse(web.Param(r, "period_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	p, err := h.maintenance.Store.QueryByID(ctx, periodID)
+	if err != nil {
+		switch {
+		case errors.Is(err, outoforder.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: periodID[%s]: %w", periodID, err)
+		}
+	}
+
+	if err := h.maintenance.Store.Delete(ctx, p); err != nil {
+		return fmt.Errorf("delete: periodID[%s]: %w", periodID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
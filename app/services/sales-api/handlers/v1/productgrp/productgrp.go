@@ -0,0 +1,177 @@
+// Package productgrp maintains the group of handlers for product access.
+package productgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	productcore "github.com/tcmhoang/sservices/business/core/product"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	product *productcore.Core
+}
+
+func New(product *productcore.Core) *Handlers {
+	return &Handlers{
+		product: product,
+	}
+}
+
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page := r.URL.Query().Get("page")
+	pageNumber, err := strconv.Atoi(page)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid page format [%s]", page), http.StatusBadRequest)
+	}
+
+	rows := r.URL.Query().Get("rows")
+	rowsPerPage, err := strconv.Atoi(rows)
+	if err != nil {
+		return validation.NewRequestError(fmt.Errorf("invalid rows format [%s]", rows), http.StatusBadRequest)
+	}
+
+	prds, err := h.product.Store.Query(ctx, pageNumber, rowsPerPage)
+	if err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	return web.Respond(ctx, w, prds, http.StatusOK)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prd, err := h.product.Store.QueryByID(ctx, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", productID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, prd, http.StatusOK)
+}
+
+func (h *Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prds, err := h.product.Store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, prds, http.StatusOK)
+}
+
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var np product.NewProduct
+	if err := web.Decode(r, &np); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	np.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prd, err := h.product.Store.Create(ctx, np)
+	if err != nil {
+		return fmt.Errorf("create: np[%+v]: %w", np, err)
+	}
+
+	return web.Respond(ctx, w, prd, http.StatusCreated)
+}
+
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var up product.UpdateProduct
+	if err := web.Decode(r, &up); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prd, err := h.product.Store.QueryByID(ctx, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: productID[%s]: %w", productID, err)
+		}
+	}
+
+	prd, err = h.product.Store.Update(ctx, claims, prd, up)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrForbidden):
+			return validation.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("update: productID[%s] up[%+v]: %w", productID, up, err)
+		}
+	}
+
+	return web.Respond(ctx, w, prd, http.StatusOK)
+}
+
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prd, err := h.product.Store.QueryByID(ctx, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: productID[%s]: %w", productID, err)
+		}
+	}
+
+	if err := h.product.Store.Delete(ctx, claims, prd); err != nil {
+		switch {
+		case errors.Is(err, product.ErrForbidden):
+			return validation.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("delete: productID[%s]: %w", productID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
@@ -0,0 +1,267 @@
+// Package rateplangrp maintains the group of handlers for rate plans and
+// quotes.
+package rateplangrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	"github.com/tcmhoang/sservices/business/data/store/fxrate"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	pricing *pricing.Core
+}
+
+func New(pricing *pricing.Core) *Handlers {
+	return &Handlers{
+		pricing: pricing,
+	}
+}
+
+func (h *Handlers) QueryByRoomTypeID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	roomTypeID, err := uuid.Parse(web.Param(r, "room_type_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rps, err := h.pricing.RatePlan.QueryByRoomTypeID(ctx, roomTypeID)
+	if err != nil {
+		return fmt.Errorf("roomTypeID[%s]: %w", roomTypeID, err)
+	}
+
+	return web.Respond(ctx, w, rps, http.StatusOK)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	rp, err := h.ratePlan(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, rp, http.StatusOK)
+}
+
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nrp rateplan.NewRatePlan
+	if err := web.Decode(r, &nrp); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rp, err := h.pricing.CreateRatePlan(ctx, nrp)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("create: nrp[%+v]: %w", nrp, err)
+		}
+	}
+
+	return web.Respond(ctx, w, rp, http.StatusCreated)
+}
+
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var urp rateplan.UpdateRatePlan
+	if err := web.Decode(r, &urp); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rp, err := h.ratePlan(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	rp, err = h.pricing.RatePlan.Update(ctx, rp, urp)
+	if err != nil {
+		return fmt.Errorf("update: ratePlanID[%s] urp[%+v]: %w", rp.ID, urp, err)
+	}
+
+	return web.Respond(ctx, w, rp, http.StatusOK)
+}
+
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	ratePlanID, err := uuid.Parse(web.Param(r, "rate_plan_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rp, err := h.pricing.RatePlan.QueryByID(ctx, ratePlanID)
+	if err != nil {
+		switch {
+		case errors.Is(err, rateplan.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: ratePlanID[%s]: %w", ratePlanID, err)
+		}
+	}
+
+	if err := h.pricing.RatePlan.Delete(ctx, rp); err != nil {
+		return fmt.Errorf("delete: ratePlanID[%s]: %w", ratePlanID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// SetWeekdayRate adds or replaces the rate for one day of the week and
+// returns every weekday rate of the plan.
+func (h *Handlers) SetWeekdayRate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var wr rateplan.WeekdayRate
+	if err := web.Decode(r, &wr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rp, err := h.ratePlan(ctx, r)
+	if err != nil {
+		return err
+	}
+	wr.RatePlanID = rp.ID
+
+	if err := h.pricing.RatePlan.SetWeekdayRate(ctx, wr); err != nil {
+		return fmt.Errorf("setweekdayrate: wr[%+v]: %w", wr, err)
+	}
+
+	wrs, err := h.pricing.RatePlan.QueryWeekdayRates(ctx, rp.ID)
+	if err != nil {
+		return fmt.Errorf("queryweekdayrates: ratePlanID[%s]: %w", rp.ID, err)
+	}
+
+	return web.Respond(ctx, w, wrs, http.StatusOK)
+}
+
+// SetOverride adds or replaces the calendar entry for one night.
+func (h *Handlers) SetOverride(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var o rateplan.Override
+	if err := web.Decode(r, &o); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	rp, err := h.ratePlan(ctx, r)
+	if err != nil {
+		return err
+	}
+	o.RatePlanID = rp.ID
+
+	if err := h.pricing.RatePlan.SetOverride(ctx, o); err != nil {
+		return fmt.Errorf("setoverride: o[%+v]: %w", o, err)
+	}
+
+	return web.Respond(ctx, w, o, http.StatusOK)
+}
+
+func (h *Handlers) QueryOverrides(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	from, to, err := dates(r, "from", "to")
+	if err != nil {
+		return err
+	}
+
+	rp, err := h.ratePlan(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	ovs, err := h.pricing.RatePlan.QueryOverrides(ctx, rp.ID, from, to)
+	if err != nil {
+		return fmt.Errorf("queryoverrides: ratePlanID[%s]: %w", rp.ID, err)
+	}
+
+	return web.Respond(ctx, w, ovs, http.StatusOK)
+}
+
+// Quote prices a stay on the rate plan night by night, with the taxes and
+// fees charged on the number of guests, one unless told otherwise. Given a
+// currency, the quote is also shown converted to it.
+func (h *Handlers) Quote(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	checkIn, checkOut, err := dates(r, "checkin", "checkout")
+	if err != nil {
+		return err
+	}
+
+	ratePlanID, err := uuid.Parse(web.Param(r, "rate_plan_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	guests := 1
+	if g := r.URL.Query().Get("guests"); g != "" {
+		if guests, err = strconv.Atoi(g); err != nil || guests < 1 {
+			return validation.NewRequestError(fmt.Errorf("invalid guests format [%s]", g), http.StatusBadRequest)
+		}
+	}
+
+	q, err := h.pricing.Quote(ctx, ratePlanID, checkIn, checkOut, guests)
+	if err != nil {
+		switch {
+		case errors.Is(err, rateplan.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, pricing.ErrInvalidStay),
+			errors.Is(err, pricing.ErrMinStay),
+			errors.Is(err, pricing.ErrClosedToArrival):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("quote: ratePlanID[%s]: %w", ratePlanID, err)
+		}
+	}
+
+	if currency := strings.ToUpper(r.URL.Query().Get("currency")); currency != "" {
+		q, err = h.pricing.Convert(ctx, q, currency)
+		if err != nil {
+			switch {
+			case errors.Is(err, fxrate.ErrNotFound):
+				return validation.NewRequestError(fmt.Errorf("no exchange rate to [%s]", currency), http.StatusBadRequest)
+			default:
+				return fmt.Errorf("convert: ratePlanID[%s] currency[%s]: %w", ratePlanID, currency, err)
+			}
+		}
+	}
+
+	return web.Respond(ctx, w, q, http.StatusOK)
+}
+
+func (h *Handlers) ratePlan(ctx context.Context, r *http.Request) (rateplan.RatePlan, error) {
+	ratePlanID, err := uuid.Parse(web.Param(r, "rate_plan_id"))
+	if err != nil {
+		return rateplan.RatePlan{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rp, err := h.pricing.RatePlan.QueryByID(ctx, ratePlanID)
+	if err != nil {
+		switch {
+		case errors.Is(err, rateplan.ErrNotFound):
+			return rateplan.RatePlan{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return rateplan.RatePlan{}, fmt.Errorf("querybyid: ratePlanID[%s]: %w", ratePlanID, err)
+		}
+	}
+
+	return rp, nil
+}
+
+// dates parses a pair of required date query parameters.
+func dates(r *http.Request, first string, last string) (time.Time, time.Time, error) {
+	qs := r.URL.Query()
+
+	from, err := time.Parse(date.Layout, qs.Get(first))
+	if err != nil {
+		return time.Time{}, time.Time{}, validation.NewRequestError(fmt.Errorf("invalid %s format [%s]", first, qs.Get(first)), http.StatusBadRequest)
+	}
+
+	to, err := time.Parse(date.Layout, qs.Get(last))
+	if err != nil {
+		return time.Time{}, time.Time{}, validation.NewRequestError(fmt.Errorf("invalid %s format [%s]", last, qs.Get(last)), http.StatusBadRequest)
+	}
+
+	return from, to, nil
+}
@@ -0,0 +1,253 @@
+// Package reservationgrp maintains the group of handlers for reservations.
+package reservationgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	reservation *rescore.Core
+}
+
+func New(reservation *rescore.Core) *Handlers {
+	return &Handlers{
+		reservation: reservation,
+	}
+}
+
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var nr reservation.NewReservation
+	if err := web.Decode(r, &nr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	nr.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation.Book(ctx, nr)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound),
+			errors.Is(err, rateplan.ErrNotFound),
+			errors.Is(err, reservation.ErrUnknownCompanion):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrCapacity),
+			errors.Is(err, rescore.ErrRatePlanMismatch),
+			errors.Is(err, pricing.ErrMinStay),
+			errors.Is(err, pricing.ErrClosedToArrival):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rescore.ErrUnavailable):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("book: nr[%+v]: %w", nr, err)
+		}
+	}
+
+	return web.Respond(ctx, w, res, http.StatusCreated)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation.Store.QueryByID(ctx, reservationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", reservationID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != res.UserID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, res, http.StatusOK)
+}
+
+func (h *Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != userID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	ress, err := h.reservation.Store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, ress, http.StatusOK)
+}
+
+// Confirm confirms a held reservation and authorizes its total on the
+// payment source in the body. Guests may confirm their own reservations.
+// Declined payments surface as a *payment.DeclineError.
+func (h *Handlers) Confirm(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var cr reservation.ConfirmReservation
+	if err := web.Decode(r, &cr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	confirm := func(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+		return h.reservation.Confirm(ctx, reservationID, userID, cr)
+	}
+
+	return h.transition(ctx, w, r, confirm)
+}
+
+// Cancel cancels a held or confirmed reservation. Guests may cancel their own
+// reservations.
+func (h *Handlers) Cancel(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return h.transition(ctx, w, r, h.reservation.Cancel)
+}
+
+// CheckIn is restricted to staff by its route. The body may name the room to
+// put the guest in, otherwise a clean room of the room type is picked.
+func (h *Handlers) CheckIn(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ci reservation.CheckInReservation
+	if err := web.Decode(r, &ci); err != nil && !errors.Is(err, io.EOF) {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	checkIn := func(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+		return h.reservation.CheckIn(ctx, reservationID, userID, ci)
+	}
+
+	return h.transition(ctx, w, r, checkIn)
+}
+
+// CheckOut is restricted to staff by its route.
+func (h *Handlers) CheckOut(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return h.transition(ctx, w, r, h.reservation.CheckOut)
+}
+
+// NoShow is restricted to staff by its route.
+func (h *Handlers) NoShow(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return h.transition(ctx, w, r, h.reservation.NoShow)
+}
+
+func (h *Handlers) QueryHistory(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	reservationID, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	hs, err := h.reservation.History(ctx, reservationID)
+	if err != nil {
+		return fmt.Errorf("history: ID[%s]: %w", reservationID, err)
+	}
+
+	return web.Respond(ctx, w, hs, http.StatusOK)
+}
+
+// transition runs a lifecycle transition against the reservation in the
+// request path. Illegal transitions surface as a *rescore.TransitionError.
+func (h *Handlers) transition(ctx context.Context, w http.ResponseWriter, r *http.Request, fn func(context.Context, uuid.UUID, uuid.UUID) (reservation.Reservation, error)) error {
+	reservationID, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := fn(ctx, reservationID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound),
+			errors.Is(err, room.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrWrongRoom):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		case errors.Is(err, rescore.ErrExpired),
+			errors.Is(err, rescore.ErrRoomUnavailable),
+			errors.Is(err, rescore.ErrRoomNotReady),
+			errors.Is(err, rescore.ErrRoomTaken),
+			errors.Is(err, rescore.ErrNoRoomReady):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("transition: ID[%s]: %w", reservationID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, res, http.StatusOK)
+}
+
+// authorize parses the reservation in the request path and checks the caller
+// is either an admin or the guest who owns it.
+func (h *Handlers) authorize(ctx context.Context, r *http.Request) (uuid.UUID, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return uuid.UUID{}, errors.New("claims missing from ctx")
+	}
+
+	reservationID, err := uuid.Parse(web.Param(r, "reservation_id"))
+	if err != nil {
+		return uuid.UUID{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	res, err := h.reservation.Store.QueryByID(ctx, reservationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, reservation.ErrNotFound):
+			return uuid.UUID{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return uuid.UUID{}, fmt.Errorf("ID[%s]: %w", reservationID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != res.UserID.String() {
+		return uuid.UUID{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return reservationID, nil
+}
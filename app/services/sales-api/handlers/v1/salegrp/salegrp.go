@@ -0,0 +1,145 @@
+// Package salegrp maintains the group of handlers for sale access.
+package salegrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	productcore "github.com/tcmhoang/sservices/business/core/product"
+	salecore "github.com/tcmhoang/sservices/business/core/sale"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/sale"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	sale    *salecore.Core
+	product *productcore.Core
+}
+
+func New(sale *salecore.Core, product *productcore.Core) *Handlers {
+	return &Handlers{
+		sale:    sale,
+		product: product,
+	}
+}
+
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var ns sale.NewSale
+	if err := web.Decode(r, &ns); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	ns.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	sl, err := h.sale.Create(ctx, ns)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("create: ns[%+v]: %w", ns, err)
+		}
+	}
+
+	return web.Respond(ctx, w, sl, http.StatusCreated)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	saleID, err := uuid.Parse(web.Param(r, "sale_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	sl, err := h.sale.Store.QueryByID(ctx, saleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sale.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("ID[%s]: %w", saleID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != sl.UserID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return web.Respond(ctx, w, sl, http.StatusOK)
+}
+
+func (h *Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != userID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	sls, err := h.sale.Store.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, sls, http.StatusOK)
+}
+
+func (h *Handlers) QueryByProductID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	productID, err := uuid.Parse(web.Param(r, "product_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	prd, err := h.product.Store.QueryByID(ctx, productID)
+	if err != nil {
+		switch {
+		case errors.Is(err, product.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: productID[%s]: %w", productID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != prd.UserID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	sls, err := h.sale.Store.QueryByProductID(ctx, productID)
+	if err != nil {
+		return fmt.Errorf("productID[%s]: %w", productID, err)
+	}
+
+	return web.Respond(ctx, w, sls, http.StatusOK)
+}
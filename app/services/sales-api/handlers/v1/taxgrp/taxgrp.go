@@ -0,0 +1,150 @@
+// Package taxgrp maintains the group of handlers for the tax and fee rules
+// charged on stays.
+package taxgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/taxrule"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+type Handlers struct {
+	tax *tax.Core
+}
+
+func New(tax *tax.Core) *Handlers {
+	return &Handlers{
+		tax: tax,
+	}
+}
+
+// Query lists the rules of the jurisdiction in the query string.
+func (h *Handlers) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	jurisdiction := r.URL.Query().Get("jurisdiction")
+	if jurisdiction == "" {
+		return validation.NewRequestError(errors.New("jurisdiction is required"), http.StatusBadRequest)
+	}
+
+	rs, err := h.tax.Store.QueryByJurisdiction(ctx, jurisdiction)
+	if err != nil {
+		return fmt.Errorf("jurisdiction[%s]: %w", jurisdiction, err)
+	}
+
+	return web.Respond(ctx, w, rs, http.StatusOK)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	tr, err := h.rule(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tr, http.StatusOK)
+}
+
+// QueryByPropertyID lists every rule charged on stays at the property, its
+// own and those of its jurisdiction.
+func (h *Handlers) QueryByPropertyID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	propertyID, err := uuid.Parse(web.Param(r, "property_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	rs, err := h.tax.Store.QueryByPropertyID(ctx, propertyID)
+	if err != nil {
+		return fmt.Errorf("propertyID[%s]: %w", propertyID, err)
+	}
+
+	return web.Respond(ctx, w, rs, http.StatusOK)
+}
+
+// Create is restricted to staff by its route.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var nr taxrule.NewRule
+	if err := web.Decode(r, &nr); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	tr, err := h.tax.Store.Create(ctx, nr)
+	if err != nil {
+		switch {
+		case errors.Is(err, taxrule.ErrUnknownProperty):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("create: nr[%+v]: %w", nr, err)
+		}
+	}
+
+	return web.Respond(ctx, w, tr, http.StatusCreated)
+}
+
+// Update is restricted to staff by its route.
+func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var ur taxrule.UpdateRule
+	if err := web.Decode(r, &ur); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	tr, err := h.rule(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	tr, err = h.tax.Store.Update(ctx, tr, ur)
+	if err != nil {
+		return fmt.Errorf("update: taxRuleID[%s] ur[%+v]: %w", tr.ID, ur, err)
+	}
+
+	return web.Respond(ctx, w, tr, http.StatusOK)
+}
+
+// Delete is restricted to staff by its route.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	taxRuleID, err := uuid.Parse(web.Param(r, "tax_rule_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	tr, err := h.tax.Store.QueryByID(ctx, taxRuleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, taxrule.ErrNotFound):
+			return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+		default:
+			return fmt.Errorf("querybyid: taxRuleID[%s]: %w", taxRuleID, err)
+		}
+	}
+
+	if err := h.tax.Store.Delete(ctx, tr); err != nil {
+		return fmt.Errorf("delete: taxRuleID[%s]: %w", tr.ID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// rule loads the rule in the request path.
+func (h *Handlers) rule(ctx context.Context, r *http.Request) (taxrule.Rule, error) {
+	taxRuleID, err := uuid.Parse(web.Param(r, "tax_rule_id"))
+	if err != nil {
+		return taxrule.Rule{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	tr, err := h.tax.Store.QueryByID(ctx, taxRuleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, taxrule.ErrNotFound):
+			return taxrule.Rule{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return taxrule.Rule{}, fmt.Errorf("querybyid: taxRuleID[%s]: %w", taxRuleID, err)
+		}
+	}
+
+	return tr, nil
+}
@@ -12,7 +12,10 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
 	usercore "github.com/tcmhoang/sservices/business/core/user"
+	"github.com/tcmhoang/sservices/business/data/store/refresh"
 	"github.com/tcmhoang/sservices/business/data/store/user"
 	"github.com/tcmhoang/sservices/business/sys/auth"
 	"github.com/tcmhoang/sservices/business/sys/validation"
@@ -20,14 +23,16 @@ import (
 )
 
 type Handlers struct {
-	user *usercore.Core
-	auth *auth.Auth
+	user        *usercore.Core
+	revocations *revcore.Core
+	auth        *auth.Auth
 }
 
-func New(user *usercore.Core, auth *auth.Auth) *Handlers {
+func New(user *usercore.Core, revocations *revcore.Core, auth *auth.Auth) *Handlers {
 	return &Handlers{
-		user: user,
-		auth: auth,
+		user:        user,
+		revocations: revocations,
+		auth:        auth,
 	}
 }
 
@@ -86,13 +91,24 @@ func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Re
 }
 
 func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
 
 	var uu user.UpdateUser
 	if err := web.Decode(r, &uu); err != nil {
 		return validation.NewRequestError(err, http.StatusBadRequest)
 	}
 
-	userID := auth.GetUserID(ctx)
+	if uu.Enabled != nil && !claims.Authorized(auth.Admin) {
+		return validation.NewRequestError(errors.New("only admins can enable or disable users"), http.StatusForbidden)
+	}
+
+	userID, err := authorize(claims, r)
+	if err != nil {
+		return err
+	}
 
 	usr, err := h.user.Store.QueryByID(ctx, userID)
 	if err != nil {
@@ -109,6 +125,12 @@ func (h *Handlers) Update(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return fmt.Errorf("update: userID[%s] uu[%+v]: %w", userID, uu, err)
 	}
 
+	if uu.Enabled != nil && !*uu.Enabled {
+		if err := h.revokeAll(ctx, userID, time.Now()); err != nil {
+			return err
+		}
+	}
+
 	return web.Respond(ctx, w, usr, http.StatusOK)
 }
 
@@ -118,7 +140,10 @@ func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Re
 		return errors.New("claims missing from ctx")
 	}
 
-	userID := auth.GetUserID(ctx)
+	userID, err := authorize(claims, r)
+	if err != nil {
+		return err
+	}
 
 	usr, err := h.user.Store.QueryByID(ctx, userID)
 	if err != nil {
@@ -131,7 +156,16 @@ func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Re
 	}
 
 	if err := h.user.Store.Delete(ctx, claims, usr); err != nil {
-		return fmt.Errorf("delete: userID[%s]: %w", userID, err)
+		switch {
+		case errors.Is(err, user.ErrInUse):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("delete: userID[%s]: %w", userID, err)
+		}
+	}
+
+	if err := h.revocations.RevokeUser(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", userID, err)
 	}
 
 	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
@@ -148,18 +182,205 @@ func (h *Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Req
 		return validation.NewRequestError(errors.New("invalid email format"), http.StatusBadRequest)
 	}
 
-	usr, err := h.user.Authenticate(ctx, *addr, pass)
+	now := time.Now()
+
+	usr, err := h.user.Authenticate(ctx, *addr, pass, now)
 	if err != nil {
 		switch {
 		case errors.Is(err, user.ErrNotFound):
 			return validation.NewRequestError(err, http.StatusNotFound)
 		case errors.Is(err, usercore.ErrAuthenticationFailure):
 			return validation.NewRequestError(err, http.StatusMethodNotAllowed)
+		case errors.Is(err, usercore.ErrLocked):
+			return validation.NewRequestError(err, http.StatusTooManyRequests)
+		case errors.Is(err, usercore.ErrDisabled):
+			return validation.NewRequestError(err, http.StatusForbidden)
 		default:
 			return fmt.Errorf("authenticate: %w", err)
 		}
 	}
 
+	tkn, err := h.tokens(ctx, usr, now)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkn, http.StatusOK)
+}
+
+// Refresh trades a refresh token for a new access token and the next refresh
+// token. A refresh token presented twice revokes every token of its login.
+func (h *Handlers) Refresh(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var rf refresh.Refresh
+	if err := web.Decode(r, &rf); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	now := time.Now()
+
+	usr, raw, err := h.user.Refresh(ctx, rf, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, usercore.ErrInvalidRefresh),
+			errors.Is(err, usercore.ErrRefreshReused):
+			return validation.NewRequestError(err, http.StatusUnauthorized)
+		case errors.Is(err, usercore.ErrDisabled):
+			return validation.NewRequestError(err, http.StatusForbidden)
+		default:
+			return fmt.Errorf("refresh: %w", err)
+		}
+	}
+
+	tkn := tokens{RefreshToken: raw}
+	if tkn.Token, err = h.access(usr, now); err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, tkn, http.StatusOK)
+}
+
+// Logout revokes the refresh token in the body along with every token of its
+// login.
+func (h *Handlers) Logout(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var rf refresh.Refresh
+	if err := web.Decode(r, &rf); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	if err := h.user.Logout(ctx, rf, time.Now()); err != nil {
+		return fmt.Errorf("logout: %w", err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// LogoutAll revokes every refresh token of the caller and every access token
+// they were issued so far.
+func (h *Handlers) LogoutAll(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if err := h.revokeAll(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// RevokeToken revokes the access token the caller presented.
+func (h *Handlers) RevokeToken(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	if err := h.revocations.RevokeToken(ctx, claims, time.Now()); err != nil {
+		return fmt.Errorf("revoketoken: tokenID[%s]: %w", claims.ID, err)
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// RevokeUser revokes every token the user was issued so far, for when an
+// account or its tokens are compromised.
+func (h *Handlers) RevokeUser(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := auth.GetUserID(ctx)
+
+	if _, err := h.user.Store.QueryByID(ctx, userID); err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("querybyid: userID[%s]: %w", userID, err)
+		}
+	}
+
+	if err := h.revokeAll(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// Unlock ends the lockout of a user who gave too many wrong passwords.
+func (h *Handlers) Unlock(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := auth.GetUserID(ctx)
+
+	usr, err := h.user.Unlock(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return fmt.Errorf("unlock: userID[%s]: %w", userID, err)
+		}
+	}
+
+	return web.Respond(ctx, w, usr, http.StatusOK)
+}
+
+// authorize parses the user in the request path and checks the caller is
+// either an admin or that user.
+func authorize(claims auth.Claims, r *http.Request) (uuid.UUID, error) {
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return uuid.UUID{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != userID.String() {
+		return uuid.UUID{}, validation.NewRequestError(user.ErrForbidden, http.StatusForbidden)
+	}
+
+	return userID, nil
+}
+
+// revokeAll revokes the refresh tokens of the user and cuts off their access
+// tokens.
+func (h *Handlers) revokeAll(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	if err := h.user.LogoutAll(ctx, userID, now); err != nil {
+		return fmt.Errorf("logoutall: userID[%s]: %w", userID, err)
+	}
+
+	if err := h.revocations.RevokeUser(ctx, userID, now); err != nil {
+		return fmt.Errorf("revokeuser: userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// tokens is what a client gets at login and on every refresh.
+type tokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// tokens issues an access token and starts a new family of refresh tokens
+// for the user.
+func (h *Handlers) tokens(ctx context.Context, usr user.User, now time.Time) (tokens, error) {
+	var tkn tokens
+	var err error
+
+	if tkn.Token, err = h.access(usr, now); err != nil {
+		return tokens{}, err
+	}
+
+	if tkn.RefreshToken, err = h.user.IssueRefresh(ctx, usr.ID, now); err != nil {
+		return tokens{}, fmt.Errorf("issuerefresh: %w", err)
+	}
+
+	return tkn, nil
+}
+
+// access issues a one-hour access token carrying the user's roles. Its id lets
+// it be revoked before it expires.
+func (h *Handlers) access(usr user.User, now time.Time) (string, error) {
 	var roles []auth.Role
 
 	for _, r := range usr.Roles {
@@ -168,6 +389,8 @@ func (h *Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Req
 			roles = append(roles, auth.Admin)
 		case "user":
 			roles = append(roles, auth.User)
+		case "housekeeping":
+			roles = append(roles, auth.Housekeeping)
 
 		default:
 
@@ -176,22 +399,19 @@ func (h *Handlers) Token(ctx context.Context, w http.ResponseWriter, r *http.Req
 
 	claims := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   usr.ID.String(),
 			Issuer:    "service project",
-			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+			ExpiresAt: jwt.NewNumericDate(now.UTC().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(now.UTC()),
 		},
 		Roles: roles,
 	}
 
-	var tkn struct {
-		Token string `json:"token"`
-	}
-
-	tkn.Token, err = h.auth.GenerateToken(claims)
+	tkn, err := h.auth.GenerateToken(claims)
 	if err != nil {
-		return fmt.Errorf("generatetoken: %w", err)
+		return "", fmt.Errorf("generatetoken: %w", err)
 	}
 
-	return web.Respond(ctx, w, tkn, http.StatusOK)
+	return tkn, nil
 }
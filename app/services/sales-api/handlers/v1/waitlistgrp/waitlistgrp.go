@@ -0,0 +1,144 @@
+// Package waitlistgrp maintains the group of handlers for the waitlist of
+// sold out stays.
+package waitlistgrp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/data/store/waitlist"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/web"
+)
+
+var ErrForbidden = errors.New("forbidden operation")
+
+type Handlers struct {
+	reservation *rescore.Core
+}
+
+func New(reservation *rescore.Core) *Handlers {
+	return &Handlers{
+		reservation: reservation,
+	}
+}
+
+// Create puts the caller on the waitlist for a room type and stay.
+func (h *Handlers) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	var ne waitlist.NewEntry
+	if err := web.Decode(r, &ne); err != nil {
+		return validation.NewRequestError(err, http.StatusBadRequest)
+	}
+
+	ne.UserID, err = uuid.Parse(claims.Subject)
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	e, err := h.reservation.JoinWaitlist(ctx, ne)
+	if err != nil {
+		switch {
+		case errors.Is(err, roomtype.ErrNotFound):
+			return validation.NewRequestError(err, http.StatusNotFound)
+		case errors.Is(err, rescore.ErrCapacity):
+			return validation.NewRequestError(err, http.StatusBadRequest)
+		default:
+			return fmt.Errorf("joinwaitlist: ne[%+v]: %w", ne, err)
+		}
+	}
+
+	return web.Respond(ctx, w, e, http.StatusCreated)
+}
+
+func (h *Handlers) QueryByID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	e, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	return web.Respond(ctx, w, e, http.StatusOK)
+}
+
+func (h *Handlers) QueryByUserID(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return errors.New("claims missing from ctx")
+	}
+
+	userID, err := uuid.Parse(web.Param(r, "user_id"))
+	if err != nil {
+		return validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != userID.String() {
+		return validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	es, err := h.reservation.Waitlist.QueryByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("userID[%s]: %w", userID, err)
+	}
+
+	return web.Respond(ctx, w, es, http.StatusOK)
+}
+
+// Delete takes the caller out of line. Once a room was offered the guest
+// releases its hold instead.
+func (h *Handlers) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	e, err := h.authorize(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.reservation.LeaveWaitlist(ctx, e.ID); err != nil {
+		switch {
+		case errors.Is(err, rescore.ErrNotWaiting):
+			return validation.NewRequestError(err, http.StatusConflict)
+		default:
+			return fmt.Errorf("leavewaitlist: ID[%s]: %w", e.ID, err)
+		}
+	}
+
+	return web.Respond[interface{}](ctx, w, nil, http.StatusNoContent)
+}
+
+// authorize loads the entry in the request path and checks the caller is
+// either an admin or the guest who is waiting.
+func (h *Handlers) authorize(ctx context.Context, r *http.Request) (waitlist.Entry, error) {
+	claims, err := auth.GetClaims(ctx)
+	if err != nil {
+		return waitlist.Entry{}, errors.New("claims missing from ctx")
+	}
+
+	waitlistID, err := uuid.Parse(web.Param(r, "waitlist_id"))
+	if err != nil {
+		return waitlist.Entry{}, validation.NewRequestError(validation.ErrInvalidID, http.StatusBadRequest)
+	}
+
+	e, err := h.reservation.Waitlist.QueryByID(ctx, waitlistID)
+	if err != nil {
+		switch {
+		case errors.Is(err, waitlist.ErrNotFound):
+			return waitlist.Entry{}, validation.NewRequestError(err, http.StatusNotFound)
+		default:
+			return waitlist.Entry{}, fmt.Errorf("ID[%s]: %w", waitlistID, err)
+		}
+	}
+
+	if !claims.Authorized(auth.Admin) && claims.Subject != e.UserID.String() {
+		return waitlist.Entry{}, validation.NewRequestError(ErrForbidden, http.StatusForbidden)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/foundation/keystore"
+	"go.uber.org/zap"
+)
+
+// keySource remembers where the keys were loaded from so they can be loaded
+// again. A service that only verifies tokens holds public keys alone, from
+// the PEM files of the folder or from a JWKS document published by the
+// service issuing them.
+type keySource struct {
+	folder   string
+	jwksFile string
+	signing  *keystore.KeyStore
+	public   *keystore.PublicKeyStore
+}
+
+func initKeys(folder string, verifyOnly bool, jwksFile string) (keySource, error) {
+	src := keySource{
+		folder: folder,
+	}
+
+	var err error
+
+	switch {
+	case !verifyOnly:
+		src.signing, err = keystore.NewFS(os.DirFS(folder))
+
+	case jwksFile == "":
+		src.public, err = keystore.NewPublicFS(os.DirFS(folder))
+
+	default:
+		src.jwksFile = jwksFile
+
+		var f *os.File
+		if f, err = os.Open(jwksFile); err != nil {
+			return keySource{}, fmt.Errorf("opening jwks: %w", err)
+		}
+		defer f.Close()
+
+		src.public, err = keystore.NewPublicJWKS(f)
+	}
+
+	if err != nil {
+		return keySource{}, err
+	}
+
+	return src, nil
+}
+
+func (src keySource) lookup() auth.KeyLookup {
+	if src.signing != nil {
+		return src.signing
+	}
+	return src.public
+}
+
+// activeKID is the kid to sign with: the one the manifest of the folder names,
+// else the configured one. Services that only verify tokens sign nothing.
+func (src keySource) activeKID(configured string) string {
+	if src.signing == nil {
+		return ""
+	}
+
+	if kid := src.signing.Active(); kid != "" {
+		return kid
+	}
+	return configured
+}
+
+// reload loads the keys again. A signing service refuses keys that would
+// leave current, the kid it signs with, unable to sign unless the manifest
+// names another active key.
+func (src keySource) reload(current string) error {
+	switch {
+	case src.signing != nil:
+		return src.signing.Reload(os.DirFS(src.folder), current)
+
+	case src.jwksFile == "":
+		return src.public.Reload(os.DirFS(src.folder))
+
+	default:
+		f, err := os.Open(src.jwksFile)
+		if err != nil {
+			return fmt.Errorf("opening jwks: %w", err)
+		}
+		defer f.Close()
+
+		return src.public.ReloadJWKS(f)
+	}
+}
+
+// stamp changes whenever a file the keys are loaded from does.
+func (src keySource) stamp() (string, error) {
+	var stamp string
+
+	add := func(p string, info fs.FileInfo) {
+		stamp += fmt.Sprintf("%s:%d:%d;", p, info.Size(), info.ModTime().UnixNano())
+	}
+
+	if src.jwksFile != "" {
+		info, err := os.Stat(src.jwksFile)
+		if err != nil {
+			return "", err
+		}
+		add(src.jwksFile, info)
+		return stamp, nil
+	}
+
+	walk := func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		add(p, info)
+
+		return nil
+	}
+
+	if err := filepath.WalkDir(src.folder, walk); err != nil {
+		return "", err
+	}
+
+	return stamp, nil
+}
+
+// watchKeys reloads the keys on SIGHUP, or once their files changed, until
+// ctx is cancelled. When the manifest names another active key, new tokens
+// are signed with it from then on. A reload that fails, such as one retiring
+// the active key without naming another, leaves the keys as they were.
+func watchKeys(ctx context.Context, log *zap.SugaredLogger, src keySource, a *auth.Auth, poll time.Duration, hup <-chan os.Signal) {
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	last, err := src.stamp()
+	if err != nil {
+		log.Errorw("keys", "status", "stamping keys", "ERROR", err)
+	}
+
+	reload := func(reason string) {
+		if err := src.reload(a.ActiveKID()); err != nil {
+			log.Errorw("keys", "status", "reloading keys", "reason", reason, "ERROR", err)
+			return
+		}
+
+		log.Infow("keys", "status", "keys reloaded", "reason", reason)
+
+		kid := src.activeKID(a.ActiveKID())
+		if kid == "" {
+			return
+		}
+
+		if kid != a.ActiveKID() {
+			if err := a.SetActiveKID(kid); err != nil {
+				log.Errorw("keys", "status", "switching active key", "kid", kid, "ERROR", err)
+				return
+			}
+			log.Infow("keys", "status", "active key switched", "kid", kid)
+		}
+
+		if _, err := a.PrivateKey(kid); err != nil {
+			log.Errorw("keys", "status", "active key cannot sign", "kid", kid, "ERROR", err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hup:
+			reload("SIGHUP")
+
+		case <-ticker.C:
+			stamp, err := src.stamp()
+			if err != nil {
+				log.Errorw("keys", "status", "stamping keys", "ERROR", err)
+				continue
+			}
+
+			if stamp == last {
+				continue
+			}
+			last = stamp
+
+			reload("files changed")
+		}
+	}
+}
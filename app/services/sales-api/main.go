@@ -15,9 +15,11 @@ import (
 	"github.com/ardanlabs/conf"
 	"github.com/jmoiron/sqlx"
 	"github.com/tcmhoang/sservices/app/services/sales-api/handlers"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
 	"github.com/tcmhoang/sservices/business/sys/auth"
 	"github.com/tcmhoang/sservices/business/sys/database"
-	"github.com/tcmhoang/sservices/foundation/keystore"
+	"github.com/tcmhoang/sservices/business/sys/payment"
 	"github.com/tcmhoang/sservices/foundation/logger"
 	"go.opentelemetry.io/otel"
 
@@ -70,8 +72,11 @@ func run(log *zap.SugaredLogger) error {
 			ShutdownTimeout time.Duration `conf:"default:20s"`
 		}
 		Auth struct {
-			KeysFolder string `conf:"default:zarf/keys/"`
-			ActiveKID  string `conf:"default:private"`
+			KeysFolder string        `conf:"default:zarf/keys/"`
+			ActiveKID  string        `conf:"default:private"`
+			VerifyOnly bool          `conf:"default:false"`
+			ReloadPoll time.Duration `conf:"default:30s"`
+			JWKSFile   string
 		}
 		DB struct {
 			User         string `conf:"default:postgres"`
@@ -83,6 +88,14 @@ func run(log *zap.SugaredLogger) error {
 			DisableTLS   bool   `conf:"default:true"`
 		}
 
+		Holds struct {
+			ReapInterval time.Duration `conf:"default:30s"`
+		}
+
+		Payment struct {
+			Gateway string `conf:"default:fake"`
+		}
+
 		Zipkin struct {
 			ReporterURI string  `conf:"default:http://zipkin-service.sales-system.svc.cluster.local:9411/api/v2/spans"`
 			ServiceName string  `conf:"default:sales-api"`
@@ -108,16 +121,6 @@ func run(log *zap.SugaredLogger) error {
 	log.Infow("Startup", "CONFIG", confout)
 	expvar.NewString("build").Set(build)
 
-	ks, err := keystore.NewFS(os.DirFS(cfg.Auth.KeysFolder))
-	if err != nil {
-		return fmt.Errorf("reading keys: %w", err)
-	}
-
-	auth, err := auth.New(cfg.Auth.ActiveKID, ks)
-	if err != nil {
-		return fmt.Errorf("constructing auth: %w", err)
-	}
-
 	log.Infow("startup", "status", "initializing database support", "host", cfg.DB.Host)
 
 	db, err := database.Open(
@@ -140,6 +143,45 @@ func run(log *zap.SugaredLogger) error {
 		db.Close()
 	}()
 
+	keys, err := initKeys(cfg.Auth.KeysFolder, cfg.Auth.VerifyOnly, cfg.Auth.JWKSFile)
+	if err != nil {
+		return fmt.Errorf("reading keys: %w", err)
+	}
+
+	auth, err := auth.New(keys.activeKID(cfg.Auth.ActiveKID), keys.lookup())
+	if err != nil {
+		return fmt.Errorf("constructing auth: %w", err)
+	}
+
+	// The revoker is set before the key watcher and the API share the Auth.
+	revocations := revcore.NewCore(log, db)
+	auth.SetRevoker(revocations)
+
+	if cfg.Auth.ReloadPoll <= 0 {
+		return fmt.Errorf("key reload poll must be positive: %s", cfg.Auth.ReloadPoll)
+	}
+
+	log.Infow("startup", "status", "key watcher started", "kid", auth.ActiveKID(), "poll", cfg.Auth.ReloadPoll)
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+
+	go watchKeys(watchCtx, log, keys, auth, cfg.Auth.ReloadPoll, hup)
+
+	log.Infow("startup", "status", "initializing payment support", "gateway", cfg.Payment.Gateway)
+
+	var gw payment.Gateway
+	switch cfg.Payment.Gateway {
+	case "fake":
+		gw = payment.NewFake()
+	default:
+		return fmt.Errorf("unknown payment gateway: %s", cfg.Payment.Gateway)
+	}
+
 	log.Info("startup", "status", "initializing OT/Zipkin tracing support")
 
 	traceProvider, err := startTracing(
@@ -165,11 +207,13 @@ func run(log *zap.SugaredLogger) error {
 
 	apiMux := handlers.APIMux(
 		handlers.APIMuxConfig{
-			Shutdown: shutdown,
-			Log:      log,
-			Auth:     auth,
-			DB:       db,
-			Tracer:   tracer,
+			Shutdown:    shutdown,
+			Log:         log,
+			Auth:        auth,
+			Revocations: revocations,
+			DB:          db,
+			Payment:     gw,
+			Tracer:      tracer,
 		})
 
 	api := http.Server{
@@ -181,6 +225,21 @@ func run(log *zap.SugaredLogger) error {
 		ErrorLog:     zap.NewStdLog(log.Desugar()),
 	}
 
+	if cfg.Holds.ReapInterval <= 0 {
+		return fmt.Errorf("hold reap interval must be positive: %s", cfg.Holds.ReapInterval)
+	}
+
+	log.Infow("startup", "status", "hold reaper started", "interval", cfg.Holds.ReapInterval)
+
+	reaperCtx, stopReaper := context.WithCancel(context.Background())
+	defer stopReaper()
+
+	reaperDone := make(chan struct{})
+	go func() {
+		defer close(reaperDone)
+		reapHolds(reaperCtx, log, rescore.NewCore(log, db, gw), cfg.Holds.ReapInterval)
+	}()
+
 	severErrs := make(chan error, 1)
 	go func() {
 		log.Infow("startup", "status", "api router started", "host", api.Addr)
@@ -195,6 +254,10 @@ func run(log *zap.SugaredLogger) error {
 		log.Infow("shutdown", "status", "shutdown started", "signal", sig)
 		defer log.Infow("shutdown", "status", "shutdown complete", "signal", sig)
 
+		log.Infow("shutdown", "status", "stopping hold reaper")
+		stopReaper()
+		<-reaperDone
+
 		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
 		defer cancel()
 
@@ -207,6 +270,59 @@ func run(log *zap.SugaredLogger) error {
 	return nil
 }
 
+// reapHolds expires holds and HELD reservations whose time ran out, and
+// releases blocks past their cutoff, every interval until ctx is cancelled.
+// Availability already ignores lapsed holds, but expiring them is what offers
+// their rooms to the waitlist.
+func reapHolds(ctx context.Context, log *zap.SugaredLogger, core *rescore.Core, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			hs, err := core.ExpireHolds(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorw("holds", "status", "expiring holds", "ERROR", err)
+				}
+				continue
+			}
+
+			if len(hs) > 0 {
+				log.Infow("holds", "status", "expired holds", "count", len(hs))
+			}
+
+			ress, err := core.ExpireReservations(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorw("holds", "status", "expiring reservations", "ERROR", err)
+				}
+				continue
+			}
+
+			if len(ress) > 0 {
+				log.Infow("holds", "status", "expired reservations", "count", len(ress))
+			}
+
+			bs, err := core.ReleaseBlocks(ctx, now)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorw("holds", "status", "releasing blocks", "ERROR", err)
+				}
+				continue
+			}
+
+			if len(bs) > 0 {
+				log.Infow("holds", "status", "released blocks", "count", len(bs))
+			}
+		}
+	}
+}
+
 func initDebugMux(log *zap.SugaredLogger, host string, db *sqlx.DB) {
 	debugMux := handlers.DebugMux(build, log, db)
 
@@ -0,0 +1,408 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"os"
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/tcmhoang/sservices/app/services/sales-api/handlers"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+type ProductTests struct {
+	app        http.Handler
+	userToken  string
+	adminToken string
+	otherToken string
+}
+
+func TestProducts(t *testing.T) {
+	t.Parallel()
+
+	test := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	t.Log("Go seeding ...")
+
+	nu := user.NewUser{
+		Name:            "Other Gopher",
+		Email:           mail.Address{Address: "other@example.com"},
+		Roles:           []string{"USER"},
+		Password:        "gophers",
+		PasswordConfirm: "gophers",
+	}
+	if _, err := user.NewStore(test.Log, test.DB).Create(context.Background(), nu); err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	revocations := revcore.NewCore(test.Log, test.DB)
+	test.Auth.SetRevoker(revocations)
+
+	shutdown := make(chan os.Signal, 1)
+	tests := ProductTests{
+		app: handlers.APIMux(handlers.APIMuxConfig{
+			Shutdown:    shutdown,
+			Log:         test.Log,
+			Auth:        test.Auth,
+			Revocations: revocations,
+			DB:          test.DB,
+		}),
+		userToken:  test.Token("user@example.com", "gophers"),
+		adminToken: test.Token("admin@example.com", "gophers"),
+		otherToken: test.Token("other@example.com", "gophers"),
+	}
+
+	t.Run("postProduct400", tests.postProduct400())
+	t.Run("postProductNoAuth401", tests.postProductNoAuth401())
+	t.Run("getProduct400", tests.getProduct400())
+	t.Run("getProduct404", tests.getProduct404())
+	t.Run("deleteProductNotFound", tests.deleteProductNotFound())
+	t.Run("putProduct404", tests.putProduct404())
+	t.Run("getProducts200", tests.getProducts200())
+	t.Run("getUserProducts200", tests.getUserProducts200())
+	t.Run("crudProducts", tests.crudProduct())
+}
+
+func (pt *ProductTests) postProduct400() func(t *testing.T) {
+	return func(t *testing.T) {
+		body, err := json.Marshal(&product.NewProduct{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/products", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Should receive a status code of 400 for the response : %d", w.Code)
+		}
+
+		var got validation.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatalf("Should be able to unmarshal the response to an error type : %s", err)
+		}
+
+		if _, exists := got.Fields["name"]; !exists {
+			t.Fatalf("Should get a validation error for the name field : %v", got.Fields)
+		}
+	}
+}
+
+func (pt *ProductTests) postProductNoAuth401() func(t *testing.T) {
+	return func(t *testing.T) {
+		body, err := json.Marshal(&product.NewProduct{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/products", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Should receive a status code of 401 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (pt *ProductTests) getProduct400() func(t *testing.T) {
+	return func(t *testing.T) {
+		url := fmt.Sprintf("/v1/products/%d", 12345)
+
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Should receive a status code of 400 for the response : %d", w.Code)
+		}
+
+		got := w.Body.String()
+		exp := `{"error":"ID is not in its proper form"}`
+		if got != exp {
+			t.Logf("got: %v", got)
+			t.Logf("exp: %v", exp)
+			t.Errorf("Should get the expected result")
+		}
+	}
+}
+
+func (pt *ProductTests) getProduct404() func(t *testing.T) {
+	return func(t *testing.T) {
+		url := fmt.Sprintf("/v1/products/%s", "a224a8d6-3f9e-4b11-9900-e81a25d80702")
+
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Should receive a status code of 404 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (pt *ProductTests) deleteProductNotFound() func(t *testing.T) {
+	return func(t *testing.T) {
+		url := fmt.Sprintf("/v1/products/%s", "112262f1-1a77-4374-9f22-39e575aa6348")
+
+		r := httptest.NewRequest(http.MethodDelete, url, nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Should receive a status code of 204 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (pt *ProductTests) putProduct404() func(t *testing.T) {
+	return func(t *testing.T) {
+		up := product.UpdateProduct{
+			Name: tests.StringPointer("Nonexistent"),
+		}
+		body, err := json.Marshal(&up)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		url := fmt.Sprintf("/v1/products/%s", "9b468f90-1cf1-4377-b3fa-68b450d632a0")
+
+		r := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("Should receive a status code of 404 for the response : %d", w.Code)
+		}
+	}
+}
+
+func (pt *ProductTests) getProducts200() func(t *testing.T) {
+	return func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/v1/products?page=1&rows=2", nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+		}
+
+		var prds []product.Product
+		if err := json.Unmarshal(w.Body.Bytes(), &prds); err != nil {
+			t.Fatalf("Should be able to unmarshal the response : %s", err)
+		}
+
+		if len(prds) != 2 {
+			t.Log("got:", len(prds))
+			t.Log("exp:", 2)
+			t.Error("Should get the right total")
+		}
+	}
+}
+
+func (pt *ProductTests) getUserProducts200() func(t *testing.T) {
+	return func(t *testing.T) {
+		url := "/v1/users/45b5fbd3-755f-4379-8f07-a58d4a30fa2f/products"
+
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+pt.userToken)
+		pt.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+		}
+
+		var prds []product.Product
+		if err := json.Unmarshal(w.Body.Bytes(), &prds); err != nil {
+			t.Fatalf("Should be able to unmarshal the response : %s", err)
+		}
+
+		if len(prds) != 2 {
+			t.Log("got:", len(prds))
+			t.Log("exp:", 2)
+			t.Error("Should get the seeded products of the user")
+		}
+	}
+}
+
+func (pt *ProductTests) crudProduct() func(t *testing.T) {
+	return func(t *testing.T) {
+		prd := pt.postProduct201(t)
+		defer pt.deleteProduct204(t, prd.ID.String())
+
+		pt.getProduct200(t, prd)
+		pt.putProduct200(t, prd.ID.String())
+		pt.putProduct403(t, prd.ID.String())
+	}
+}
+
+func (pt *ProductTests) postProduct201(t *testing.T) product.Product {
+	np := product.NewProduct{
+		Name:     "Comic Books",
+		Cost:     money.New(25, "USD"),
+		Quantity: 60,
+	}
+
+	body, err := json.Marshal(&np)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/products", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+pt.userToken)
+	pt.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Should receive a status code of 201 for the response : %d", w.Code)
+	}
+
+	var got product.Product
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	exp := got
+	exp.Name = "Comic Books"
+	exp.Cost = money.New(25, "USD")
+	exp.Quantity = 60
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Fatalf("Should get the expected result, diff:\n%s", diff)
+	}
+
+	return got
+}
+
+func (pt *ProductTests) deleteProduct204(t *testing.T, id string) {
+	url := fmt.Sprintf("/v1/products/%s", id)
+
+	r := httptest.NewRequest(http.MethodDelete, url, nil)
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+pt.userToken)
+	pt.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Should receive a status code of 204 for the response : %d", w.Code)
+	}
+}
+
+func (pt *ProductTests) getProduct200(t *testing.T, prd product.Product) {
+	url := fmt.Sprintf("/v1/products/%s", prd.ID)
+
+	r := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+pt.adminToken)
+	pt.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+	}
+
+	var got product.Product
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	if got.ID != prd.ID || got.Name != prd.Name || got.UserID != prd.UserID {
+		t.Logf("got: %+v", got)
+		t.Logf("exp: %+v", prd)
+		t.Fatalf("Should get the expected result.")
+	}
+}
+
+func (pt *ProductTests) putProduct200(t *testing.T, id string) {
+	up := product.UpdateProduct{
+		Name: tests.StringPointer("Graphic Novels"),
+	}
+	body, err := json.Marshal(&up)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := fmt.Sprintf("/v1/products/%s", id)
+
+	r := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+pt.userToken)
+	pt.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the response : %d", w.Code)
+	}
+
+	var got product.Product
+	if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	if got.Name != "Graphic Novels" {
+		t.Fatalf("Should see an updated Name : got %q want %q", got.Name, "Graphic Novels")
+	}
+
+	if got.Cost != money.New(25, "USD") {
+		t.Fatalf("Should not affect other fields like Cost : got %v want %v", got.Cost, money.New(25, "USD"))
+	}
+}
+
+func (pt *ProductTests) putProduct403(t *testing.T, id string) {
+	up := product.UpdateProduct{
+		Name: tests.StringPointer("Stolen"),
+	}
+	body, err := json.Marshal(&up)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	url := fmt.Sprintf("/v1/products/%s", id)
+
+	r := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+pt.otherToken)
+	pt.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Should receive a status code of 403 for the response : %d", w.Code)
+	}
+}
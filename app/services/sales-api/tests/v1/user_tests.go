@@ -18,6 +18,8 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/tcmhoang/sservices/app/services/sales-api/handlers"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
+	usercore "github.com/tcmhoang/sservices/business/core/user"
 	"github.com/tcmhoang/sservices/business/data/store/user"
 	"github.com/tcmhoang/sservices/business/data/tests"
 	"github.com/tcmhoang/sservices/business/sys/validation"
@@ -41,13 +43,17 @@ func TestUsers(t *testing.T) {
 		test.Teardown()
 	}()
 
+	revocations := revcore.NewCore(test.Log, test.DB)
+	test.Auth.SetRevoker(revocations)
+
 	shutdown := make(chan os.Signal, 1)
 	tests := UserTests{
 		app: handlers.APIMux(handlers.APIMuxConfig{
-			Shutdown: shutdown,
-			Log:      test.Log,
-			Auth:     test.Auth,
-			DB:       test.DB,
+			Shutdown:    shutdown,
+			Log:         test.Log,
+			Auth:        test.Auth,
+			Revocations: revocations,
+			DB:          test.DB,
 		}),
 		userToken:  test.Token("user@example.com", "gophers"),
 		adminToken: test.Token("admin@example.com", "gophers"),
@@ -81,6 +87,7 @@ func TestUsers(t *testing.T) {
 	t.Run("putUser404", tests.putUser404())
 	t.Run("getUsers200", tests.getUsers200(usrs))
 	t.Run("crudUsers", tests.crudUser())
+	t.Run("getToken429", tests.getToken429())
 
 }
 
@@ -123,6 +130,44 @@ func (ut *UserTests) getToken200() func(t *testing.T) {
 	}
 }
 
+func (ut *UserTests) getToken429() func(t *testing.T) {
+	return func(t *testing.T) {
+		login := func(pass string) int {
+			r := httptest.NewRequest(http.MethodGet, "/v1/users/token", nil)
+			w := httptest.NewRecorder()
+
+			r.SetBasicAuth("user@example.com", pass)
+			ut.app.ServeHTTP(w, r)
+
+			return w.Code
+		}
+
+		for i := 0; i < usercore.MaxFailedLogins; i++ {
+			if code := login("guess"); code != http.StatusMethodNotAllowed {
+				t.Fatalf("Should receive a status code of 405 for wrong password %d : %d", i, code)
+			}
+		}
+
+		if code := login("gophers"); code != http.StatusTooManyRequests {
+			t.Fatalf("Should receive a status code of 429 once locked out : %d", code)
+		}
+
+		r := httptest.NewRequest(http.MethodPost, "/v1/users/45b5fbd3-755f-4379-8f07-a58d4a30fa2f/unlock", nil)
+		w := httptest.NewRecorder()
+
+		r.Header.Set("Authorization", "Bearer "+ut.adminToken)
+		ut.app.ServeHTTP(w, r)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200 for the unlock : %d", w.Code)
+		}
+
+		if code := login("gophers"); code != http.StatusOK {
+			t.Fatalf("Should receive a status code of 200 once unlocked : %d", code)
+		}
+	}
+}
+
 func (ut *UserTests) postUser400() func(t *testing.T) {
 	return func(t *testing.T) {
 		usr := user.NewUser{
@@ -375,7 +420,8 @@ func (ut *UserTests) crudUser() func(t *testing.T) {
 
 		ut.getUser200(t, usr.ID.String())
 		ut.putUser200(t, usr.ID.String())
-		ut.putUser401(t, usr.ID.String())
+		ut.putUser403(t, usr.ID.String())
+		ut.putUserDisabled(t, usr.ID.String())
 	}
 }
 
@@ -561,7 +607,7 @@ func (ut *UserTests) putUser200(t *testing.T, id string) {
 	}
 }
 
-func (ut *UserTests) putUser401(t *testing.T, id string) {
+func (ut *UserTests) putUser403(t *testing.T, id string) {
 	u := user.NewUser{
 		Name: "Bill Ken",
 	}
@@ -578,7 +624,59 @@ func (ut *UserTests) putUser401(t *testing.T, id string) {
 	r.Header.Set("Authorization", "Bearer "+ut.userToken)
 	ut.app.ServeHTTP(w, r)
 
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Should receive a status code of 403 for the response : %d", w.Code)
+	}
+}
+
+func (ut *UserTests) putUserDisabled(t *testing.T, id string) {
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/token", nil)
+	w := httptest.NewRecorder()
+
+	r.SetBasicAuth("tcmhoang@outlook.com", "gophers")
+	ut.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the token : %d", w.Code)
+	}
+
+	var tkn struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&tkn); err != nil {
+		t.Fatalf("Should be able to unmarshal the response : %s", err)
+	}
+
+	url := fmt.Sprintf("/v1/users/%s", id)
+	rename := `{"name":"Conrad Hoang"}`
+
+	r = httptest.NewRequest(http.MethodPut, url, strings.NewReader(rename))
+	w = httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+tkn.Token)
+	ut.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 before being disabled : %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPut, url, strings.NewReader(`{"enabled":false}`))
+	w = httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+ut.adminToken)
+	ut.app.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Should receive a status code of 200 for the update : %d", w.Code)
+	}
+
+	r = httptest.NewRequest(http.MethodPut, url, strings.NewReader(rename))
+	w = httptest.NewRecorder()
+
+	r.Header.Set("Authorization", "Bearer "+tkn.Token)
+	ut.app.ServeHTTP(w, r)
+
 	if w.Code != http.StatusUnauthorized {
-		t.Fatalf("Should receive a status code of 401 for the response : %d", w.Code)
+		t.Fatalf("Should receive a status code of 401 once disabled : %d", w.Code)
 	}
 }
@@ -1,5 +1,49 @@
 package commands
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/conf"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
 
 var ErrHelp = errors.New("provided help")
+
+// Run executes the command named by the first argument.
+func Run(log *zap.SugaredLogger, cfg database.Config, keysFolder string, args conf.Args) error {
+	switch args.Num(0) {
+	case "genkey":
+		return GenKey()
+	case "gentoken":
+		return genToken(log, cfg, keysFolder, args.Num(1), args.Num(2))
+	case "keyadd":
+		return keyAdd(keysFolder, args.Num(1))
+	case "keyactivate":
+		return keyActivate(keysFolder, args.Num(1))
+	case "keyretire":
+		return keyRetire(keysFolder, args.Num(1), args.Num(2))
+	case "keyprune":
+		return keyPrune(keysFolder, time.Now())
+	case "migrate":
+		return migrate(cfg)
+	case "seed":
+		return seed(cfg)
+	case "fxload":
+		return fxLoad(log, cfg, args.Num(1))
+	default:
+		fmt.Println("genkey: generate a set of private/public key files")
+		fmt.Println("gentoken: generate a token for a user")
+		fmt.Println("keyadd: add a signing key to the keys folder")
+		fmt.Println("keyactivate: sign new tokens with a key of the keys folder")
+		fmt.Println("keyretire: stop a key from signing, verifying until a deadline")
+		fmt.Println("keyprune: remove the retired keys past their deadline")
+		fmt.Println("migrate: create the schema in the database")
+		fmt.Println("seed: add data to the database")
+		fmt.Println("fxload: load exchange rates from a CSV file")
+		fmt.Println("provide a command to get more help")
+		return ErrHelp
+	}
+}
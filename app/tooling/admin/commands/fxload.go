@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tcmhoang/sservices/business/core/fx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+func fxLoad(log *zap.SugaredLogger, cfg database.Config, path string) error {
+	if path == "" {
+		fmt.Println("help: fxload <file.csv>")
+		fmt.Println("the file starts with the header base,quote,rate,as_of")
+		return ErrHelp
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening rates: %w", err)
+	}
+	defer f.Close()
+
+	nrs, err := fx.ParseCSV(f)
+	if err != nil {
+		return fmt.Errorf("parsing rates: %w", err)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := fx.NewCore(log, db).Load(ctx, nrs); err != nil {
+		return fmt.Errorf("loading rates: %w", err)
+	}
+
+	fmt.Printf("%d exchange rates loaded\n", len(nrs))
+	return nil
+}
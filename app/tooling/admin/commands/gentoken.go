@@ -16,7 +16,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func genToken(log *zap.SugaredLogger, cfg database.Config, userIDStr string, kid string) error {
+func genToken(log *zap.SugaredLogger, cfg database.Config, keysFolder string, userIDStr string, kid string) error {
 	if userIDStr == "" || kid == "" {
 		fmt.Println("help: gentoken <user_id> <kid>")
 		return ErrHelp
@@ -44,15 +44,12 @@ func genToken(log *zap.SugaredLogger, cfg database.Config, userIDStr string, kid
 		return fmt.Errorf("retrieve user: %w", err)
 	}
 
-	keysFolder := "zarf/keys/"
 	ks, err := keystore.NewFS(os.DirFS(keysFolder))
 	if err != nil {
 		return fmt.Errorf("reading keys: %w", err)
 	}
 
-	// TODO(tcmhoang): move read key info to database
-	activeKID := "private"
-	a, err := auth.New(activeKID, ks)
+	a, err := auth.New(kid, ks)
 	if err != nil {
 		return fmt.Errorf("constructing auth: %w", err)
 	}
@@ -64,6 +61,8 @@ func genToken(log *zap.SugaredLogger, cfg database.Config, userIDStr string, kid
 			roles = append(roles, auth.Admin)
 		case "user":
 			roles = append(roles, auth.User)
+		case "housekeeping":
+			roles = append(roles, auth.Housekeeping)
 
 		default:
 
@@ -72,6 +71,7 @@ func genToken(log *zap.SugaredLogger, cfg database.Config, userIDStr string, kid
 
 	claims := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Issuer:    "service project",
 			Subject:   usr.ID.String(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(8760 * time.Hour).UTC()),
@@ -0,0 +1,199 @@
+package commands
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tcmhoang/sservices/foundation/keystore"
+)
+
+// A rotation adds a key, activates it and retires the previous one for longer
+// than the tokens it signed live. The service picks every step up when it
+// reloads the keys folder.
+
+func keyAdd(folder string, kid string) error {
+	if kid == "" {
+		fmt.Println("help: keyadd <kid>")
+		return ErrHelp
+	}
+
+	p, err := keyPath(folder, kid)
+	if err != nil {
+		return err
+	}
+
+	privkey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("creating key file: %w", err)
+	}
+	defer file.Close()
+
+	privblk := pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privkey),
+	}
+
+	if err := pem.Encode(file, &privblk); err != nil {
+		return fmt.Errorf("encoding to key file: %w", err)
+	}
+
+	fmt.Printf("key %s added, it verifies tokens once the service reloads its keys\n", kid)
+
+	return nil
+}
+
+func keyActivate(folder string, kid string) error {
+	if kid == "" {
+		fmt.Println("help: keyactivate <kid>")
+		return ErrHelp
+	}
+
+	if err := checkKey(folder, kid); err != nil {
+		return err
+	}
+
+	m, err := keystore.ReadManifest(os.DirFS(folder))
+	if err != nil {
+		return err
+	}
+
+	prev := m.Active
+	m.Active = kid
+	delete(m.Retired, kid)
+
+	if err := keystore.WriteManifest(folder, m); err != nil {
+		return err
+	}
+
+	fmt.Printf("key %s signs new tokens once the service reloads its keys\n", kid)
+	if prev != "" && prev != kid {
+		fmt.Printf("key %s is no longer active but still verifies tokens, retire it with keyretire\n", prev)
+	}
+
+	return nil
+}
+
+func keyRetire(folder string, kid string, grace string) error {
+	if kid == "" || grace == "" {
+		fmt.Println("help: keyretire <kid> <grace>")
+		fmt.Println("the key verifies tokens for the grace duration, such as 2h, which must")
+		fmt.Println("outlive the tokens it signed")
+		return ErrHelp
+	}
+
+	d, err := time.ParseDuration(grace)
+	if err != nil || d < 0 {
+		return fmt.Errorf("parsing grace[%s]: must be a positive duration", grace)
+	}
+
+	if err := checkKey(folder, kid); err != nil {
+		return err
+	}
+
+	m, err := keystore.ReadManifest(os.DirFS(folder))
+	if err != nil {
+		return err
+	}
+
+	switch m.Active {
+	case "":
+		return errors.New("the manifest names no active key, run keyactivate first")
+	case kid:
+		return fmt.Errorf("key %s is active, activate another key first", kid)
+	}
+
+	until := time.Now().Add(d).UTC().Truncate(time.Second)
+	m.Retired[kid] = until
+
+	if err := keystore.WriteManifest(folder, m); err != nil {
+		return err
+	}
+
+	fmt.Printf("key %s signs nothing and verifies tokens until %s\n", kid, until.Format(time.RFC3339))
+
+	return nil
+}
+
+func keyPrune(folder string, now time.Time) error {
+	m, err := keystore.ReadManifest(os.DirFS(folder))
+	if err != nil {
+		return err
+	}
+
+	var pruned []string
+	for kid, until := range m.Retired {
+		if now.Before(until) {
+			continue
+		}
+
+		p, err := keyPath(folder, kid)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("removing key file: %w", err)
+		}
+
+		delete(m.Retired, kid)
+		pruned = append(pruned, kid)
+	}
+
+	if len(pruned) == 0 {
+		fmt.Println("no retired key is past its deadline")
+		return nil
+	}
+
+	if err := keystore.WriteManifest(folder, m); err != nil {
+		return err
+	}
+
+	sort.Strings(pruned)
+	fmt.Printf("keys removed: %s\n", strings.Join(pruned, ", "))
+
+	return nil
+}
+
+// keyPath is the file of the key in the keys folder. Kids name files, so
+// they cannot reach out of the folder.
+func keyPath(folder string, kid string) (string, error) {
+	if kid != filepath.Base(kid) || strings.HasPrefix(kid, ".") {
+		return "", fmt.Errorf("invalid kid[%s]", kid)
+	}
+
+	return filepath.Join(folder, kid+".pem"), nil
+}
+
+// checkKey makes sure the key exists and parses.
+func checkKey(folder string, kid string) error {
+	p, err := keyPath(folder, kid)
+	if err != nil {
+		return err
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return fmt.Errorf("reading key file: %w", err)
+	}
+
+	if _, err := jwt.ParseRSAPrivateKeyFromPEM(b); err != nil {
+		return fmt.Errorf("parsing key file: %w", err)
+	}
+
+	return nil
+}
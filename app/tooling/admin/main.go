@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/ardanlabs/conf"
+	"github.com/tcmhoang/sservices/app/tooling/admin/commands"
+	"github.com/tcmhoang/sservices/business/sys/database"
 	"github.com/tcmhoang/sservices/foundation/logger"
 	"go.uber.org/zap"
 )
@@ -20,12 +24,56 @@ func main() {
 	defer log.Sync()
 
 	if err := run(log); err != nil {
+		if !errors.Is(err, commands.ErrHelp) {
+			log.Errorw("admin", "ERROR", err)
+		}
 		os.Exit(1)
 	}
 
 }
 
-func run(logger *zap.SugaredLogger) error {
-	// TODO(tcmhoang): need to handle cmd passed-in to call the right functions
-	return nil
+func run(log *zap.SugaredLogger) error {
+	cfg := struct {
+		conf.Version
+		Args conf.Args
+		Auth struct {
+			KeysFolder string `conf:"default:zarf/keys/"`
+		}
+		DB struct {
+			User         string `conf:"default:postgres"`
+			Password     string `conf:"default:postgres,mask"`
+			Host         string `conf:"default:localhost"`
+			Name         string `conf:"default:postgres"`
+			MaxIdleConns int    `conf:"default:0"`
+			MaxOpenConns int    `conf:"default:0"`
+			DisableTLS   bool   `conf:"default:true"`
+		}
+	}{
+		Version: conf.Version{
+			SVN:  build,
+			Desc: "TCMHOANG",
+		},
+	}
+
+	const prefix = "SALES"
+	help, err := conf.ParseOSArgs(prefix, &cfg)
+	if err != nil {
+		if errors.Is(err, conf.ErrHelpWanted) {
+			fmt.Println(help)
+			return nil
+		}
+		return fmt.Errorf("parsing config: %w", err)
+	}
+
+	dbConfig := database.Config{
+		User:         cfg.DB.User,
+		Password:     cfg.DB.Password,
+		Host:         cfg.DB.Host,
+		Name:         cfg.DB.Name,
+		MaxIdleConns: cfg.DB.MaxIdleConns,
+		MaxOpenConns: cfg.DB.MaxOpenConns,
+		DisableTLS:   cfg.DB.DisableTLS,
+	}
+
+	return commands.Run(log, dbConfig, cfg.Auth.KeysFolder, cfg.Args)
 }
@@ -0,0 +1,416 @@
+// Package assignment provides the core business API for giving physical rooms
+// to the reservations arriving on a day. Planning is pure and deterministic,
+// so the same arrivals and rooms always get the same rooms.
+package assignment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotAssignable = errors.New("only held or confirmed reservations can be assigned a room")
+	ErrWrongRoom     = errors.New("room does not belong to the room type of the reservation")
+	ErrOutOfService  = errors.New("room is out of service")
+	ErrRoomTaken     = errors.New("room is assigned to another stay on those nights")
+)
+
+// What a room costs a stay when it misses one of the stay's preferences.
+// Rooms are picked by lowest cost. A connecting room matters most, then
+// keeping accessible rooms for the guests who need them, then the floor.
+const (
+	costConnecting = 1000
+	costAccessible = 100
+	costFloor      = 10
+)
+
+// maxGap caps how many free nights after a stay count against a room, so
+// rooms with nothing booked after the stay cost the same.
+const maxGap = 30
+
+// Assignment is a room given to a reservation.
+type Assignment struct {
+	ReservationID uuid.UUID `json:"reservationID"`
+	RoomID        uuid.UUID `json:"roomID"`
+	Number        string    `json:"number"`
+}
+
+// Plan is what assigning rooms to a day's arrivals came to. Unassigned lists
+// the arrivals no room could take for the whole of their stay.
+type Plan struct {
+	Assigned   []Assignment `json:"assigned"`
+	Unassigned []uuid.UUID  `json:"unassigned"`
+}
+
+// span is the nights a room is taken, check-out excluded.
+type span struct {
+	from time.Time
+	to   time.Time
+}
+
+// Assign plans rooms for the arrivals. booked are the reservations already
+// assigned a room over the nights the arrivals stay, the arrivals that have
+// one included, which keep it. Rooms are not given out over the nights of
+// their out-of-order periods.
+//
+// A stay is only ever given a room free for every one of its nights, so
+// guests never move rooms. Accessible stays only get accessible rooms. Among
+// the rest, rooms cost a stay for each preference they miss and for every
+// free night they would be left with before their next booking, so short
+// stays fill the gaps and rooms free for long runs are kept for long stays.
+// Ties go to the lowest floor and room number.
+func Assign(arrivals []reservation.Reservation, rooms []room.Room, booked []reservation.Reservation, periods []outoforder.Period) Plan {
+	busy := make(map[uuid.UUID][]span)
+	for _, o := range periods {
+		busy[o.RoomID] = append(busy[o.RoomID], span{from: o.Starts, to: o.Ends})
+	}
+	roomOf := make(map[uuid.UUID]uuid.UUID)
+	for _, b := range booked {
+		if b.RoomID == nil {
+			continue
+		}
+		busy[*b.RoomID] = append(busy[*b.RoomID], span{from: b.CheckIn, to: b.CheckOut})
+		roomOf[b.ID] = *b.RoomID
+	}
+
+	pending := make([]reservation.Reservation, 0, len(arrivals))
+	for _, a := range arrivals {
+		if _, ok := roomOf[a.ID]; !ok {
+			pending = append(pending, a)
+		}
+	}
+
+	byID := make(map[uuid.UUID]reservation.Reservation)
+	partners := make(map[uuid.UUID][]uuid.UUID)
+	for _, rs := range [][]reservation.Reservation{booked, arrivals} {
+		for _, r := range rs {
+			if _, ok := byID[r.ID]; ok {
+				continue
+			}
+			byID[r.ID] = r
+			if r.ConnectWith != nil {
+				partners[r.ID] = append(partners[r.ID], *r.ConnectWith)
+				partners[*r.ConnectWith] = append(partners[*r.ConnectWith], r.ID)
+			}
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		a, b := pending[i], pending[j]
+		switch {
+		case a.Accessible != b.Accessible:
+			return a.Accessible
+		case (len(partners[a.ID]) > 0) != (len(partners[b.ID]) > 0):
+			return len(partners[a.ID]) > 0
+		case nights(a.CheckIn, a.CheckOut) != nights(b.CheckIn, b.CheckOut):
+			return nights(a.CheckIn, a.CheckOut) > nights(b.CheckIn, b.CheckOut)
+		default:
+			return a.ID.String() < b.ID.String()
+		}
+	})
+
+	sorted := make([]room.Room, len(rooms))
+	copy(sorted, rooms)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Floor != sorted[j].Floor {
+			return sorted[i].Floor < sorted[j].Floor
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	fits := func(rm room.Room, r reservation.Reservation) bool {
+		if rm.RoomTypeID != r.RoomTypeID ||
+			rm.Status == room.StatusOutOfService ||
+			rm.Housekeeping == room.HousekeepingOutOfOrder ||
+			(r.Accessible && !rm.Accessible) {
+			return false
+		}
+		for _, s := range busy[rm.ID] {
+			if r.CheckIn.Before(s.to) && s.from.Before(r.CheckOut) {
+				return false
+			}
+		}
+		return true
+	}
+
+	cost := func(rm room.Room, r reservation.Reservation) int {
+		c := 0
+
+		if rm.Accessible && !r.Accessible {
+			c += costAccessible
+		}
+
+		if r.PreferredFloor != nil {
+			d := rm.Floor - *r.PreferredFloor
+			if d < 0 {
+				d = -d
+			}
+			c += d * costFloor
+		}
+
+		for _, pid := range partners[r.ID] {
+			if prm, ok := roomOf[pid]; ok {
+				if !connects(rm, prm, sorted) {
+					c += costConnecting
+				}
+				continue
+			}
+
+			p, ok := byID[pid]
+			if !ok {
+				continue
+			}
+
+			free := false
+			for _, other := range sorted {
+				if other.ID != rm.ID && connects(rm, other.ID, sorted) && fits(other, p) {
+					free = true
+					break
+				}
+			}
+			if !free {
+				c += costConnecting
+			}
+		}
+
+		gap := maxGap
+		for _, s := range busy[rm.ID] {
+			if !s.from.Before(r.CheckOut) {
+				if n := nights(r.CheckOut, s.from); n < gap {
+					gap = n
+				}
+			}
+		}
+
+		return c + gap
+	}
+
+	var p Plan
+
+	for _, r := range pending {
+		best := -1
+		bestCost := 0
+		for i, rm := range sorted {
+			if !fits(rm, r) {
+				continue
+			}
+			if c := cost(rm, r); best == -1 || c < bestCost {
+				best, bestCost = i, c
+			}
+		}
+
+		if best == -1 {
+			p.Unassigned = append(p.Unassigned, r.ID)
+			continue
+		}
+
+		rm := sorted[best]
+		busy[rm.ID] = append(busy[rm.ID], span{from: r.CheckIn, to: r.CheckOut})
+		roomOf[r.ID] = rm.ID
+	}
+
+	numbers := make(map[uuid.UUID]string)
+	for _, rm := range rooms {
+		numbers[rm.ID] = rm.Number
+	}
+
+	for _, a := range arrivals {
+		if id, ok := roomOf[a.ID]; ok {
+			p.Assigned = append(p.Assigned, Assignment{ReservationID: a.ID, RoomID: id, Number: numbers[id]})
+		}
+	}
+
+	return p
+}
+
+// connects reports whether rm and the room with the given ID connect. Rooms
+// connect when either one points to the other.
+func connects(rm room.Room, otherID uuid.UUID, rooms []room.Room) bool {
+	if rm.ConnectsTo != nil && *rm.ConnectsTo == otherID {
+		return true
+	}
+	for _, other := range rooms {
+		if other.ID == otherID {
+			return other.ConnectsTo != nil && *other.ConnectsTo == rm.ID
+		}
+	}
+	return false
+}
+
+// nights counts the nights between two dates.
+func nights(from time.Time, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+type Core struct {
+	log         *zap.SugaredLogger
+	db          *sqlx.DB
+	reservation reservation.Store
+	room        room.Store
+	outOfOrder  outoforder.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:         log,
+		db:          db,
+		reservation: *reservation.NewStore(log, db),
+		room:        *room.NewStore(log, db),
+		outOfOrder:  *outoforder.NewStore(log, db),
+	}
+}
+
+// Arrivals assigns rooms to the confirmed reservations arriving at the
+// property on the day. Arrivals that already have a room keep it. The rooms
+// of the property stay locked while planning, so runs and overrides on the
+// same property happen one at a time.
+func (c *Core) Arrivals(ctx context.Context, propertyID uuid.UUID, day time.Time, now time.Time) (Plan, error) {
+	day = date.Truncate(day)
+
+	var p Plan
+
+	tran := func(tx sqlx.ExtContext) error {
+		resStore := c.reservation.Tran(tx)
+		rmStore := c.room.Tran(tx)
+		oooStore := c.outOfOrder.Tran(tx)
+
+		rms, err := rmStore.QueryByPropertyIDForUpdate(ctx, propertyID)
+		if err != nil {
+			return fmt.Errorf("querybypropertyid: %w", err)
+		}
+
+		arrivals, err := resStore.QueryArrivalsForUpdate(ctx, propertyID, day)
+		if err != nil {
+			return fmt.Errorf("queryarrivals: %w", err)
+		}
+
+		last := day
+		for _, a := range arrivals {
+			if a.CheckOut.After(last) {
+				last = a.CheckOut
+			}
+		}
+
+		booked, err := resStore.QueryAssigned(ctx, propertyID, day, last.AddDate(0, 0, maxGap))
+		if err != nil {
+			return fmt.Errorf("queryassigned: %w", err)
+		}
+
+		periods, err := oooStore.QueryByPropertyID(ctx, propertyID, day, last.AddDate(0, 0, maxGap))
+		if err != nil {
+			return fmt.Errorf("querybypropertyid: %w", err)
+		}
+
+		p = Assign(arrivals, rms, booked, periods)
+
+		for _, a := range arrivals {
+			if a.RoomID != nil {
+				continue
+			}
+			for _, as := range p.Assigned {
+				if as.ReservationID != a.ID {
+					continue
+				}
+
+				a.RoomID = &as.RoomID
+				a.DateUpdated = now
+				if err := resStore.UpdateRoom(ctx, a); err != nil {
+					return fmt.Errorf("updateroom: %w", err)
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return Plan{}, err
+	}
+
+	return p, nil
+}
+
+// Override assigns the reservation to the room the front desk picked, or
+// leaves it unassigned without one. The room must be of the reservation's
+// room type, free for the whole stay and in order every night of it.
+func (c *Core) Override(ctx context.Context, reservationID uuid.UUID, ar reservation.AssignRoom, now time.Time) (reservation.Reservation, error) {
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		resStore := c.reservation.Tran(tx)
+		rmStore := c.room.Tran(tx)
+		oooStore := c.outOfOrder.Tran(tx)
+
+		var err error
+		res, err = resStore.QueryByIDForUpdate(ctx, reservationID)
+		if err != nil {
+			return fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+		}
+
+		if res.Status != reservation.StatusHeld && res.Status != reservation.StatusConfirmed {
+			return ErrNotAssignable
+		}
+
+		if ar.RoomID != nil {
+			rm, err := rmStore.QueryByIDForUpdate(ctx, *ar.RoomID)
+			if err != nil {
+				return fmt.Errorf("querybyid: roomID[%s]: %w", *ar.RoomID, err)
+			}
+
+			if rm.RoomTypeID != res.RoomTypeID {
+				return ErrWrongRoom
+			}
+
+			if rm.Status == room.StatusOutOfService || rm.Housekeeping == room.HousekeepingOutOfOrder {
+				return ErrOutOfService
+			}
+
+			periods, err := oooStore.QueryByRoomID(ctx, rm.ID, res.CheckIn, res.CheckOut)
+			if err != nil {
+				return fmt.Errorf("querybyroomid: %w", err)
+			}
+
+			if len(periods) > 0 {
+				return ErrOutOfService
+			}
+
+			booked, err := resStore.QueryAssigned(ctx, res.PropertyID, res.CheckIn, res.CheckOut)
+			if err != nil {
+				return fmt.Errorf("queryassigned: %w", err)
+			}
+
+			for _, b := range booked {
+				if b.ID != res.ID && *b.RoomID == rm.ID {
+					return ErrRoomTaken
+				}
+			}
+		}
+
+		res.RoomID = ar.RoomID
+		res.DateUpdated = now
+
+		if err := resStore.UpdateRoom(ctx, res); err != nil {
+			return fmt.Errorf("updateroom: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,226 @@
+package assignment_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/assignment"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+var (
+	day      = time.Date(2030, time.March, 1, 0, 0, 0, 0, time.UTC)
+	king     = uuid.MustParse("a0000000-0000-4000-8000-000000000001")
+	twin     = uuid.MustParse("a0000000-0000-4000-8000-000000000002")
+	resAlice = uuid.MustParse("b0000000-0000-4000-8000-000000000001")
+	resBob   = uuid.MustParse("b0000000-0000-4000-8000-000000000002")
+	resCarol = uuid.MustParse("b0000000-0000-4000-8000-000000000003")
+	resDave  = uuid.MustParse("b0000000-0000-4000-8000-000000000004")
+)
+
+// roomID gives every room number a stable ID.
+func roomID(number string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(number))
+}
+
+func mkRoom(number string, floor int, opts ...func(*room.Room)) room.Room {
+	rm := room.Room{
+		ID:           roomID(number),
+		RoomTypeID:   king,
+		Number:       number,
+		Floor:        floor,
+		Status:       room.StatusAvailable,
+		Housekeeping: room.HousekeepingClean,
+	}
+	for _, opt := range opts {
+		opt(&rm)
+	}
+	return rm
+}
+
+func mkStay(id uuid.UUID, from int, to int, opts ...func(*reservation.Reservation)) reservation.Reservation {
+	res := reservation.Reservation{
+		ID:         id,
+		RoomTypeID: king,
+		CheckIn:    day.AddDate(0, 0, from),
+		CheckOut:   day.AddDate(0, 0, to),
+		Status:     reservation.StatusConfirmed,
+	}
+	for _, opt := range opts {
+		opt(&res)
+	}
+	return res
+}
+
+func accessibleRoom(rm *room.Room)              { rm.Accessible = true }
+func accessibleStay(r *reservation.Reservation) { r.Accessible = true }
+
+func connectsTo(number string) func(*room.Room) {
+	return func(rm *room.Room) {
+		id := roomID(number)
+		rm.ConnectsTo = &id
+	}
+}
+
+func floor(f int) func(*reservation.Reservation) {
+	return func(r *reservation.Reservation) { r.PreferredFloor = &f }
+}
+
+func connectWith(id uuid.UUID) func(*reservation.Reservation) {
+	return func(r *reservation.Reservation) { r.ConnectWith = &id }
+}
+
+func in(number string) func(*reservation.Reservation) {
+	return func(r *reservation.Reservation) {
+		id := roomID(number)
+		r.RoomID = &id
+	}
+}
+
+func TestAssign(t *testing.T) {
+	table := []struct {
+		name     string
+		arrivals []reservation.Reservation
+		rooms    []room.Room
+		booked   []reservation.Reservation
+		periods  []outoforder.Period
+		exp      map[uuid.UUID]string
+	}{
+		{
+			name:     "lowest floor and number without preferences",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2)},
+			rooms:    []room.Room{mkRoom("202", 2), mkRoom("102", 1), mkRoom("101", 1)},
+			exp:      map[uuid.UUID]string{resAlice: "101"},
+		},
+		{
+			name:     "preferred floor",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2, floor(5)), mkStay(resBob, 0, 2, floor(1))},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("301", 3), mkRoom("501", 5)},
+			exp:      map[uuid.UUID]string{resAlice: "501", resBob: "101"},
+		},
+		{
+			name:     "accessible rooms kept for guests who need them",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2), mkStay(resBob, 0, 2, accessibleStay)},
+			rooms:    []room.Room{mkRoom("101", 1, accessibleRoom), mkRoom("102", 1)},
+			exp:      map[uuid.UUID]string{resAlice: "102", resBob: "101"},
+		},
+		{
+			name:     "accessible stays only get accessible rooms",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2, accessibleStay)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("102", 1)},
+			exp:      map[uuid.UUID]string{resAlice: ""},
+		},
+		{
+			name:     "connecting rooms for companions",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2), mkStay(resBob, 0, 2, connectWith(resAlice)), mkStay(resCarol, 0, 2)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("102", 1), mkRoom("201", 2, connectsTo("202")), mkRoom("202", 2)},
+			exp:      map[uuid.UUID]string{resAlice: "201", resBob: "202", resCarol: "101"},
+		},
+		{
+			name:     "connecting room next to a companion already in",
+			arrivals: []reservation.Reservation{mkStay(resBob, 0, 2, connectWith(resAlice))},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("201", 2, connectsTo("202")), mkRoom("202", 2)},
+			booked:   []reservation.Reservation{mkStay(resAlice, -1, 2, in("202"))},
+			exp:      map[uuid.UUID]string{resBob: "201"},
+		},
+		{
+			name:     "one room for the whole stay",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 4)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("102", 1)},
+			booked:   []reservation.Reservation{mkStay(resCarol, 2, 3, in("101"))},
+			exp:      map[uuid.UUID]string{resAlice: "102"},
+		},
+		{
+			name:     "short stays fill the gaps before other bookings",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 1), mkStay(resBob, 0, 5)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("102", 1)},
+			booked:   []reservation.Reservation{mkStay(resCarol, 1, 3, in("102"))},
+			exp:      map[uuid.UUID]string{resAlice: "102", resBob: "101"},
+		},
+		{
+			name:     "no room free for the whole stay",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 3)},
+			rooms:    []room.Room{mkRoom("101", 1)},
+			booked:   []reservation.Reservation{mkStay(resCarol, 2, 4, in("101"))},
+			exp:      map[uuid.UUID]string{resAlice: ""},
+		},
+		{
+			name:     "arrivals with a room keep it",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2, floor(1), in("201")), mkStay(resBob, 0, 2)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("201", 2)},
+			booked:   []reservation.Reservation{mkStay(resAlice, 0, 2, floor(1), in("201"))},
+			exp:      map[uuid.UUID]string{resAlice: "201", resBob: "101"},
+		},
+		{
+			name:     "rooms out of order any night of the stay are skipped",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 3), mkStay(resBob, 0, 1)},
+			rooms:    []room.Room{mkRoom("101", 1), mkRoom("102", 1)},
+			periods:  []outoforder.Period{{RoomID: roomID("101"), Starts: day.AddDate(0, 0, 2), Ends: day.AddDate(0, 0, 4)}},
+			exp:      map[uuid.UUID]string{resAlice: "102", resBob: "101"},
+		},
+		{
+			name:     "rooms out of order or of another type are skipped",
+			arrivals: []reservation.Reservation{mkStay(resAlice, 0, 2), mkStay(resDave, 0, 2)},
+			rooms: []room.Room{
+				mkRoom("101", 1, func(rm *room.Room) { rm.Status = room.StatusOutOfService }),
+				mkRoom("102", 1, func(rm *room.Room) { rm.Housekeeping = room.HousekeepingOutOfOrder }),
+				mkRoom("103", 1, func(rm *room.Room) { rm.RoomTypeID = twin }),
+				mkRoom("104", 1),
+			},
+			exp: map[uuid.UUID]string{resAlice: "104", resDave: ""},
+		},
+	}
+
+	t.Log("Given the need to assign rooms to the day's arrivals.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got := numbers(assignment.Assign(tt.arrivals, tt.rooms, tt.booked, tt.periods))
+
+				if fmt.Sprint(got) != fmt.Sprint(tt.exp) {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould assign the expected rooms.", tests.Failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould assign the expected rooms.", tests.Success, testID)
+
+				arrivals := reverse(tt.arrivals)
+				rooms := reverse(tt.rooms)
+				if again := numbers(assignment.Assign(arrivals, rooms, tt.booked, tt.periods)); fmt.Sprint(again) != fmt.Sprint(got) {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, again)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, got)
+					t.Fatalf("\t%s\tTest %d:\tShould assign the same rooms whatever the input order.", tests.Failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould assign the same rooms whatever the input order.", tests.Success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+// numbers maps every reservation of the plan to its room number, unassigned
+// ones to an empty string.
+func numbers(p assignment.Plan) map[uuid.UUID]string {
+	m := make(map[uuid.UUID]string)
+	for _, a := range p.Assigned {
+		m[a.ReservationID] = a.Number
+	}
+	for _, id := range p.Unassigned {
+		m[id] = ""
+	}
+	return m
+}
+
+func reverse[T any](s []T) []T {
+	r := make([]T, len(s))
+	for i, v := range s {
+		r[len(s)-1-i] = v
+	}
+	return r
+}
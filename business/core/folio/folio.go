@@ -0,0 +1,406 @@
+// Package folio provides the core business API for billing stays: posting
+// charges and payments to the folio of each reservation and rendering it as
+// an invoice.
+package folio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	salecore "github.com/tcmhoang/sservices/business/core/sale"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/folio"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/taxrule"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotBillable = errors.New("reservation has no stay to bill")
+	ErrOverRefund  = errors.New("refund is more than the guest paid")
+)
+
+// Statement is a folio with its lines and totals.
+type Statement struct {
+	ReservationID uuid.UUID    `json:"reservationID"`
+	Lines         []folio.Line `json:"lines"`
+	Charges       int          `json:"charges"`
+	Payments      int          `json:"payments"`
+	Refunds       int          `json:"refunds"`
+	Balance       int          `json:"balance"`
+}
+
+// Summarize totals the lines of the reservation's folio. A positive balance
+// is owed by the guest, a negative one is owed to the guest.
+func Summarize(reservationID uuid.UUID, lines []folio.Line) Statement {
+	st := Statement{
+		ReservationID: reservationID,
+		Lines:         lines,
+	}
+	if st.Lines == nil {
+		st.Lines = []folio.Line{}
+	}
+
+	for _, l := range lines {
+		switch l.Kind {
+		case folio.KindPayment:
+			st.Payments -= l.Amount
+		case folio.KindRefund:
+			st.Refunds += l.Amount
+		default:
+			st.Charges += l.Amount
+		}
+		st.Balance += l.Amount
+	}
+
+	return st
+}
+
+type Core struct {
+	log         *zap.SugaredLogger
+	db          *sqlx.DB
+	Store       folio.Store
+	Reservation reservation.Store
+	product     product.Store
+	property    property.Store
+	tax         *tax.Core
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:         log,
+		db:          db,
+		Store:       *folio.NewStore(log, db),
+		Reservation: *reservation.NewStore(log, db),
+		product:     *product.NewStore(log, db),
+		property:    *property.NewStore(log, db),
+		tax:         tax.NewCore(log, db),
+	}
+}
+
+// Statement returns the folio of the reservation. A reservation nothing was
+// posted for yet has an empty folio.
+func (c *Core) Statement(ctx context.Context, reservationID uuid.UUID) (Statement, error) {
+	return c.statement(ctx, c.Store, reservationID)
+}
+
+// PostStay charges every night of the reservation at the price it was booked
+// at, followed by the taxes and fees of the property on the stay. It runs
+// inside tx, along with the check-out that triggers it, and dates the lines
+// at now, the time of the check-out.
+func (c *Core) PostStay(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, userID uuid.UUID, now time.Time) error {
+	fStore := c.Store.Tran(tx)
+	resStore := c.Reservation.Tran(tx)
+
+	f, err := fStore.Open(ctx, res.ID, now)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	nights, err := resStore.QueryNights(ctx, res.ID)
+	if err != nil {
+		return fmt.Errorf("querynights: %w", err)
+	}
+
+	lines := make([]folio.Line, len(nights))
+	for i, n := range nights {
+		night := n.Night
+		lines[i] = folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        folio.KindRoom,
+			Description: fmt.Sprintf("Room, night of %s", night.Format("2006-01-02")),
+			Night:       &night,
+			Quantity:    1,
+			UnitAmount:  n.Rate,
+			Amount:      n.Rate,
+			UserID:      userID,
+			DateCreated: now,
+		}
+	}
+
+	cs, err := c.tax.Reservation(ctx, tx, res)
+	if err != nil {
+		return fmt.Errorf("reservation taxes: %w", err)
+	}
+
+	for _, ch := range cs {
+		kind := folio.KindTax
+		if ch.Category == taxrule.CategoryFee {
+			kind = folio.KindFee
+		}
+
+		lines = append(lines, folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        kind,
+			Description: ch.Name,
+			Quantity:    1,
+			UnitAmount:  ch.Amount,
+			Amount:      ch.Amount,
+			UserID:      userID,
+			DateCreated: now,
+		})
+	}
+
+	if err := fStore.AddLines(ctx, lines); err != nil {
+		return fmt.Errorf("addlines: %w", err)
+	}
+
+	return nil
+}
+
+// PostCapture records money the payment gateway collected for the stay as a
+// payment referencing the gateway's capture. It runs inside tx, along with
+// the capture.
+func (c *Core) PostCapture(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, t transaction.Transaction, userID uuid.UUID) error {
+	fStore := c.Store.Tran(tx)
+
+	f, err := fStore.Open(ctx, res.ID, t.DateCreated)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+
+	l := folio.Line{
+		ID:          uuid.New(),
+		FolioID:     f.ID,
+		Kind:        folio.KindPayment,
+		Description: "Card payment",
+		Quantity:    1,
+		UnitAmount:  -t.Amount,
+		Amount:      -t.Amount,
+		Reference:   t.GatewayRef,
+		UserID:      userID,
+		DateCreated: t.DateCreated,
+	}
+
+	if err := fStore.AddLines(ctx, []folio.Line{l}); err != nil {
+		return fmt.Errorf("addlines: %w", err)
+	}
+
+	return nil
+}
+
+// PostExtra charges items from the product catalog to the reservation and
+// takes them out of stock, like a sale. The product must be priced in the
+// currency of the property, the folio is never converted.
+func (c *Core) PostExtra(ctx context.Context, reservationID uuid.UUID, ne folio.NewExtra, userID uuid.UUID, now time.Time) (Statement, error) {
+	if err := validation.Check(ne); err != nil {
+		return Statement{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	lines := func(ctx context.Context, tx sqlx.ExtContext, f folio.Folio, _ Statement, now time.Time) ([]folio.Line, error) {
+		prdStore := c.product.Tran(tx)
+		resStore := c.Reservation.Tran(tx)
+		propStore := c.property.Tran(tx)
+
+		prd, err := prdStore.QueryByIDForUpdate(ctx, ne.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("querybyid: productID[%s]: %w", ne.ProductID, err)
+		}
+
+		res, err := resStore.QueryByID(ctx, reservationID)
+		if err != nil {
+			return nil, fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+		}
+
+		prop, err := propStore.QueryByID(ctx, res.PropertyID)
+		if err != nil {
+			return nil, fmt.Errorf("querybyid: propertyID[%s]: %w", res.PropertyID, err)
+		}
+
+		if prd.Cost.Currency != prop.Currency {
+			return nil, &money.CurrencyError{Want: prop.Currency, Got: prd.Cost.Currency}
+		}
+
+		if prd.Quantity < ne.Quantity {
+			return nil, &salecore.OversellError{
+				ProductID: prd.ID,
+				Requested: ne.Quantity,
+				Available: prd.Quantity,
+			}
+		}
+
+		if err := prdStore.UpdateQuantity(ctx, prd.ID, -ne.Quantity); err != nil {
+			return nil, fmt.Errorf("updatequantity: %w", err)
+		}
+
+		l := folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        folio.KindExtra,
+			Description: prd.Name,
+			ProductID:   &prd.ID,
+			Quantity:    ne.Quantity,
+			UnitAmount:  prd.Cost.Amount,
+			Amount:      prd.Cost.Mul(ne.Quantity).Amount,
+			UserID:      userID,
+			DateCreated: now,
+		}
+
+		return []folio.Line{l}, nil
+	}
+
+	return c.post(ctx, reservationID, now, lines)
+}
+
+// PostCharge charges a tax or fee to the reservation.
+func (c *Core) PostCharge(ctx context.Context, reservationID uuid.UUID, nc folio.NewCharge, userID uuid.UUID, now time.Time) (Statement, error) {
+	if err := validation.Check(nc); err != nil {
+		return Statement{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	lines := func(ctx context.Context, tx sqlx.ExtContext, f folio.Folio, _ Statement, now time.Time) ([]folio.Line, error) {
+		l := folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        nc.Kind,
+			Description: nc.Description,
+			Quantity:    1,
+			UnitAmount:  nc.Amount,
+			Amount:      nc.Amount,
+			UserID:      userID,
+			DateCreated: now,
+		}
+
+		return []folio.Line{l}, nil
+	}
+
+	return c.post(ctx, reservationID, now, lines)
+}
+
+// PostPayment records money received from the guest.
+func (c *Core) PostPayment(ctx context.Context, reservationID uuid.UUID, np folio.NewPayment, userID uuid.UUID, now time.Time) (Statement, error) {
+	if err := validation.Check(np); err != nil {
+		return Statement{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	lines := func(ctx context.Context, tx sqlx.ExtContext, f folio.Folio, _ Statement, now time.Time) ([]folio.Line, error) {
+		l := folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        folio.KindPayment,
+			Description: "Payment",
+			Quantity:    1,
+			UnitAmount:  -np.Amount,
+			Amount:      -np.Amount,
+			Reference:   np.Reference,
+			UserID:      userID,
+			DateCreated: now,
+		}
+
+		return []folio.Line{l}, nil
+	}
+
+	return c.post(ctx, reservationID, now, lines)
+}
+
+// PostRefund records money given back to the guest. A folio never refunds
+// more than the guest paid into it.
+func (c *Core) PostRefund(ctx context.Context, reservationID uuid.UUID, np folio.NewPayment, userID uuid.UUID, now time.Time) (Statement, error) {
+	if err := validation.Check(np); err != nil {
+		return Statement{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	lines := func(ctx context.Context, tx sqlx.ExtContext, f folio.Folio, st Statement, now time.Time) ([]folio.Line, error) {
+		if st.Refunds+np.Amount > st.Payments {
+			return nil, ErrOverRefund
+		}
+
+		l := folio.Line{
+			ID:          uuid.New(),
+			FolioID:     f.ID,
+			Kind:        folio.KindRefund,
+			Description: "Refund",
+			Quantity:    1,
+			UnitAmount:  np.Amount,
+			Amount:      np.Amount,
+			Reference:   np.Reference,
+			UserID:      userID,
+			DateCreated: now,
+		}
+
+		return []folio.Line{l}, nil
+	}
+
+	return c.post(ctx, reservationID, now, lines)
+}
+
+// post adds the lines built by fn to the folio of the reservation and returns
+// the updated statement. The reservation row is locked for the transaction,
+// so fn sees the statement as it stands while no other posting can change
+// it. The lines are dated at now. Reservations that never turned into a stay
+// cannot be billed.
+func (c *Core) post(ctx context.Context, reservationID uuid.UUID, now time.Time, fn func(context.Context, sqlx.ExtContext, folio.Folio, Statement, time.Time) ([]folio.Line, error)) (Statement, error) {
+	var st Statement
+
+	tran := func(tx sqlx.ExtContext) error {
+		resStore := c.Reservation.Tran(tx)
+		fStore := c.Store.Tran(tx)
+
+		res, err := resStore.QueryByIDForUpdate(ctx, reservationID)
+		if err != nil {
+			return fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+		}
+
+		if res.Status == reservation.StatusHeld || res.Status == reservation.StatusExpired {
+			return ErrNotBillable
+		}
+
+		f, err := fStore.Open(ctx, res.ID, now)
+		if err != nil {
+			return fmt.Errorf("open: %w", err)
+		}
+
+		st, err = c.statement(ctx, fStore, res.ID)
+		if err != nil {
+			return err
+		}
+
+		lines, err := fn(ctx, tx, f, st, now)
+		if err != nil {
+			return err
+		}
+
+		if err := fStore.AddLines(ctx, lines); err != nil {
+			return fmt.Errorf("addlines: %w", err)
+		}
+
+		st, err = c.statement(ctx, fStore, res.ID)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return Statement{}, err
+	}
+
+	return st, nil
+}
+
+// statement loads and totals the folio of the reservation through store.
+func (c *Core) statement(ctx context.Context, store folio.Store, reservationID uuid.UUID) (Statement, error) {
+	f, err := store.QueryByReservationID(ctx, reservationID)
+	if err != nil {
+		if errors.Is(err, folio.ErrNotFound) {
+			return Summarize(reservationID, nil), nil
+		}
+		return Statement{}, fmt.Errorf("querybyreservationid: %w", err)
+	}
+
+	lines, err := store.QueryLines(ctx, f.ID)
+	if err != nil {
+		return Statement{}, fmt.Errorf("querylines: %w", err)
+	}
+
+	return Summarize(reservationID, lines), nil
+}
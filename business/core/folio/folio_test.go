@@ -0,0 +1,156 @@
+package folio_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	foliocore "github.com/tcmhoang/sservices/business/core/folio"
+	"github.com/tcmhoang/sservices/business/data/store/folio"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func line(kind string, amount int) folio.Line {
+	return folio.Line{Kind: kind, Quantity: 1, UnitAmount: amount, Amount: amount}
+}
+
+func TestSummarize(t *testing.T) {
+	tt := []struct {
+		name  string
+		lines []folio.Line
+		exp   foliocore.Statement
+	}{
+		{
+			name: "empty folio",
+			exp:  foliocore.Statement{},
+		},
+		{
+			name: "charges only",
+			lines: []folio.Line{
+				line(folio.KindRoom, 100),
+				line(folio.KindRoom, 120),
+				line(folio.KindTax, 22),
+				line(folio.KindExtra, 15),
+			},
+			exp: foliocore.Statement{Charges: 257, Balance: 257},
+		},
+		{
+			name: "settled",
+			lines: []folio.Line{
+				line(folio.KindRoom, 100),
+				line(folio.KindFee, 10),
+				line(folio.KindPayment, -110),
+			},
+			exp: foliocore.Statement{Charges: 110, Payments: 110, Balance: 0},
+		},
+		{
+			name: "overpaid then refunded",
+			lines: []folio.Line{
+				line(folio.KindRoom, 100),
+				line(folio.KindPayment, -150),
+				line(folio.KindRefund, 50),
+			},
+			exp: foliocore.Statement{Charges: 100, Payments: 150, Refunds: 50, Balance: 0},
+		},
+		{
+			name: "credit owed to the guest",
+			lines: []folio.Line{
+				line(folio.KindRoom, 100),
+				line(folio.KindPayment, -130),
+			},
+			exp: foliocore.Statement{Charges: 100, Payments: 130, Balance: -30},
+		},
+	}
+
+	t.Log("Given the need to total folios.")
+	{
+		for testID, tst := range tt {
+			tf := func(t *testing.T) {
+				st := foliocore.Summarize(uuid.Nil, tst.lines)
+
+				if st.Charges != tst.exp.Charges || st.Payments != tst.exp.Payments || st.Refunds != tst.exp.Refunds || st.Balance != tst.exp.Balance {
+					t.Logf("\t\tTest %d:\tGot: %+v", testID, st)
+					t.Logf("\t\tTest %d:\tExp: %+v", testID, tst.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould total the folio.", tests.Failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould total the folio.", tests.Success, testID)
+			}
+
+			t.Run(tst.name, tf)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	night := date("2030-03-10")
+	lines := []folio.Line{
+		{Kind: folio.KindRoom, Description: "Room, night of 2030-03-10", Night: &night, Quantity: 1, UnitAmount: 150, Amount: 150},
+		{Kind: folio.KindExtra, Description: "Minibar water", Quantity: 2, UnitAmount: 3, Amount: 6, DateCreated: date("2030-03-11")},
+		{Kind: folio.KindPayment, Description: "Payment", Quantity: 1, UnitAmount: -100, Amount: -100, DateCreated: date("2030-03-11")},
+	}
+
+	inv := foliocore.Invoice{
+		Statement: foliocore.Summarize(uuid.Nil, lines),
+		Reservation: reservation.Reservation{
+			ID:       uuid.MustParse("0b9e2c54-5a1a-4b7e-9a3e-4a5c0b2f6d11"),
+			CheckIn:  night,
+			CheckOut: date("2030-03-11"),
+		},
+		Property: property.Property{
+			Name:     "Gopher Inn",
+			Address:  "2 Gopher Way",
+			City:     "Da Nang",
+			Country:  "VN",
+			Currency: "USD",
+		},
+	}
+
+	exp := `INVOICE
+Gopher Inn
+2 Gopher Way, Da Nang, VN
+
+Reservation  0b9e2c54-5a1a-4b7e-9a3e-4a5c0b2f6d11
+Stay         2030-03-10 to 2030-03-11
+Currency     USD
+
+DATE        DESCRIPTION                QTY  UNIT  AMOUNT
+2030-03-10  Room, night of 2030-03-10  1    150   150
+2030-03-11  Minibar water              2    3     6
+2030-03-11  Payment                    1    -100  -100
+
+Charges      156
+Payments     100
+Refunds      0
+Balance due  56
+`
+
+	t.Log("Given the need to print invoices.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen rendering a folio as plain text.", testID)
+		{
+			var b strings.Builder
+			if err := foliocore.Render(&b, inv); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to render the invoice : %s.", tests.Failed, testID, err)
+			}
+
+			if got := b.String(); got != exp {
+				t.Logf("\t\tTest %d:\tGot:\n%s", testID, got)
+				t.Logf("\t\tTest %d:\tExp:\n%s", testID, exp)
+				t.Fatalf("\t%s\tTest %d:\tShould itemize every line with the totals.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould itemize every line with the totals.", tests.Success, testID)
+		}
+	}
+}
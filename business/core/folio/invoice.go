@@ -0,0 +1,80 @@
+package folio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/sys/date"
+)
+
+// Invoice is a statement along with the stay and property it bills for.
+type Invoice struct {
+	Statement
+	Reservation reservation.Reservation
+	Property    property.Property
+}
+
+// Invoice gathers what is needed to print the folio of the reservation.
+func (c *Core) Invoice(ctx context.Context, reservationID uuid.UUID) (Invoice, error) {
+	res, err := c.Reservation.QueryByID(ctx, reservationID)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+	}
+
+	prop, err := c.property.QueryByID(ctx, res.PropertyID)
+	if err != nil {
+		return Invoice{}, fmt.Errorf("querybyid: propertyID[%s]: %w", res.PropertyID, err)
+	}
+
+	st, err := c.Statement(ctx, res.ID)
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	inv := Invoice{
+		Statement:   st,
+		Reservation: res,
+		Property:    prop,
+	}
+
+	return inv, nil
+}
+
+// Render writes the invoice to w as plain text, ready to print.
+func Render(w io.Writer, inv Invoice) error {
+	res := inv.Reservation
+	prop := inv.Property
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "INVOICE\n")
+	fmt.Fprintf(tw, "%s\n", prop.Name)
+	fmt.Fprintf(tw, "%s, %s, %s\n", prop.Address, prop.City, prop.Country)
+	fmt.Fprintf(tw, "\n")
+	fmt.Fprintf(tw, "Reservation\t%s\n", res.ID)
+	fmt.Fprintf(tw, "Stay\t%s to %s\n", res.CheckIn.Format(date.Layout), res.CheckOut.Format(date.Layout))
+	fmt.Fprintf(tw, "Currency\t%s\n", prop.Currency)
+	fmt.Fprintf(tw, "\n")
+
+	fmt.Fprintf(tw, "DATE\tDESCRIPTION\tQTY\tUNIT\tAMOUNT\n")
+	for _, l := range inv.Lines {
+		day := l.DateCreated
+		if l.Night != nil {
+			day = *l.Night
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", day.Format(date.Layout), l.Description, l.Quantity, l.UnitAmount, l.Amount)
+	}
+	fmt.Fprintf(tw, "\n")
+
+	fmt.Fprintf(tw, "Charges\t%d\n", inv.Charges)
+	fmt.Fprintf(tw, "Payments\t%d\n", inv.Payments)
+	fmt.Fprintf(tw, "Refunds\t%d\n", inv.Refunds)
+	fmt.Fprintf(tw, "Balance due\t%d\n", inv.Balance)
+
+	return tw.Flush()
+}
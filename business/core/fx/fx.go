@@ -0,0 +1,185 @@
+// Package fx provides the core business API for showing amounts in another
+// currency than the one they are charged in, from a table of rates loaded by
+// staff. Conversions are for display only, guests are always charged in the
+// currency of the property.
+package fx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/fxrate"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+// header is the first line of a rate table.
+var header = []string{"base", "quote", "rate", "as_of"}
+
+// Converter converts amounts from one currency to another at a single rate.
+// AsOf is when the rate was published.
+type Converter struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Rate string    `json:"rate"`
+	AsOf time.Time `json:"asOf"`
+	rate *big.Rat
+}
+
+// NewConverter converts at the rate. An inverse converter converts from the
+// quote currency of the rate back to its base currency.
+func NewConverter(r fxrate.Rate, inverse bool) (Converter, error) {
+	rate, ok := new(big.Rat).SetString(r.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return Converter{}, fmt.Errorf("invalid rate [%s] for %s/%s", r.Rate, r.Base, r.Quote)
+	}
+
+	cv := Converter{
+		From: r.Base,
+		To:   r.Quote,
+		Rate: r.Rate,
+		AsOf: r.AsOf,
+		rate: rate,
+	}
+
+	if inverse {
+		cv.From, cv.To = r.Quote, r.Base
+		cv.rate = new(big.Rat).Inv(rate)
+		cv.Rate = cv.rate.FloatString(10)
+	}
+
+	return cv, nil
+}
+
+// Convert converts m, which must be in the currency converted from.
+func (cv Converter) Convert(m money.Money) (money.Money, error) {
+	if m.Currency != cv.From {
+		return money.Money{}, &money.CurrencyError{Want: cv.From, Got: m.Currency}
+	}
+	return m.Convert(cv.To, cv.rate), nil
+}
+
+// ParseCSV reads a rate table: a header line naming the base, quote, rate and
+// as_of columns, then one rate per line. as_of is an RFC 3339 time or a date.
+func ParseCSV(r io.Reader) ([]fxrate.NewRate, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(header)
+	cr.TrimLeadingSpace = true
+
+	first, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	for i, name := range header {
+		if strings.ToLower(first[i]) != name {
+			return nil, fmt.Errorf("header must be %s", strings.Join(header, ","))
+		}
+	}
+
+	var nrs []fxrate.NewRate
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading rate: %w", err)
+		}
+
+		line, _ := cr.FieldPos(0)
+
+		asOf, err := parseTime(rec[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid as_of [%s]", line, rec[3])
+		}
+
+		nr := fxrate.NewRate{
+			Base:  strings.ToUpper(rec[0]),
+			Quote: strings.ToUpper(rec[1]),
+			Rate:  rec[2],
+			AsOf:  asOf,
+		}
+
+		if err := validation.Check(nr); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		nrs = append(nrs, nr)
+	}
+
+	return nrs, nil
+}
+
+type Core struct {
+	log   *zap.SugaredLogger
+	db    *sqlx.DB
+	Store fxrate.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:   log,
+		db:    db,
+		Store: *fxrate.NewStore(log, db),
+	}
+}
+
+// Load saves every rate of a table, or none of them.
+func (c *Core) Load(ctx context.Context, nrs []fxrate.NewRate) error {
+	tran := func(tx sqlx.ExtContext) error {
+		store := c.Store.Tran(tx)
+
+		for _, nr := range nrs {
+			if _, err := store.Save(ctx, nr); err != nil {
+				return fmt.Errorf("save: %s/%s: %w", nr.Base, nr.Quote, err)
+			}
+		}
+
+		return nil
+	}
+
+	return database.WithinTran(ctx, c.log, c.db, tran)
+}
+
+// Converter returns a converter at the latest rate from one currency to the
+// other. A pair only loaded the other way around is converted at the inverse
+// rate.
+func (c *Core) Converter(ctx context.Context, from string, to string) (Converter, error) {
+	now := time.Now()
+
+	if from == to {
+		return NewConverter(fxrate.Rate{Base: from, Quote: to, Rate: "1", AsOf: now}, false)
+	}
+
+	r, err := c.Store.QueryLatest(ctx, from, to, now)
+	if err == nil {
+		return NewConverter(r, false)
+	}
+	if !errors.Is(err, fxrate.ErrNotFound) {
+		return Converter{}, fmt.Errorf("querylatest: %w", err)
+	}
+
+	r, err = c.Store.QueryLatest(ctx, to, from, now)
+	if err != nil {
+		return Converter{}, fmt.Errorf("querylatest: %w", err)
+	}
+
+	return NewConverter(r, true)
+}
+
+// parseTime reads an RFC 3339 time or a date, taken as midnight UTC.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,101 @@
+package fx_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tcmhoang/sservices/business/core/fx"
+	"github.com/tcmhoang/sservices/business/data/store/fxrate"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/money"
+)
+
+func TestParseCSV(t *testing.T) {
+	table := []struct {
+		name  string
+		csv   string
+		rates int
+		err   bool
+	}{
+		{"rates", "base,quote,rate,as_of\nUSD,EUR,0.92,2026-10-01\nusd, vnd, 25000, 2026-10-01T12:00:00Z\n", 2, false},
+		{"header only", "base,quote,rate,as_of\n", 0, false},
+		{"empty", "", 0, true},
+		{"wrong header", "from,to,rate,as_of\nUSD,EUR,0.92,2026-10-01\n", 0, true},
+		{"missing column", "base,quote,rate,as_of\nUSD,EUR,0.92\n", 0, true},
+		{"bad rate", "base,quote,rate,as_of\nUSD,EUR,abc,2026-10-01\n", 0, true},
+		{"bad as_of", "base,quote,rate,as_of\nUSD,EUR,0.92,yesterday\n", 0, true},
+		{"unknown currency", "base,quote,rate,as_of\nUSD,XYZ,0.92,2026-10-01\n", 0, true},
+		{"same currency", "base,quote,rate,as_of\nUSD,USD,1,2026-10-01\n", 0, true},
+	}
+
+	t.Log("Given the need to load rate tables.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				nrs, err := fx.ParseCSV(strings.NewReader(tt.csv))
+
+				if tt.err {
+					if err == nil {
+						t.Fatalf("\t%s\tTest %d:\tShould reject the table.", tests.Failed, testID)
+					}
+					t.Logf("\t%s\tTest %d:\tShould reject the table : %s.", tests.Success, testID, err)
+					return
+				}
+
+				if err != nil || len(nrs) != tt.rates {
+					t.Fatalf("\t%s\tTest %d:\tShould read %d rates : %v %v.", tests.Failed, testID, tt.rates, nrs, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould read %d rates.", tests.Success, testID, tt.rates)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestConverter(t *testing.T) {
+	asOf := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	r := fxrate.Rate{Base: "USD", Quote: "EUR", Rate: "0.8", AsOf: asOf}
+
+	t.Log("Given the need to show amounts in other currencies.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen converting at a rate.", testID)
+		{
+			cv, err := fx.NewConverter(r, false)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to convert at the rate : %s.", tests.Failed, testID, err)
+			}
+
+			got, err := cv.Convert(money.New(1000, "USD"))
+			if err != nil || got != money.New(800, "EUR") {
+				t.Fatalf("\t%s\tTest %d:\tShould convert from the base currency : %v %v.", tests.Failed, testID, got, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould convert from the base currency.", tests.Success, testID)
+
+			var ce *money.CurrencyError
+			if _, err := cv.Convert(money.New(1000, "EUR")); !errors.As(err, &ce) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT convert from another currency : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT convert from another currency.", tests.Success, testID)
+
+			inv, err := fx.NewConverter(r, true)
+			if err != nil || inv.From != "EUR" || inv.To != "USD" || !inv.AsOf.Equal(asOf) {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to convert at the inverse rate : %+v %v.", tests.Failed, testID, inv, err)
+			}
+
+			got, err = inv.Convert(money.New(800, "EUR"))
+			if err != nil || got != money.New(1000, "USD") {
+				t.Fatalf("\t%s\tTest %d:\tShould convert back to the base currency : %v %v.", tests.Failed, testID, got, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould convert back to the base currency.", tests.Success, testID)
+
+			if _, err := fx.NewConverter(fxrate.Rate{Base: "USD", Quote: "EUR", Rate: "0"}, false); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT convert at a zero rate.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT convert at a zero rate.", tests.Success, testID)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Package guest provides the core business API for guest profiles and the
+// guests staying on reservations.
+package guest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/guest"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrTooManyGuests = errors.New("reservation already has as many guests as it was booked for")
+	ErrPrimaryTaken  = errors.New("reservation already has a primary guest")
+)
+
+type Core struct {
+	log         *zap.SugaredLogger
+	db          *sqlx.DB
+	Store       guest.Store
+	Reservation reservation.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:         log,
+		db:          db,
+		Store:       *guest.NewStore(log, db),
+		Reservation: *reservation.NewStore(log, db),
+	}
+}
+
+// AddOccupant puts a guest on a reservation. A reservation never holds more
+// guests than it was booked for, nor more than one primary guest. The
+// reservation row is locked so concurrent additions are checked one at a
+// time.
+func (c *Core) AddOccupant(ctx context.Context, reservationID uuid.UUID, no guest.NewOccupant) ([]guest.Occupant, error) {
+	if err := validation.Check(no); err != nil {
+		return nil, fmt.Errorf("validating data: %w", err)
+	}
+
+	var ocs []guest.Occupant
+
+	tran := func(tx sqlx.ExtContext) error {
+		resStore := c.Reservation.Tran(tx)
+		gStore := c.Store.Tran(tx)
+
+		res, err := resStore.QueryByIDForUpdate(ctx, reservationID)
+		if err != nil {
+			return fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+		}
+
+		if _, err := gStore.QueryByID(ctx, no.GuestID); err != nil {
+			return fmt.Errorf("querybyid: guestID[%s]: %w", no.GuestID, err)
+		}
+
+		ocs, err = gStore.QueryOccupants(ctx, res.ID)
+		if err != nil {
+			return fmt.Errorf("queryoccupants: %w", err)
+		}
+
+		for _, oc := range ocs {
+			switch {
+			case oc.ID == no.GuestID:
+				return guest.ErrAlreadyAttached
+			case oc.Primary && no.Primary:
+				return ErrPrimaryTaken
+			}
+		}
+
+		if len(ocs) >= res.Guests {
+			return ErrTooManyGuests
+		}
+
+		if err := gStore.AddOccupant(ctx, res.ID, no); err != nil {
+			return fmt.Errorf("addoccupant: %w", err)
+		}
+
+		ocs, err = gStore.QueryOccupants(ctx, res.ID)
+		if err != nil {
+			return fmt.Errorf("queryoccupants: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return nil, err
+	}
+
+	return ocs, nil
+}
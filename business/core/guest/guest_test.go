@@ -0,0 +1,188 @@
+package guest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	guestcore "github.com/tcmhoang/sservices/business/core/guest"
+	"github.com/tcmhoang/sservices/business/core/inventory"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/guest"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+var (
+	propertyID = uuid.MustParse("9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21")
+	userID     = uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+)
+
+func TestGuest(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := guestcore.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to work with guest profiles.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen putting guests on a reservation.", testID)
+		{
+			ctx := context.Background()
+
+			owner := userID
+			lead, err := core.Store.Create(ctx, guest.NewGuest{
+				UserID:         &owner,
+				Name:           "Gopher Lead",
+				Email:          "lead@example.com",
+				Nationality:    "VN",
+				DocumentType:   guest.DocumentPassport,
+				DocumentNumber: "B1234567",
+				Preferences:    []string{"high floor"},
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a guest : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a guest.", tests.Success, testID)
+
+			kid, err := core.Store.Create(ctx, guest.NewGuest{
+				UserID: &owner,
+				Name:   "Gopher Kid",
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a guest without a document : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a guest without a document.", tests.Success, testID)
+
+			gs, err := core.Store.Query(ctx, guest.Filter{DocumentNumber: "B1234567"}, 1, 10)
+			if err != nil || len(gs) != 1 || gs[0].ID != lead.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould find the guest by document number : %+v %v.", tests.Failed, testID, gs, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould find the guest by document number.", tests.Success, testID)
+
+			gs, err = core.Store.QueryByUserID(ctx, userID)
+			if err != nil || len(gs) != 2 {
+				t.Fatalf("\t%s\tTest %d:\tShould list the guests an account manages : %+v %v.", tests.Failed, testID, gs, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould list the guests an account manages.", tests.Success, testID)
+
+			res := book(t, stest, 2)
+
+			if _, err := core.AddOccupant(ctx, res.ID, guest.NewOccupant{GuestID: lead.ID, Primary: true}); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to add the primary guest : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to add the primary guest.", tests.Success, testID)
+
+			_, err = core.AddOccupant(ctx, res.ID, guest.NewOccupant{GuestID: lead.ID})
+			if !errors.Is(err, guest.ErrAlreadyAttached) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to add the same guest twice : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to add the same guest twice.", tests.Success, testID)
+
+			_, err = core.AddOccupant(ctx, res.ID, guest.NewOccupant{GuestID: kid.ID, Primary: true})
+			if !errors.Is(err, guestcore.ErrPrimaryTaken) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to add a second primary guest : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to add a second primary guest.", tests.Success, testID)
+
+			ocs, err := core.AddOccupant(ctx, res.ID, guest.NewOccupant{GuestID: kid.ID})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to add a second guest : %s.", tests.Failed, testID, err)
+			}
+			if len(ocs) != 2 || ocs[0].ID != lead.ID || !ocs[0].Primary {
+				t.Fatalf("\t%s\tTest %d:\tShould list the primary guest first : %+v.", tests.Failed, testID, ocs)
+			}
+			t.Logf("\t%s\tTest %d:\tShould list the primary guest first.", tests.Success, testID)
+
+			extra, err := core.Store.Create(ctx, guest.NewGuest{Name: "Gopher Friend"})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a guest without an account : %s.", tests.Failed, testID, err)
+			}
+
+			_, err = core.AddOccupant(ctx, res.ID, guest.NewOccupant{GuestID: extra.ID})
+			if !errors.Is(err, guestcore.ErrTooManyGuests) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to add more guests than booked : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to add more guests than booked.", tests.Success, testID)
+
+			if err := core.Store.Delete(ctx, kid); !errors.Is(err, guest.ErrInUse) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to delete a guest on a reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to delete a guest on a reservation.", tests.Success, testID)
+
+			if err := core.Store.RemoveOccupant(ctx, res.ID, kid.ID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to take a guest off the reservation : %s.", tests.Failed, testID, err)
+			}
+
+			if err := core.Store.Delete(ctx, kid); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete a guest once off every reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to delete a guest once off every reservation.", tests.Success, testID)
+		}
+	}
+}
+
+// book reserves a fresh single room type for the seeded user.
+func book(t *testing.T, stest *tests.State, guests int) reservation.Reservation {
+	ctx := context.Background()
+	inv := inventory.NewCore(stest.Log, stest.DB)
+
+	rt, err := inv.CreateRoomType(ctx, roomtype.NewRoomType{
+		PropertyID: propertyID,
+		Name:       "Family Room",
+		Capacity:   guests,
+		BedConfig:  "2 QUEEN",
+		BaseRate:   150,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a room type : %s", err)
+	}
+
+	if _, err := inv.CreateRoom(ctx, room.NewRoom{RoomTypeID: rt.ID, Number: "FR1", Floor: 2}); err != nil {
+		t.Fatalf("Should be able to create a room : %s", err)
+	}
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+	res, err := rescore.NewCore(stest.Log, stest.DB, payment.NewFake()).Book(ctx, reservation.NewReservation{
+		RoomTypeID: rt.ID,
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 2),
+		Guests:     guests,
+		UserID:     userID,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to book the room : %s", err)
+	}
+
+	return res
+}
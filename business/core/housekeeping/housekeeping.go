@@ -0,0 +1,162 @@
+// Package housekeeping provides the core business API for the cleaning status
+// of rooms and the staff who clean them.
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/housekeeping"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var ErrNotHousekeeper = errors.New("assignee is not housekeeping staff")
+
+// role is the user role of housekeeping staff.
+const role = "HOUSEKEEPING"
+
+// TransitionError is returned when a room is asked to move to a housekeeping
+// status that cannot be reached from its current one.
+type TransitionError struct {
+	RoomID uuid.UUID
+	From   string
+	To     string
+}
+
+func (te *TransitionError) Error() string {
+	return fmt.Sprintf("room %s cannot move from %s to %s", te.RoomID, te.From, te.To)
+}
+
+// transitions lists the housekeeping statuses a room may move to from each
+// status. Only clean rooms are inspected, and rooms out of order go back to
+// dirty once they are fixed.
+var transitions = map[string][]string{
+	room.HousekeepingDirty:      {room.HousekeepingCleaning, room.HousekeepingClean, room.HousekeepingOutOfOrder},
+	room.HousekeepingCleaning:   {room.HousekeepingDirty, room.HousekeepingClean, room.HousekeepingOutOfOrder},
+	room.HousekeepingClean:      {room.HousekeepingDirty, room.HousekeepingInspected, room.HousekeepingOutOfOrder},
+	room.HousekeepingInspected:  {room.HousekeepingDirty, room.HousekeepingOutOfOrder},
+	room.HousekeepingOutOfOrder: {room.HousekeepingDirty},
+}
+
+// CanTransition reports whether a room in housekeeping status from may move
+// to status to.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+type Core struct {
+	log   *zap.SugaredLogger
+	db    *sqlx.DB
+	Store housekeeping.Store
+	room  room.Store
+	user  user.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:   log,
+		db:    db,
+		Store: *housekeeping.NewStore(log, db),
+		room:  *room.NewStore(log, db),
+		user:  *user.NewStore(log, db),
+	}
+}
+
+// SetStatus moves the room to another housekeeping status. A room cleaned
+// completes its task of the day.
+func (c *Core) SetStatus(ctx context.Context, roomID uuid.UUID, uh room.UpdateHousekeeping, now time.Time) (room.Room, error) {
+	if err := validation.Check(uh); err != nil {
+		return room.Room{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var rm room.Room
+
+	tran := func(tx sqlx.ExtContext) error {
+		rmStore := c.room.Tran(tx)
+		hkStore := c.Store.Tran(tx)
+
+		var err error
+		rm, err = rmStore.QueryByIDForUpdate(ctx, roomID)
+		if err != nil {
+			return fmt.Errorf("querybyid: roomID[%s]: %w", roomID, err)
+		}
+
+		if !CanTransition(rm.Housekeeping, uh.Status) {
+			return &TransitionError{RoomID: rm.ID, From: rm.Housekeeping, To: uh.Status}
+		}
+
+		rm.Housekeeping = uh.Status
+		rm.DateUpdated = now
+
+		if err := rmStore.UpdateState(ctx, rm); err != nil {
+			return fmt.Errorf("updatestate: %w", err)
+		}
+
+		if uh.Status == room.HousekeepingClean {
+			if err := hkStore.Complete(ctx, rm.ID, now, now); err != nil {
+				return fmt.Errorf("complete: %w", err)
+			}
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return room.Room{}, err
+	}
+
+	return rm, nil
+}
+
+// Assign gives a room to clean on a day to a member of the housekeeping
+// staff.
+func (c *Core) Assign(ctx context.Context, nt housekeeping.NewTask) (housekeeping.Task, error) {
+	usr, err := c.user.QueryByID(ctx, nt.AssigneeID)
+	if err != nil {
+		return housekeeping.Task{}, fmt.Errorf("querybyid: assigneeID[%s]: %w", nt.AssigneeID, err)
+	}
+
+	if !hasRole(usr, role) {
+		return housekeeping.Task{}, ErrNotHousekeeper
+	}
+
+	t, err := c.Store.Create(ctx, nt)
+	if err != nil {
+		return housekeeping.Task{}, fmt.Errorf("create: %w", err)
+	}
+
+	return t, nil
+}
+
+// Board returns the housekeeping board of the property for the day.
+func (c *Core) Board(ctx context.Context, propertyID uuid.UUID, day time.Time) ([]housekeeping.BoardRoom, error) {
+	rs, err := c.Store.Board(ctx, propertyID, day)
+	if err != nil {
+		return nil, fmt.Errorf("board: %w", err)
+	}
+
+	return rs, nil
+}
+
+func hasRole(usr user.User, want string) bool {
+	for _, r := range usr.Roles {
+		if strings.EqualFold(r, want) {
+			return true
+		}
+	}
+	return false
+}
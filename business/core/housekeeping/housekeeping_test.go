@@ -0,0 +1,46 @@
+package housekeeping_test
+
+import (
+	"testing"
+
+	"github.com/tcmhoang/sservices/business/core/housekeeping"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+func TestCanTransition(t *testing.T) {
+	table := []struct {
+		name string
+		from string
+		to   string
+		exp  bool
+	}{
+		{"dirty to cleaning", room.HousekeepingDirty, room.HousekeepingCleaning, true},
+		{"cleaning to clean", room.HousekeepingCleaning, room.HousekeepingClean, true},
+		{"clean to inspected", room.HousekeepingClean, room.HousekeepingInspected, true},
+		{"inspected to dirty", room.HousekeepingInspected, room.HousekeepingDirty, true},
+		{"clean to out of order", room.HousekeepingClean, room.HousekeepingOutOfOrder, true},
+		{"out of order to dirty", room.HousekeepingOutOfOrder, room.HousekeepingDirty, true},
+		{"dirty to inspected", room.HousekeepingDirty, room.HousekeepingInspected, false},
+		{"cleaning to inspected", room.HousekeepingCleaning, room.HousekeepingInspected, false},
+		{"out of order to clean", room.HousekeepingOutOfOrder, room.HousekeepingClean, false},
+		{"clean to clean", room.HousekeepingClean, room.HousekeepingClean, false},
+		{"unknown status", "", room.HousekeepingClean, false},
+	}
+
+	t.Log("Given the need to move rooms through housekeeping statuses.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got := housekeeping.CanTransition(tt.from, tt.to)
+
+				if got != tt.exp {
+					t.Fatalf("\t%s\tTest %d:\tShould report %t moving from %s to %s.", tests.Failed, testID, tt.exp, tt.from, tt.to)
+				}
+				t.Logf("\t%s\tTest %d:\tShould report %t moving from %s to %s.", tests.Success, testID, tt.exp, tt.from, tt.to)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Package inventory provides the core business API for managing properties,
+// their room types and the physical rooms behind them.
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"go.uber.org/zap"
+)
+
+var ErrPropertyMismatch = errors.New("room type belongs to another property")
+
+type Core struct {
+	log      *zap.SugaredLogger
+	Property property.Store
+	RoomType roomtype.Store
+	Room     room.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:      log,
+		Property: *property.NewStore(log, db),
+		RoomType: *roomtype.NewStore(log, db),
+		Room:     *room.NewStore(log, db),
+	}
+}
+
+// CreateRoomType adds a room type to an existing property.
+func (c *Core) CreateRoomType(ctx context.Context, nrt roomtype.NewRoomType) (roomtype.RoomType, error) {
+	if _, err := c.Property.QueryByID(ctx, nrt.PropertyID); err != nil {
+		return roomtype.RoomType{}, fmt.Errorf("querybyid: propertyID[%s]: %w", nrt.PropertyID, err)
+	}
+
+	rt, err := c.RoomType.Create(ctx, nrt)
+	if err != nil {
+		return roomtype.RoomType{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rt, nil
+}
+
+// CreateRoom adds a physical room to the property its room type belongs to.
+func (c *Core) CreateRoom(ctx context.Context, nr room.NewRoom) (room.Room, error) {
+	rt, err := c.RoomType.QueryByID(ctx, nr.RoomTypeID)
+	if err != nil {
+		return room.Room{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", nr.RoomTypeID, err)
+	}
+	nr.PropertyID = rt.PropertyID
+
+	rm, err := c.Room.Create(ctx, nr)
+	if err != nil {
+		return room.Room{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rm, nil
+}
+
+// UpdateRoom updates a room, making sure a new room type stays within the
+// same property.
+func (c *Core) UpdateRoom(ctx context.Context, rm room.Room, ur room.UpdateRoom) (room.Room, error) {
+	if ur.RoomTypeID != nil {
+		rt, err := c.RoomType.QueryByID(ctx, *ur.RoomTypeID)
+		if err != nil {
+			return room.Room{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", *ur.RoomTypeID, err)
+		}
+		if rt.PropertyID != rm.PropertyID {
+			return room.Room{}, ErrPropertyMismatch
+		}
+	}
+
+	rm, err := c.Room.Update(ctx, rm, ur)
+	if err != nil {
+		return room.Room{}, fmt.Errorf("update: %w", err)
+	}
+
+	return rm, nil
+}
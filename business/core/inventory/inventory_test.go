@@ -0,0 +1,144 @@
+package inventory_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/tcmhoang/sservices/business/core/inventory"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestInventory(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := inventory.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to work with hotel inventory.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen building a property with its rooms.", testID)
+		{
+			ctx := context.Background()
+
+			_, err := core.CreateRoomType(ctx, roomtype.NewRoomType{
+				PropertyID: uuid.New(),
+				Name:       "Orphan",
+				Capacity:   2,
+				BedConfig:  "1 KING",
+				BaseRate:   100,
+			})
+			if !errors.Is(err, property.ErrNotFound) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to add a room type to an unknown property : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to add a room type to an unknown property.", tests.Success, testID)
+
+			prop, err := core.Property.Create(ctx, property.NewProperty{
+				Name:     "Gopher Inn",
+				Address:  "2 Gopher Way",
+				City:     "Da Nang",
+				Country:  "VN",
+				TimeZone: "Asia/Ho_Chi_Minh",
+				Currency: "USD",
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a property : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a property.", tests.Success, testID)
+
+			rt, err := core.CreateRoomType(ctx, roomtype.NewRoomType{
+				PropertyID: prop.ID,
+				Name:       "Family Suite",
+				Capacity:   4,
+				BedConfig:  "1 KING 2 TWIN",
+				Amenities:  []string{"wifi", "kitchenette"},
+				BaseRate:   250,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a room type : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a room type.", tests.Success, testID)
+
+			saved, err := core.RoomType.QueryByID(ctx, rt.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the room type : %s.", tests.Failed, testID, err)
+			}
+
+			if diff := cmp.Diff(rt.Amenities, saved.Amenities); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the same amenities. Diff:\n%s", tests.Failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same amenities.", tests.Success, testID)
+
+			rm, err := core.CreateRoom(ctx, room.NewRoom{
+				RoomTypeID: rt.ID,
+				Number:     "301",
+				Floor:      3,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a room.", tests.Success, testID)
+
+			if rm.PropertyID != prop.ID || rm.Status != room.StatusAvailable {
+				t.Fatalf("\t%s\tTest %d:\tShould place an available room in the property of its room type : %+v.", tests.Failed, testID, rm)
+			}
+			t.Logf("\t%s\tTest %d:\tShould place an available room in the property of its room type.", tests.Success, testID)
+
+			_, err = core.CreateRoom(ctx, room.NewRoom{
+				RoomTypeID: rt.ID,
+				Number:     "301",
+				Floor:      3,
+			})
+			if !errors.Is(err, room.ErrUniqueNumber) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to reuse a room number : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to reuse a room number.", tests.Success, testID)
+
+			seeded := uuid.MustParse("3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01")
+			_, err = core.UpdateRoom(ctx, rm, room.UpdateRoom{RoomTypeID: &seeded})
+			if !errors.Is(err, inventory.ErrPropertyMismatch) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to move a room to another property's room type : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to move a room to another property's room type.", tests.Success, testID)
+
+			if err := core.Property.Delete(ctx, prop); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete the property : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := core.Room.QueryByID(ctx, rm.ID); !errors.Is(err, room.ErrNotFound) {
+				t.Fatalf("\t%s\tTest %d:\tShould cascade the delete to rooms : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould cascade the delete to rooms.", tests.Success, testID)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package maintenance provides the core business API for taking rooms out of
+// order for a while, such as for repairs.
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/availability"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+// Conflicts is what a new out-of-order period clashes with: the stays already
+// assigned to the room over its nights, which need another room, and how many
+// more rooms of the room type are sold on the busiest of its nights than are
+// left in inventory.
+type Conflicts struct {
+	Reservations []reservation.Reservation `json:"reservations"`
+	Overbooked   int                       `json:"overbooked"`
+}
+
+// Closure is an out-of-order period along with what it clashes with when it
+// was made.
+type Closure struct {
+	outoforder.Period
+	Conflicts Conflicts `json:"conflicts"`
+}
+
+type Core struct {
+	log          *zap.SugaredLogger
+	db           *sqlx.DB
+	Store        outoforder.Store
+	room         room.Store
+	roomType     roomtype.Store
+	reservation  reservation.Store
+	availability availability.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:          log,
+		db:           db,
+		Store:        *outoforder.NewStore(log, db),
+		room:         *room.NewStore(log, db),
+		roomType:     *roomtype.NewStore(log, db),
+		reservation:  *reservation.NewStore(log, db),
+		availability: *availability.NewStore(log, db),
+	}
+}
+
+// Create takes the room out of order for the period and reports what it
+// clashes with. The period is made even when it clashes, since a broken room
+// cannot be sold either way. The room type stays locked meanwhile, so bookings
+// are checked against the period one at a time.
+func (c *Core) Create(ctx context.Context, np outoforder.NewPeriod) (Closure, error) {
+	np.Starts = date.Truncate(np.Starts)
+	np.Ends = date.Truncate(np.Ends)
+
+	if err := validation.Check(np); err != nil {
+		return Closure{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var cl Closure
+
+	tran := func(tx sqlx.ExtContext) error {
+		rmStore := c.room.Tran(tx)
+		rtStore := c.roomType.Tran(tx)
+		oooStore := c.Store.Tran(tx)
+		resStore := c.reservation.Tran(tx)
+		avlStore := c.availability.Tran(tx)
+
+		rm, err := rmStore.QueryByID(ctx, np.RoomID)
+		if err != nil {
+			return fmt.Errorf("querybyid: roomID[%s]: %w", np.RoomID, err)
+		}
+
+		if _, err := rtStore.QueryByIDForUpdate(ctx, rm.RoomTypeID); err != nil {
+			return fmt.Errorf("querybyid: roomTypeID[%s]: %w", rm.RoomTypeID, err)
+		}
+
+		np.PropertyID = rm.PropertyID
+		cl.Period, err = oooStore.Create(ctx, np)
+		if err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		booked, err := resStore.QueryAssigned(ctx, rm.PropertyID, np.Starts, np.Ends)
+		if err != nil {
+			return fmt.Errorf("queryassigned: %w", err)
+		}
+
+		cl.Conflicts.Reservations = []reservation.Reservation{}
+		for _, b := range booked {
+			if *b.RoomID == rm.ID {
+				cl.Conflicts.Reservations = append(cl.Conflicts.Reservations, b)
+			}
+		}
+
+		avl, err := avlStore.QueryByRoomTypeID(ctx, rm.RoomTypeID, np.Starts, np.Ends)
+		if err != nil {
+			return fmt.Errorf("querybyroomtypeid: %w", err)
+		}
+
+		if avl.Available < 0 {
+			cl.Conflicts.Overbooked = -avl.Available
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return Closure{}, err
+	}
+
+	return cl, nil
+}
+
+// Query returns the out-of-order periods at the property covering any night
+// between from and to.
+func (c *Core) Query(ctx context.Context, propertyID uuid.UUID, from time.Time, to time.Time) ([]outoforder.Period, error) {
+	ps, err := c.Store.QueryByPropertyID(ctx, propertyID, date.Truncate(from), date.Truncate(to))
+	if err != nil {
+		return nil, fmt.Errorf("querybypropertyid: %w", err)
+	}
+
+	return ps, nil
+}
@@ -0,0 +1,79 @@
+package pricing
+
+import (
+	"time"
+
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+)
+
+// CheckInHour is the local hour from which guests may arrive. Cancellation
+// deadlines are counted back from it in the property's time zone.
+const CheckInHour = 14
+
+// DefaultPolicy applies to stays booked without a rate plan: free
+// cancellation until arrival, then the first night is charged.
+var DefaultPolicy = rateplan.RatePlan{
+	Refundable:  true,
+	PenaltyType: rateplan.PenaltyFirstNight,
+}
+
+// Cancellation is what cancelling a stay costs the guest and what is left to
+// give back to them.
+type Cancellation struct {
+	Deadline time.Time `json:"deadline"`
+	Penalty  int       `json:"penalty"`
+	Refund   int       `json:"refund"`
+}
+
+// Penalty works out what cancelling a stay at time now costs under the
+// cancellation policy of rp. The stay starts on the checkIn date, local to
+// loc, and rates holds the price of each of its nights in order.
+//
+// A stay cancelled partway through, without the guest ever checking in, is
+// charged at least the nights whose arrival time has already passed, since
+// their room was kept for the guest.
+func Penalty(rp rateplan.RatePlan, checkIn time.Time, rates []int, loc *time.Location, now time.Time) Cancellation {
+	var total int
+	for _, rate := range rates {
+		total += rate
+	}
+
+	y, m, d := checkIn.Date()
+	arrival := time.Date(y, m, d, CheckInHour, 0, 0, 0, loc)
+
+	cxl := Cancellation{
+		Deadline: arrival.Add(-time.Duration(rp.FreeCancelHours) * time.Hour),
+	}
+
+	switch {
+	case !rp.Refundable:
+		cxl.Penalty = total
+
+	case now.Before(cxl.Deadline):
+		cxl.Penalty = 0
+
+	case rp.PenaltyType == rateplan.PenaltyPercent:
+		cxl.Penalty = (total*rp.PenaltyPercent + 50) / 100
+
+	case len(rates) > 0:
+		cxl.Penalty = rates[0]
+	}
+
+	var elapsed int
+	for i, rate := range rates {
+		if now.Before(arrival.AddDate(0, 0, i)) {
+			break
+		}
+		elapsed += rate
+	}
+	if cxl.Penalty < elapsed {
+		cxl.Penalty = elapsed
+	}
+
+	if cxl.Penalty > total {
+		cxl.Penalty = total
+	}
+	cxl.Refund = total - cxl.Penalty
+
+	return cxl
+}
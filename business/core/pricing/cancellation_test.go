@@ -0,0 +1,266 @@
+package pricing_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+func location(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+func instant(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestPenalty(t *testing.T) {
+	hcm := location("Asia/Ho_Chi_Minh")
+	nyc := location("America/New_York")
+
+	flexible := rateplan.RatePlan{
+		Refundable:      true,
+		FreeCancelHours: 24,
+		PenaltyType:     rateplan.PenaltyFirstNight,
+	}
+
+	half := rateplan.RatePlan{
+		Refundable:      true,
+		FreeCancelHours: 48,
+		PenaltyType:     rateplan.PenaltyPercent,
+		PenaltyPercent:  50,
+	}
+
+	// Arrival at the Ho Chi Minh property is 2024-03-10 14:00 +07:00, which
+	// puts the 24 hour deadline at 2024-03-09 07:00 UTC.
+	checkIn := date("2024-03-10")
+
+	table := []struct {
+		name    string
+		rp      rateplan.RatePlan
+		loc     *time.Location
+		rates   []int
+		now     time.Time
+		penalty int
+		refund  int
+	}{
+		{
+			name:    "well before the deadline",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-01T00:00:00Z"),
+			penalty: 0,
+			refund:  400,
+		},
+		{
+			name:    "a second before the deadline",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-09T06:59:59Z"),
+			penalty: 0,
+			refund:  400,
+		},
+		{
+			name:    "at the deadline",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-09T07:00:00Z"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "same day cancel",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-10T09:00:00+07:00"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "same day cancel without notice period",
+			rp:      rateplan.RatePlan{Refundable: true, PenaltyType: rateplan.PenaltyFirstNight},
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-10T13:59:00+07:00"),
+			penalty: 0,
+			refund:  400,
+		},
+		{
+			name:    "after arrival",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-10T20:00:00+07:00"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "same instant is free in a later time zone",
+			rp:      flexible,
+			loc:     nyc,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-09T17:30:00Z"),
+			penalty: 0,
+			refund:  400,
+		},
+		{
+			name:    "same instant is late in an earlier time zone",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-09T17:30:00Z"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "deadline counts real hours across a DST change",
+			rp:      flexible,
+			loc:     nyc,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-09T13:30:00-05:00"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "one night stay",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100},
+			now:     instant("2024-03-10T09:00:00+07:00"),
+			penalty: 100,
+			refund:  0,
+		},
+		{
+			name:    "partial stay charges the nights gone by",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-11T20:00:00+07:00"),
+			penalty: 250,
+			refund:  150,
+		},
+		{
+			name:    "partial stay before the next arrival time",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-11T13:59:59+07:00"),
+			penalty: 100,
+			refund:  300,
+		},
+		{
+			name:    "partial stay under the percentage",
+			rp:      half,
+			loc:     hcm,
+			rates:   []int{100, 100, 100, 100},
+			now:     instant("2024-03-11T15:00:00+07:00"),
+			penalty: 200,
+			refund:  200,
+		},
+		{
+			name:    "partial stay over the percentage",
+			rp:      half,
+			loc:     hcm,
+			rates:   []int{100, 100, 100, 100},
+			now:     instant("2024-03-12T15:00:00+07:00"),
+			penalty: 300,
+			refund:  100,
+		},
+		{
+			name:    "partial stay at the next local arrival time",
+			rp:      flexible,
+			loc:     nyc,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-03-11T14:00:00-04:00"),
+			penalty: 250,
+			refund:  150,
+		},
+		{
+			name:    "first night priced above the rest",
+			rp:      flexible,
+			loc:     hcm,
+			rates:   []int{250, 90},
+			now:     instant("2024-03-10T09:00:00+07:00"),
+			penalty: 250,
+			refund:  90,
+		},
+		{
+			name:    "percentage rounds half up",
+			rp:      half,
+			loc:     hcm,
+			rates:   []int{200, 201},
+			now:     instant("2024-03-09T00:00:00Z"),
+			penalty: 201,
+			refund:  200,
+		},
+		{
+			name:    "percentage before its own deadline",
+			rp:      half,
+			loc:     hcm,
+			rates:   []int{200, 201},
+			now:     instant("2024-03-08T06:59:59Z"),
+			penalty: 0,
+			refund:  401,
+		},
+		{
+			name:    "full percentage",
+			rp:      rateplan.RatePlan{Refundable: true, PenaltyType: rateplan.PenaltyPercent, PenaltyPercent: 100},
+			loc:     hcm,
+			rates:   []int{100, 150},
+			now:     instant("2024-03-10T15:00:00+07:00"),
+			penalty: 250,
+			refund:  0,
+		},
+		{
+			name:    "non-refundable",
+			rp:      rateplan.RatePlan{Refundable: false, FreeCancelHours: 24},
+			loc:     hcm,
+			rates:   []int{100, 150, 150},
+			now:     instant("2024-01-01T00:00:00Z"),
+			penalty: 400,
+			refund:  0,
+		},
+		{
+			name:    "default policy",
+			rp:      pricing.DefaultPolicy,
+			loc:     hcm,
+			rates:   []int{120, 120},
+			now:     instant("2024-03-10T14:00:00+07:00"),
+			penalty: 120,
+			refund:  120,
+		},
+	}
+
+	t.Log("Given the need to charge for cancellations.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				cxl := pricing.Penalty(tt.rp, checkIn, tt.rates, tt.loc, tt.now)
+
+				if cxl.Penalty != tt.penalty || cxl.Refund != tt.refund {
+					t.Logf("\t\tTest %d:\tGot: penalty %d refund %d", testID, cxl.Penalty, cxl.Refund)
+					t.Logf("\t\tTest %d:\tExp: penalty %d refund %d", testID, tt.penalty, tt.refund)
+					t.Fatalf("\t%s\tTest %d:\tShould charge the policy's penalty (deadline %v).", tests.Failed, testID, cxl.Deadline)
+				}
+				t.Logf("\t%s\tTest %d:\tShould charge the policy's penalty.", tests.Success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
@@ -0,0 +1,394 @@
+// Package pricing provides the core business API for pricing stays on rate
+// plans.
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/core/fx"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/block"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidStay     = errors.New("check-out must be after check-in")
+	ErrMinStay         = errors.New("stay is shorter than the minimum stay")
+	ErrClosedToArrival = errors.New("rate plan is closed to arrival on the check-in date")
+)
+
+// Set of sources a nightly rate can come from, in order of precedence.
+const (
+	SourceOverride = "OVERRIDE"
+	SourceWeekday  = "WEEKDAY"
+	SourceBase     = "BASE"
+)
+
+// Night is the price of a single night of a stay.
+type Night struct {
+	Date   time.Time `json:"date"`
+	Rate   int       `json:"rate"`
+	Source string    `json:"source"`
+}
+
+// Quote is the price of a stay on a rate plan, night by night. Total is what
+// the room costs, TotalWithTaxes adds the taxes and fees charged on top of it
+// when the quote was made for a number of guests. Amounts are in the currency
+// of the property, Converted shows them in another one when asked for.
+type Quote struct {
+	RatePlanID        uuid.UUID    `json:"ratePlanID"`
+	RoomTypeID        uuid.UUID    `json:"roomTypeID"`
+	Refundable        bool         `json:"refundable"`
+	BreakfastIncluded bool         `json:"breakfastIncluded"`
+	CheckIn           time.Time    `json:"checkIn"`
+	CheckOut          time.Time    `json:"checkOut"`
+	Nights            []Night      `json:"nights"`
+	Total             int          `json:"total"`
+	Taxes             []tax.Charge `json:"taxes"`
+	TotalWithTaxes    int          `json:"totalWithTaxes"`
+	Currency          string       `json:"currency"`
+	Converted         *Converted   `json:"converted,omitempty"`
+}
+
+// Converted is a quote shown in another currency, at the rate the converter
+// used, for display only.
+type Converted struct {
+	fx.Converter
+	Nights         []int `json:"nights"`
+	Total          int   `json:"total"`
+	TotalWithTaxes int   `json:"totalWithTaxes"`
+}
+
+// RoomTypeQuote prices a stay on a room type both without a rate plan and on
+// each of its rate plans that is open for the stay.
+type RoomTypeQuote struct {
+	Base      Quote   `json:"base"`
+	RatePlans []Quote `json:"ratePlans"`
+}
+
+type Core struct {
+	log      *zap.SugaredLogger
+	RatePlan rateplan.Store
+	roomType roomtype.Store
+	property property.Store
+	tax      *tax.Core
+	fx       *fx.Core
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:      log,
+		RatePlan: *rateplan.NewStore(log, db),
+		roomType: *roomtype.NewStore(log, db),
+		property: *property.NewStore(log, db),
+		tax:      tax.NewCore(log, db),
+		fx:       fx.NewCore(log, db),
+	}
+}
+
+// CreateRatePlan adds a rate plan to an existing room type.
+func (c *Core) CreateRatePlan(ctx context.Context, nrp rateplan.NewRatePlan) (rateplan.RatePlan, error) {
+	if _, err := c.roomType.QueryByID(ctx, nrp.RoomTypeID); err != nil {
+		return rateplan.RatePlan{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", nrp.RoomTypeID, err)
+	}
+
+	rp, err := c.RatePlan.Create(ctx, nrp)
+	if err != nil {
+		return rateplan.RatePlan{}, fmt.Errorf("create: %w", err)
+	}
+
+	return rp, nil
+}
+
+// Quote prices a stay of guests on the rate plan, taxes and fees included.
+func (c *Core) Quote(ctx context.Context, ratePlanID uuid.UUID, checkIn time.Time, checkOut time.Time, guests int) (Quote, error) {
+	q, err := quote(ctx, c.RatePlan, ratePlanID, checkIn, checkOut)
+	if err != nil {
+		return Quote{}, err
+	}
+
+	rt, err := c.roomType.QueryByID(ctx, q.RoomTypeID)
+	if err != nil {
+		return Quote{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", q.RoomTypeID, err)
+	}
+
+	prop, err := c.property.QueryByID(ctx, rt.PropertyID)
+	if err != nil {
+		return Quote{}, fmt.Errorf("querybyid: propertyID[%s]: %w", rt.PropertyID, err)
+	}
+
+	return c.withTaxes(ctx, q, prop, guests)
+}
+
+// QuoteRoomType prices a stay of guests on the room type the same way
+// booking it would, taxes and fees included. Rate plans whose minimum stay or
+// closed-to-arrival restriction rules the stay out are left out.
+func (c *Core) QuoteRoomType(ctx context.Context, roomTypeID uuid.UUID, checkIn time.Time, checkOut time.Time, guests int) (RoomTypeQuote, error) {
+	rt, err := c.roomType.QueryByID(ctx, roomTypeID)
+	if err != nil {
+		return RoomTypeQuote{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", roomTypeID, err)
+	}
+
+	prop, err := c.property.QueryByID(ctx, rt.PropertyID)
+	if err != nil {
+		return RoomTypeQuote{}, fmt.Errorf("querybyid: propertyID[%s]: %w", rt.PropertyID, err)
+	}
+
+	base, err := Price(BasePlan(rt), nil, nil, checkIn, checkOut)
+	if err != nil {
+		return RoomTypeQuote{}, fmt.Errorf("price: %w", err)
+	}
+
+	base, err = c.withTaxes(ctx, base, prop, guests)
+	if err != nil {
+		return RoomTypeQuote{}, err
+	}
+
+	rps, err := c.RatePlan.QueryByRoomTypeID(ctx, rt.ID)
+	if err != nil {
+		return RoomTypeQuote{}, fmt.Errorf("querybyroomtypeid: %w", err)
+	}
+
+	rtq := RoomTypeQuote{
+		Base:      base,
+		RatePlans: make([]Quote, 0, len(rps)),
+	}
+
+	for _, rp := range rps {
+		q, err := quote(ctx, c.RatePlan, rp.ID, checkIn, checkOut)
+		if err != nil {
+			if errors.Is(err, ErrMinStay) || errors.Is(err, ErrClosedToArrival) {
+				continue
+			}
+			return RoomTypeQuote{}, fmt.Errorf("quote: ratePlanID[%s]: %w", rp.ID, err)
+		}
+
+		q, err = c.withTaxes(ctx, q, prop, guests)
+		if err != nil {
+			return RoomTypeQuote{}, err
+		}
+
+		rtq.RatePlans = append(rtq.RatePlans, q)
+	}
+
+	return rtq, nil
+}
+
+// QuoteTx prices a stay reading the rate plan inside tx, for callers that
+// book the stay in the same transaction.
+func (c *Core) QuoteTx(ctx context.Context, tx sqlx.ExtContext, ratePlanID uuid.UUID, checkIn time.Time, checkOut time.Time) (Quote, error) {
+	return quote(ctx, c.RatePlan.Tran(tx), ratePlanID, checkIn, checkOut)
+}
+
+// Convert shows the quote in the currency as well.
+func (c *Core) Convert(ctx context.Context, q Quote, currency string) (Quote, error) {
+	cv, err := c.fx.Converter(ctx, q.Currency, currency)
+	if err != nil {
+		return Quote{}, fmt.Errorf("converter: %w", err)
+	}
+
+	return Convert(q, cv)
+}
+
+// ConvertRoomType shows every quote for the room type in the currency as
+// well.
+func (c *Core) ConvertRoomType(ctx context.Context, rtq RoomTypeQuote, currency string) (RoomTypeQuote, error) {
+	cv, err := c.fx.Converter(ctx, rtq.Base.Currency, currency)
+	if err != nil {
+		return RoomTypeQuote{}, fmt.Errorf("converter: %w", err)
+	}
+
+	if rtq.Base, err = Convert(rtq.Base, cv); err != nil {
+		return RoomTypeQuote{}, err
+	}
+
+	for i := range rtq.RatePlans {
+		if rtq.RatePlans[i], err = Convert(rtq.RatePlans[i], cv); err != nil {
+			return RoomTypeQuote{}, err
+		}
+	}
+
+	return rtq, nil
+}
+
+// Convert shows the quote in the currency cv converts to. Every amount is
+// converted on its own, so the converted nights may not add up to the
+// converted total by a minor unit.
+func Convert(q Quote, cv fx.Converter) (Quote, error) {
+	convert := func(amount int) (int, error) {
+		m, err := cv.Convert(money.New(amount, q.Currency))
+		return m.Amount, err
+	}
+
+	cq := Converted{
+		Converter: cv,
+		Nights:    make([]int, len(q.Nights)),
+	}
+
+	var err error
+	for i, n := range q.Nights {
+		if cq.Nights[i], err = convert(n.Rate); err != nil {
+			return Quote{}, err
+		}
+	}
+	if cq.Total, err = convert(q.Total); err != nil {
+		return Quote{}, err
+	}
+	if cq.TotalWithTaxes, err = convert(q.TotalWithTaxes); err != nil {
+		return Quote{}, err
+	}
+
+	q.Converted = &cq
+
+	return q, nil
+}
+
+// withTaxes adds the taxes and fees of the property on a stay of guests to
+// the quote, which is in the currency of the property.
+func (c *Core) withTaxes(ctx context.Context, q Quote, prop property.Property, guests int) (Quote, error) {
+	rates := make([]int, len(q.Nights))
+	for i, n := range q.Nights {
+		rates[i] = n.Rate
+	}
+
+	cs, err := c.tax.Stay(ctx, prop.ID, rates, guests)
+	if err != nil {
+		return Quote{}, fmt.Errorf("stay: %w", err)
+	}
+
+	q.Currency = prop.Currency
+	q.Taxes = cs
+	q.TotalWithTaxes = q.Total + tax.Total(cs)
+
+	return q, nil
+}
+
+func quote(ctx context.Context, store rateplan.Store, ratePlanID uuid.UUID, checkIn time.Time, checkOut time.Time) (Quote, error) {
+	checkIn = date.Truncate(checkIn)
+	checkOut = date.Truncate(checkOut)
+
+	rp, err := store.QueryByID(ctx, ratePlanID)
+	if err != nil {
+		return Quote{}, fmt.Errorf("querybyid: ratePlanID[%s]: %w", ratePlanID, err)
+	}
+
+	wrs, err := store.QueryWeekdayRates(ctx, rp.ID)
+	if err != nil {
+		return Quote{}, fmt.Errorf("queryweekdayrates: %w", err)
+	}
+
+	ovs, err := store.QueryOverrides(ctx, rp.ID, checkIn, checkOut)
+	if err != nil {
+		return Quote{}, fmt.Errorf("queryoverrides: %w", err)
+	}
+
+	return Price(rp, wrs, ovs, checkIn, checkOut)
+}
+
+// BasePlan is the plan stays booked without a rate plan are priced on: the
+// room type's base rate every night, with no restrictions.
+func BasePlan(rt roomtype.RoomType) rateplan.RatePlan {
+	return rateplan.RatePlan{
+		RoomTypeID: rt.ID,
+		BaseRate:   rt.BaseRate,
+		MinStay:    1,
+	}
+}
+
+// BlockPlan is the plan rooms picked up from a block are priced on: the
+// block's negotiated rate every night, with no restrictions.
+func BlockPlan(b block.Block) rateplan.RatePlan {
+	return rateplan.RatePlan{
+		RoomTypeID: b.RoomTypeID,
+		BaseRate:   b.Rate,
+		MinStay:    1,
+	}
+}
+
+// Price works out the nightly breakdown and total of a stay on a rate plan,
+// before taxes.
+// Each night costs its calendar override if one is set, otherwise the rate
+// for its day of the week, otherwise the plan's base rate. The minimum stay
+// and closed-to-arrival restrictions are those of the arrival night.
+func Price(rp rateplan.RatePlan, wrs []rateplan.WeekdayRate, ovs []rateplan.Override, checkIn time.Time, checkOut time.Time) (Quote, error) {
+	checkIn = date.Truncate(checkIn)
+	checkOut = date.Truncate(checkOut)
+
+	if !checkOut.After(checkIn) {
+		return Quote{}, ErrInvalidStay
+	}
+
+	weekdays := make(map[time.Weekday]int, len(wrs))
+	for _, wr := range wrs {
+		weekdays[time.Weekday(wr.Weekday)] = wr.Rate
+	}
+
+	calendar := make(map[time.Time]rateplan.Override, len(ovs))
+	for _, o := range ovs {
+		calendar[date.Truncate(o.Night)] = o
+	}
+
+	nights := 0
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		nights++
+	}
+
+	minStay := rp.MinStay
+	arrival, ok := calendar[checkIn]
+	if ok {
+		if arrival.ClosedToArrival {
+			return Quote{}, ErrClosedToArrival
+		}
+		if arrival.MinStay != nil {
+			minStay = *arrival.MinStay
+		}
+	}
+	if nights < minStay {
+		return Quote{}, fmt.Errorf("%w: %d nights required", ErrMinStay, minStay)
+	}
+
+	q := Quote{
+		RatePlanID:        rp.ID,
+		RoomTypeID:        rp.RoomTypeID,
+		Refundable:        rp.Refundable,
+		BreakfastIncluded: rp.BreakfastIncluded,
+		CheckIn:           checkIn,
+		CheckOut:          checkOut,
+		Nights:            make([]Night, 0, nights),
+		Taxes:             []tax.Charge{},
+	}
+
+	for d := checkIn; d.Before(checkOut); d = d.AddDate(0, 0, 1) {
+		n := Night{
+			Date:   d,
+			Rate:   rp.BaseRate,
+			Source: SourceBase,
+		}
+
+		if rate, ok := weekdays[d.Weekday()]; ok {
+			n.Rate = rate
+			n.Source = SourceWeekday
+		}
+
+		if o, ok := calendar[d]; ok && o.Rate != nil {
+			n.Rate = *o.Rate
+			n.Source = SourceOverride
+		}
+
+		q.Nights = append(q.Nights, n)
+		q.Total += n.Rate
+	}
+	q.TotalWithTaxes = q.Total
+
+	return q, nil
+}
@@ -0,0 +1,200 @@
+package pricing_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPrice(t *testing.T) {
+	rp := rateplan.RatePlan{
+		ID:         uuid.New(),
+		RoomTypeID: uuid.New(),
+		BaseRate:   100,
+		MinStay:    1,
+	}
+
+	// 2024-03-01 is a Friday.
+	weekend := []rateplan.WeekdayRate{
+		{RatePlanID: rp.ID, Weekday: int(time.Friday), Rate: 150},
+		{RatePlanID: rp.ID, Weekday: int(time.Saturday), Rate: 160},
+	}
+
+	table := []struct {
+		name     string
+		wrs      []rateplan.WeekdayRate
+		ovs      []rateplan.Override
+		minStay  int
+		checkIn  time.Time
+		checkOut time.Time
+		rates    []int
+		sources  []string
+		total    int
+		err      error
+	}{
+		{
+			name:     "base",
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-06"),
+			rates:    []int{100, 100},
+			sources:  []string{pricing.SourceBase, pricing.SourceBase},
+			total:    200,
+		},
+		{
+			name:     "weekday",
+			wrs:      weekend,
+			checkIn:  date("2024-02-29"),
+			checkOut: date("2024-03-03"),
+			rates:    []int{100, 150, 160},
+			sources:  []string{pricing.SourceBase, pricing.SourceWeekday, pricing.SourceWeekday},
+			total:    410,
+		},
+		{
+			name: "override beats weekday",
+			wrs:  weekend,
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-02"), Rate: intPtr(300)},
+			},
+			checkIn:  date("2024-03-01"),
+			checkOut: date("2024-03-03"),
+			rates:    []int{150, 300},
+			sources:  []string{pricing.SourceWeekday, pricing.SourceOverride},
+			total:    450,
+		},
+		{
+			name: "override without rate",
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-05"), MinStay: intPtr(1)},
+			},
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-06"),
+			rates:    []int{100, 100},
+			sources:  []string{pricing.SourceBase, pricing.SourceBase},
+			total:    200,
+		},
+		{
+			name:     "time of day ignored",
+			checkIn:  date("2024-03-04").Add(23 * time.Hour),
+			checkOut: date("2024-03-05").Add(time.Hour),
+			rates:    []int{100},
+			sources:  []string{pricing.SourceBase},
+			total:    100,
+		},
+		{
+			name:     "plan min stay",
+			minStay:  3,
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-06"),
+			err:      pricing.ErrMinStay,
+		},
+		{
+			name: "arrival min stay",
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-04"), MinStay: intPtr(2)},
+			},
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-05"),
+			err:      pricing.ErrMinStay,
+		},
+		{
+			name: "arrival min stay relaxes plan",
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-04"), MinStay: intPtr(1)},
+			},
+			minStay:  3,
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-05"),
+			rates:    []int{100},
+			sources:  []string{pricing.SourceBase},
+			total:    100,
+		},
+		{
+			name: "closed to arrival",
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-04"), ClosedToArrival: true},
+			},
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-06"),
+			err:      pricing.ErrClosedToArrival,
+		},
+		{
+			name: "closed to arrival mid stay",
+			ovs: []rateplan.Override{
+				{Night: date("2024-03-05"), ClosedToArrival: true},
+			},
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-06"),
+			rates:    []int{100, 100},
+			sources:  []string{pricing.SourceBase, pricing.SourceBase},
+			total:    200,
+		},
+		{
+			name:     "empty stay",
+			checkIn:  date("2024-03-04"),
+			checkOut: date("2024-03-04"),
+			err:      pricing.ErrInvalidStay,
+		},
+	}
+
+	t.Log("Given the need to price stays on a rate plan.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				plan := rp
+				if tt.minStay != 0 {
+					plan.MinStay = tt.minStay
+				}
+
+				q, err := pricing.Price(plan, tt.wrs, tt.ovs, tt.checkIn, tt.checkOut)
+				if tt.err != nil {
+					if !errors.Is(err, tt.err) {
+						t.Fatalf("\t%s\tTest %d:\tShould fail with %q : %v.", tests.Failed, testID, tt.err, err)
+					}
+					t.Logf("\t%s\tTest %d:\tShould fail with %q.", tests.Success, testID, tt.err)
+					return
+				}
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to price the stay : %s.", tests.Failed, testID, err)
+				}
+
+				var rates []int
+				var sources []string
+				for _, n := range q.Nights {
+					rates = append(rates, n.Rate)
+					sources = append(sources, n.Source)
+				}
+
+				if diff := cmp.Diff(tt.rates, rates); diff != "" {
+					t.Fatalf("\t%s\tTest %d:\tShould get the nightly rates, diff:\n%s", tests.Failed, testID, diff)
+				}
+				if diff := cmp.Diff(tt.sources, sources); diff != "" {
+					t.Fatalf("\t%s\tTest %d:\tShould get the rate sources, diff:\n%s", tests.Failed, testID, diff)
+				}
+				if q.Total != tt.total {
+					t.Fatalf("\t%s\tTest %d:\tShould total %d : got %d.", tests.Failed, testID, tt.total, q.Total)
+				}
+				t.Logf("\t%s\tTest %d:\tShould price the stay.", tests.Success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
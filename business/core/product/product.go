@@ -0,0 +1,20 @@
+// Package product provides the core business API for the product catalog.
+package product
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"go.uber.org/zap"
+)
+
+type Core struct {
+	log   *zap.SugaredLogger
+	Store product.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log,
+		*product.NewStore(log, db),
+	}
+}
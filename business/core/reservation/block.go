@@ -0,0 +1,228 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	"github.com/tcmhoang/sservices/business/data/store/block"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+var (
+	ErrBlockReleased = errors.New("block has been released")
+	ErrBlockFull     = errors.New("every room of the block is picked up")
+	ErrOutsideBlock  = errors.New("stay is outside the dates of the block")
+)
+
+// CreateBlock sets rooms of a room type aside for a group. The room type row
+// is locked like a booking, so the block only takes rooms that are free for
+// every night it covers.
+func (c *Core) CreateBlock(ctx context.Context, nb block.NewBlock) (block.Block, error) {
+	nb.CheckIn = date.Truncate(nb.CheckIn)
+	nb.CheckOut = date.Truncate(nb.CheckOut)
+
+	if err := validation.Check(nb); err != nil {
+		return block.Block{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var b block.Block
+
+	tran := func(tx sqlx.ExtContext) error {
+		rtStore := c.roomType.Tran(tx)
+		avlStore := c.Availability.Tran(tx)
+
+		rt, err := rtStore.QueryByIDForUpdate(ctx, nb.RoomTypeID)
+		if err != nil {
+			return fmt.Errorf("querybyid: roomTypeID[%s]: %w", nb.RoomTypeID, err)
+		}
+
+		avl, err := avlStore.QueryByRoomTypeID(ctx, rt.ID, nb.CheckIn, nb.CheckOut)
+		if err != nil {
+			return fmt.Errorf("querybyroomtypeid: %w", err)
+		}
+
+		if avl.Available < nb.Rooms {
+			return ErrUnavailable
+		}
+
+		now := time.Now()
+		b = block.Block{
+			ID:          uuid.New(),
+			PropertyID:  rt.PropertyID,
+			RoomTypeID:  rt.ID,
+			Name:        nb.Name,
+			Code:        nb.Code,
+			CheckIn:     nb.CheckIn,
+			CheckOut:    nb.CheckOut,
+			Rooms:       nb.Rooms,
+			Rate:        nb.Rate,
+			Cutoff:      nb.Cutoff,
+			Status:      block.StatusActive,
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		bStore := c.Block.Tran(tx)
+		if err := bStore.Create(ctx, b); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return block.Block{}, err
+	}
+
+	return b, nil
+}
+
+// PickUp books a room out of the block with the attendee's code at the
+// block's negotiated rate. Pickups come out of the rooms the block already
+// keeps aside, so they never compete with general inventory, but a block
+// never hands out more rooms on a night than it holds. Like any booking the
+// reservation starts out HELD.
+func (c *Core) PickUp(ctx context.Context, p block.Pickup) (reservation.Reservation, error) {
+	p.CheckIn = date.Truncate(p.CheckIn)
+	p.CheckOut = date.Truncate(p.CheckOut)
+
+	if err := validation.Check(p); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		bStore := c.Block.Tran(tx)
+		rtStore := c.roomType.Tran(tx)
+
+		b, err := bStore.QueryByCodeForUpdate(ctx, p.Code)
+		if err != nil {
+			return fmt.Errorf("querybycode: %w", err)
+		}
+
+		now := time.Now()
+		if b.Status != block.StatusActive || !now.Before(b.Cutoff) {
+			return ErrBlockReleased
+		}
+
+		if p.CheckIn.Before(b.CheckIn) || p.CheckOut.After(b.CheckOut) {
+			return ErrOutsideBlock
+		}
+
+		rt, err := rtStore.QueryByID(ctx, b.RoomTypeID)
+		if err != nil {
+			return fmt.Errorf("querybyid: roomTypeID[%s]: %w", b.RoomTypeID, err)
+		}
+
+		if p.Guests > rt.Capacity {
+			return ErrCapacity
+		}
+
+		picked, err := bStore.PickedUp(ctx, b.ID, p.CheckIn, p.CheckOut, now)
+		if err != nil {
+			return fmt.Errorf("pickedup: %w", err)
+		}
+
+		if picked >= b.Rooms {
+			return ErrBlockFull
+		}
+
+		q, err := pricing.Price(pricing.BlockPlan(b), nil, nil, p.CheckIn, p.CheckOut)
+		if err != nil {
+			return fmt.Errorf("price: %w", err)
+		}
+
+		nr := reservation.NewReservation{
+			RoomTypeID: b.RoomTypeID,
+			CheckIn:    p.CheckIn,
+			CheckOut:   p.CheckOut,
+			Guests:     p.Guests,
+			UserID:     p.UserID,
+		}
+
+		res, err = c.create(ctx, tx, rt, nr, q, &b.ID)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// ReleaseBlock gives the rooms of a block nobody picked up back to general
+// inventory ahead of its cutoff and offers them to the waitlist.
+func (c *Core) ReleaseBlock(ctx context.Context, blockID uuid.UUID) (block.Block, error) {
+	var b block.Block
+
+	tran := func(tx sqlx.ExtContext) error {
+		bStore := c.Block.Tran(tx)
+
+		var err error
+		b, err = bStore.QueryByIDForUpdate(ctx, blockID)
+		if err != nil {
+			return fmt.Errorf("querybyid: blockID[%s]: %w", blockID, err)
+		}
+
+		if b.Status != block.StatusActive {
+			return ErrBlockReleased
+		}
+
+		now := time.Now()
+		b.Status = block.StatusReleased
+		b.DateUpdated = now
+
+		if err := bStore.Update(ctx, b); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		return c.offer(ctx, tx, b.RoomTypeID, b.CheckIn, b.CheckOut, now)
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return block.Block{}, err
+	}
+
+	return b, nil
+}
+
+// ReleaseBlocks releases every block whose cutoff passed by now and returns
+// them. The rooms nobody picked up go back to general inventory and are
+// offered to the waitlist.
+func (c *Core) ReleaseBlocks(ctx context.Context, now time.Time) ([]block.Block, error) {
+	var bs []block.Block
+
+	tran := func(tx sqlx.ExtContext) error {
+		bStore := c.Block.Tran(tx)
+
+		var err error
+		bs, err = bStore.ReleaseDue(ctx, now)
+		if err != nil {
+			return fmt.Errorf("releasedue: %w", err)
+		}
+
+		for _, b := range bs {
+			if err := c.offer(ctx, tx, b.RoomTypeID, b.CheckIn, b.CheckOut, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return nil, err
+	}
+
+	return bs, nil
+}
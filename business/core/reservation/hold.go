@@ -0,0 +1,248 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/hold"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/waitlist"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+var ErrHoldInactive = errors.New("hold is no longer active")
+
+// PlaceHold keeps one room of a room type aside for the stay while the guest
+// checks out. The hold counts against availability until it is confirmed,
+// released or its time runs out.
+func (c *Core) PlaceHold(ctx context.Context, nh hold.NewHold) (hold.Hold, error) {
+	nh.CheckIn = date.Truncate(nh.CheckIn)
+	nh.CheckOut = date.Truncate(nh.CheckOut)
+
+	if err := validation.Check(nh); err != nil {
+		return hold.Hold{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if nh.Minutes == 0 {
+		nh.Minutes = hold.DefaultMinutes
+	}
+
+	var h hold.Hold
+
+	tran := func(tx sqlx.ExtContext) error {
+		rt, _, err := c.claim(ctx, tx, nh.RoomTypeID, nh.RatePlanID, nh.CheckIn, nh.CheckOut, nh.Guests)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		h = hold.Hold{
+			ID:          uuid.New(),
+			PropertyID:  rt.PropertyID,
+			RoomTypeID:  rt.ID,
+			UserID:      nh.UserID,
+			CheckIn:     nh.CheckIn,
+			CheckOut:    nh.CheckOut,
+			Guests:      nh.Guests,
+			RatePlanID:  nh.RatePlanID,
+			Status:      hold.StatusActive,
+			ExpiresAt:   now.Add(time.Duration(nh.Minutes) * time.Minute),
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		hStore := c.Hold.Tran(tx)
+		if err := hStore.Create(ctx, h); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return hold.Hold{}, err
+	}
+
+	return h, nil
+}
+
+// ExtendHold pushes the expiry of an active hold to the given number of
+// minutes from now.
+func (c *Core) ExtendHold(ctx context.Context, holdID uuid.UUID, eh hold.ExtendHold) (hold.Hold, error) {
+	if err := validation.Check(eh); err != nil {
+		return hold.Hold{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var h hold.Hold
+
+	tran := func(tx sqlx.ExtContext) error {
+		hStore := c.Hold.Tran(tx)
+
+		var err error
+		h, err = hStore.QueryByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return fmt.Errorf("querybyid: holdID[%s]: %w", holdID, err)
+		}
+
+		now := time.Now()
+		if !h.Active(now) {
+			return ErrHoldInactive
+		}
+
+		h.ExpiresAt = now.Add(time.Duration(eh.Minutes) * time.Minute)
+		h.DateUpdated = now
+
+		if err := hStore.Update(ctx, h); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return hold.Hold{}, err
+	}
+
+	return h, nil
+}
+
+// ReleaseHold gives the held room back before the hold expires and offers it
+// to the waitlist. Releasing a hold offered from the waitlist declines the
+// offer.
+func (c *Core) ReleaseHold(ctx context.Context, holdID uuid.UUID) error {
+	tran := func(tx sqlx.ExtContext) error {
+		hStore := c.Hold.Tran(tx)
+
+		h, err := hStore.QueryByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return fmt.Errorf("querybyid: holdID[%s]: %w", holdID, err)
+		}
+
+		if h.Status != hold.StatusActive {
+			return ErrHoldInactive
+		}
+
+		now := time.Now()
+		h.Status = hold.StatusReleased
+		h.DateUpdated = now
+
+		if err := hStore.Update(ctx, h); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		if err := c.settleOffer(ctx, tx, h.ID, waitlist.StatusCancelled, now); err != nil {
+			return err
+		}
+
+		return c.offer(ctx, tx, h.RoomTypeID, h.CheckIn, h.CheckOut, now)
+	}
+
+	return database.WithinTran(ctx, c.log, c.db, tran)
+}
+
+// ConfirmHold turns an active hold into a confirmed reservation. The hold
+// stops counting against availability in the same transaction that books
+// its room, so the guest never competes with their own hold. Like Confirm,
+// the total is authorized on the payment source.
+func (c *Core) ConfirmHold(ctx context.Context, holdID uuid.UUID, userID uuid.UUID, cr reservation.ConfirmReservation) (reservation.Reservation, error) {
+	if err := validation.Check(cr); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		hStore := c.Hold.Tran(tx)
+
+		h, err := hStore.QueryByIDForUpdate(ctx, holdID)
+		if err != nil {
+			return fmt.Errorf("querybyid: holdID[%s]: %w", holdID, err)
+		}
+
+		now := time.Now()
+		if !h.Active(now) {
+			return ErrHoldInactive
+		}
+
+		h.Status = hold.StatusConverted
+		h.DateUpdated = now
+
+		if err := hStore.Update(ctx, h); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		if err := c.settleOffer(ctx, tx, h.ID, waitlist.StatusFulfilled, now); err != nil {
+			return err
+		}
+
+		nr := reservation.NewReservation{
+			RoomTypeID: h.RoomTypeID,
+			CheckIn:    h.CheckIn,
+			CheckOut:   h.CheckOut,
+			Guests:     h.Guests,
+			RatePlanID: h.RatePlanID,
+			UserID:     h.UserID,
+		}
+
+		res, err = c.book(ctx, tx, nr)
+		if err != nil {
+			return err
+		}
+
+		res, err = c.move(ctx, tx, res.ID, userID, reservation.StatusConfirmed, nil)
+		if err != nil {
+			return err
+		}
+
+		return c.authorize(ctx, tx, res, cr.PaymentSource, userID)
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// ExpireHolds marks every hold whose time ran out by now as expired and
+// returns them. Waitlist offers that lapsed with them expire too, and the
+// freed rooms are offered further down the waitlist.
+func (c *Core) ExpireHolds(ctx context.Context, now time.Time) ([]hold.Hold, error) {
+	var hs []hold.Hold
+
+	tran := func(tx sqlx.ExtContext) error {
+		hStore := c.Hold.Tran(tx)
+
+		var err error
+		hs, err = hStore.Expire(ctx, now)
+		if err != nil {
+			return fmt.Errorf("expire: %w", err)
+		}
+
+		for _, h := range hs {
+			if err := c.settleOffer(ctx, tx, h.ID, waitlist.StatusExpired, now); err != nil {
+				return err
+			}
+		}
+
+		for _, h := range hs {
+			if err := c.offer(ctx, tx, h.RoomTypeID, h.CheckIn, h.CheckOut, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return nil, err
+	}
+
+	return hs, nil
+}
@@ -0,0 +1,141 @@
+package reservation
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+)
+
+// authorize sets the total of the reservation, taxes and fees included, aside
+// on the payment source inside tx. A stay that costs nothing is not sent to
+// the gateway. Gateway declines surface as a *payment.DeclineError and roll
+// the confirmation back.
+func (c *Core) authorize(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, source string, userID uuid.UUID) error {
+	cs, err := c.tax.Reservation(ctx, tx, res)
+	if err != nil {
+		return fmt.Errorf("reservation taxes: %w", err)
+	}
+
+	amount := res.Total + tax.Total(cs)
+	if amount == 0 {
+		return nil
+	}
+
+	propStore := c.property.Tran(tx)
+
+	prop, err := propStore.QueryByID(ctx, res.PropertyID)
+	if err != nil {
+		return fmt.Errorf("querybyid: propertyID[%s]: %w", res.PropertyID, err)
+	}
+
+	key := authorizeKey(res.ID, source)
+	pr, err := c.payment.Authorize(ctx, key, source, amount, prop.Currency)
+	if err != nil {
+		return fmt.Errorf("authorize: %w", err)
+	}
+
+	_, err = c.record(ctx, tx, res.ID, transaction.KindAuthorize, pr, prop.Currency, key, userID)
+	return err
+}
+
+// capture collects amount of what was authorized for the reservation inside
+// tx, or the whole authorization when amount is below zero. It returns false
+// when nothing was authorized for the reservation.
+func (c *Core) capture(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, amount int, userID uuid.UUID) (transaction.Transaction, bool, error) {
+	tStore := c.Transaction.Tran(tx)
+
+	auth, err := tStore.QueryLatest(ctx, res.ID, transaction.KindAuthorize)
+	if err != nil {
+		if errors.Is(err, transaction.ErrNotFound) {
+			return transaction.Transaction{}, false, nil
+		}
+		return transaction.Transaction{}, false, fmt.Errorf("querylatest: %w", err)
+	}
+
+	if amount < 0 {
+		amount = auth.Amount
+	}
+
+	key := idempotencyKey(res.ID, transaction.KindCapture)
+	pr, err := c.payment.Capture(ctx, key, auth.GatewayRef, amount)
+	if err != nil {
+		return transaction.Transaction{}, false, fmt.Errorf("capture: %w", err)
+	}
+
+	t, err := c.record(ctx, tx, res.ID, transaction.KindCapture, pr, auth.Currency, key, userID)
+	if err != nil {
+		return transaction.Transaction{}, false, err
+	}
+
+	return t, true, nil
+}
+
+// void releases what was authorized for the reservation inside tx, if
+// anything.
+func (c *Core) void(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, userID uuid.UUID) error {
+	tStore := c.Transaction.Tran(tx)
+
+	auth, err := tStore.QueryLatest(ctx, res.ID, transaction.KindAuthorize)
+	if err != nil {
+		if errors.Is(err, transaction.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("querylatest: %w", err)
+	}
+
+	key := idempotencyKey(res.ID, transaction.KindVoid)
+	pr, err := c.payment.Void(ctx, key, auth.GatewayRef)
+	if err != nil {
+		return fmt.Errorf("void: %w", err)
+	}
+
+	_, err = c.record(ctx, tx, res.ID, transaction.KindVoid, pr, auth.Currency, key, userID)
+	return err
+}
+
+// record stores what the gateway answered for the reservation inside tx.
+func (c *Core) record(ctx context.Context, tx sqlx.ExtContext, reservationID uuid.UUID, kind string, pr payment.Result, currency string, key string, userID uuid.UUID) (transaction.Transaction, error) {
+	t := transaction.Transaction{
+		ID:             uuid.New(),
+		ReservationID:  &reservationID,
+		Kind:           kind,
+		Amount:         pr.Amount,
+		Currency:       currency,
+		GatewayRef:     pr.ID,
+		IdempotencyKey: key,
+		UserID:         userID,
+		DateCreated:    time.Now(),
+	}
+
+	tStore := c.Transaction.Tran(tx)
+	if err := tStore.Create(ctx, t); err != nil {
+		return transaction.Transaction{}, fmt.Errorf("create transaction: %w", err)
+	}
+
+	return t, nil
+}
+
+// idempotencyKey names a gateway request for the reservation. A reservation
+// is captured or voided at most once, so retrying the same step always sends
+// the same key.
+func idempotencyKey(reservationID uuid.UUID, kind string) string {
+	return fmt.Sprintf("reservation/%s/%s", reservationID, kind)
+}
+
+// authorizeKey names an authorization of the reservation on source. The
+// gateway replays its answer to a key it already saw, declines included, so
+// the key changes with the source to let the guest retry with another card.
+// The source is hashed to keep card tokens out of the stored key.
+func authorizeKey(reservationID uuid.UUID, source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return fmt.Sprintf("%s/%x", idempotencyKey(reservationID, transaction.KindAuthorize), sum[:8])
+}
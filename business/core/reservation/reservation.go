@@ -0,0 +1,612 @@
+// Package reservation provides the core business API for booking rooms.
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	foliocore "github.com/tcmhoang/sservices/business/core/folio"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/availability"
+	"github.com/tcmhoang/sservices/business/data/store/block"
+	"github.com/tcmhoang/sservices/business/data/store/hold"
+	"github.com/tcmhoang/sservices/business/data/store/outbox"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/data/store/waitlist"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrUnavailable      = errors.New("room type is not available for the requested dates")
+	ErrCapacity         = errors.New("number of guests exceeds the room type capacity")
+	ErrRatePlanMismatch = errors.New("rate plan does not belong to the room type")
+	ErrExpired          = errors.New("reservation hold has expired")
+	ErrWrongRoom        = errors.New("room does not belong to the room type of the reservation")
+	ErrRoomUnavailable  = errors.New("room is occupied or out of service")
+	ErrRoomNotReady     = errors.New("room is not clean")
+	ErrRoomTaken        = errors.New("room is assigned to another stay on those nights")
+	ErrNoRoomReady      = errors.New("no clean room of the room type is free")
+)
+
+// TransitionError is returned when a reservation is asked to move to a status
+// that cannot be reached from its current one.
+type TransitionError struct {
+	ReservationID uuid.UUID
+	From          string
+	To            string
+}
+
+func (te *TransitionError) Error() string {
+	return fmt.Sprintf("reservation %s cannot move from %s to %s", te.ReservationID, te.From, te.To)
+}
+
+// transitions lists the statuses a reservation may move to from each status.
+// CHECKED_OUT, CANCELLED, NO_SHOW and EXPIRED are terminal.
+var transitions = map[string][]string{
+	reservation.StatusHeld:      {reservation.StatusConfirmed, reservation.StatusCancelled, reservation.StatusExpired},
+	reservation.StatusConfirmed: {reservation.StatusCheckedIn, reservation.StatusCancelled, reservation.StatusNoShow},
+	reservation.StatusCheckedIn: {reservation.StatusCheckedOut},
+}
+
+// CanTransition reports whether a reservation in status from may move to
+// status to.
+func CanTransition(from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Offer is a room type with free rooms for a stay along with what booking it
+// costs, with or without a rate plan.
+type Offer struct {
+	availability.Availability
+	pricing.RoomTypeQuote
+}
+
+type Core struct {
+	log          *zap.SugaredLogger
+	db           *sqlx.DB
+	Store        reservation.Store
+	roomType     roomtype.Store
+	Availability availability.Store
+	Hold         hold.Store
+	Waitlist     waitlist.Store
+	Outbox       outbox.Store
+	Block        block.Store
+	Transaction  transaction.Store
+	property     property.Store
+	room         room.Store
+	outOfOrder   outoforder.Store
+	pricing      *pricing.Core
+	folio        *foliocore.Core
+	tax          *tax.Core
+	payment      payment.Gateway
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB, gw payment.Gateway) *Core {
+	return &Core{
+		log:          log,
+		db:           db,
+		Store:        *reservation.NewStore(log, db),
+		roomType:     *roomtype.NewStore(log, db),
+		Availability: *availability.NewStore(log, db),
+		Hold:         *hold.NewStore(log, db),
+		Waitlist:     *waitlist.NewStore(log, db),
+		Outbox:       *outbox.NewStore(log, db),
+		Block:        *block.NewStore(log, db),
+		Transaction:  *transaction.NewStore(log, db),
+		property:     *property.NewStore(log, db),
+		room:         *room.NewStore(log, db),
+		outOfOrder:   *outoforder.NewStore(log, db),
+		pricing:      pricing.NewCore(log, db),
+		folio:        foliocore.NewCore(log, db),
+		tax:          tax.NewCore(log, db),
+		payment:      gw,
+	}
+}
+
+// Book reserves one room of a room type for the stay. The room type row is
+// locked for the duration of the transaction, so concurrent bookings for the
+// same room type are checked against availability one at a time and can
+// never overbook it. New reservations start out HELD and expire unless
+// confirmed within reservation.HoldMinutes.
+func (c *Core) Book(ctx context.Context, nr reservation.NewReservation) (reservation.Reservation, error) {
+	nr.CheckIn = date.Truncate(nr.CheckIn)
+	nr.CheckOut = date.Truncate(nr.CheckOut)
+
+	if err := validation.Check(nr); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		var err error
+		res, err = c.book(ctx, tx, nr)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// Search finds the room types with free rooms for the stay and prices each of
+// them through the pricing core, so the prices match what Book charges. The
+// prices include the taxes and fees charged on the number of guests, and are
+// also shown in the currency of the filter if it has one.
+func (c *Core) Search(ctx context.Context, filter availability.Filter, pageNumber int, rowsPerPage int) ([]Offer, error) {
+	filter.CheckIn = date.Truncate(filter.CheckIn)
+	filter.CheckOut = date.Truncate(filter.CheckOut)
+
+	avls, err := c.Availability.Query(ctx, filter, pageNumber, rowsPerPage)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	offers := make([]Offer, len(avls))
+	for i, avl := range avls {
+		rtq, err := c.pricing.QuoteRoomType(ctx, avl.RoomTypeID, filter.CheckIn, filter.CheckOut, filter.Guests)
+		if err != nil {
+			return nil, fmt.Errorf("quoteroomtype: roomTypeID[%s]: %w", avl.RoomTypeID, err)
+		}
+
+		if filter.Currency != "" {
+			if rtq, err = c.pricing.ConvertRoomType(ctx, rtq, filter.Currency); err != nil {
+				return nil, fmt.Errorf("convertroomtype: roomTypeID[%s]: %w", avl.RoomTypeID, err)
+			}
+		}
+
+		offers[i] = Offer{
+			Availability:  avl,
+			RoomTypeQuote: rtq,
+		}
+	}
+
+	return offers, nil
+}
+
+// Confirm moves a held reservation to CONFIRMED and authorizes its total,
+// taxes and fees included, on the payment source. A reservation whose hold
+// ran out can no longer be confirmed, even before the reaper expires it.
+func (c *Core) Confirm(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID, cr reservation.ConfirmReservation) (reservation.Reservation, error) {
+	if err := validation.Check(cr); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		var err error
+		res, err = c.move(ctx, tx, reservationID, userID, reservation.StatusConfirmed, nil)
+		if err != nil {
+			return err
+		}
+
+		return c.authorize(ctx, tx, res, cr.PaymentSource, userID)
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// CheckIn records the guest's arrival for a confirmed reservation and puts
+// them in a room, which must be clean. The room is occupied until check-out.
+func (c *Core) CheckIn(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID, ci reservation.CheckInReservation) (reservation.Reservation, error) {
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		apply := func(_ string, res *reservation.Reservation) error {
+			return c.occupy(ctx, tx, res, ci.RoomID, *res.DateCheckedIn)
+		}
+
+		var err error
+		res, err = c.move(ctx, tx, reservationID, userID, reservation.StatusCheckedIn, apply)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// CheckOut records the guest's departure and charges every night of the stay
+// to the reservation's folio in the same transaction. What was authorized at
+// confirmation is captured and posted to the folio as a payment. The room is
+// freed and left dirty for housekeeping.
+func (c *Core) CheckOut(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		var err error
+		res, err = c.move(ctx, tx, reservationID, userID, reservation.StatusCheckedOut, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := c.vacate(ctx, tx, res, *res.DateCheckedOut); err != nil {
+			return err
+		}
+
+		if err := c.folio.PostStay(ctx, tx, res, userID, *res.DateCheckedOut); err != nil {
+			return fmt.Errorf("poststay: %w", err)
+		}
+
+		t, ok, err := c.capture(ctx, tx, res, -1, userID)
+		if err != nil || !ok {
+			return err
+		}
+
+		if err := c.folio.PostCapture(ctx, tx, res, t, userID); err != nil {
+			return fmt.Errorf("postcapture: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// Cancel cancels a held or confirmed reservation, returning its room to
+// availability. For a confirmed reservation the penalty owed under the rate
+// plan's cancellation policy and the amount left to refund are saved on the
+// reservation. A held reservation was never confirmed, so it costs nothing
+// and there is nothing to refund. The penalty is captured from what was
+// authorized at confirmation and the rest released. The freed room is
+// offered to the waitlist.
+func (c *Core) Cancel(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		apply := func(from string, res *reservation.Reservation) error {
+			if from == reservation.StatusHeld {
+				var none int
+				res.CancelPenalty = &none
+				res.CancelRefund = &none
+				return nil
+			}
+
+			cxl, err := c.cancellation(ctx, tx, *res, *res.DateCancelled)
+			if err != nil {
+				return err
+			}
+
+			res.CancelPenalty = &cxl.Penalty
+			res.CancelRefund = &cxl.Refund
+			return nil
+		}
+
+		var err error
+		res, err = c.move(ctx, tx, reservationID, userID, reservation.StatusCancelled, apply)
+		if err != nil {
+			return err
+		}
+
+		if *res.CancelPenalty > 0 {
+			if _, _, err := c.capture(ctx, tx, res, *res.CancelPenalty, userID); err != nil {
+				return err
+			}
+		} else if err := c.void(ctx, tx, res, userID); err != nil {
+			return err
+		}
+
+		return c.offer(ctx, tx, res.RoomTypeID, res.CheckIn, res.CheckOut, *res.DateCancelled)
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// cancellation works out what cancelling the reservation at time now costs,
+// using the policy of its rate plan and the nightly prices it was booked at.
+func (c *Core) cancellation(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, now time.Time) (pricing.Cancellation, error) {
+	propStore := c.property.Tran(tx)
+	resStore := c.Store.Tran(tx)
+	rpStore := c.pricing.RatePlan.Tran(tx)
+
+	prop, err := propStore.QueryByID(ctx, res.PropertyID)
+	if err != nil {
+		return pricing.Cancellation{}, fmt.Errorf("querybyid: propertyID[%s]: %w", res.PropertyID, err)
+	}
+
+	loc, err := time.LoadLocation(prop.TimeZone)
+	if err != nil {
+		return pricing.Cancellation{}, fmt.Errorf("loading time zone[%s]: %w", prop.TimeZone, err)
+	}
+
+	policy := pricing.DefaultPolicy
+	if res.RatePlanID != nil {
+		policy, err = rpStore.QueryByID(ctx, *res.RatePlanID)
+		if err != nil {
+			return pricing.Cancellation{}, fmt.Errorf("querybyid: ratePlanID[%s]: %w", *res.RatePlanID, err)
+		}
+	}
+
+	nights, err := resStore.QueryNights(ctx, res.ID)
+	if err != nil {
+		return pricing.Cancellation{}, fmt.Errorf("querynights: %w", err)
+	}
+
+	rates := make([]int, len(nights))
+	for i, n := range nights {
+		rates[i] = n.Rate
+	}
+
+	return pricing.Penalty(policy, res.CheckIn, rates, loc, now), nil
+}
+
+// NoShow marks a confirmed reservation whose guest never arrived. The room
+// was guaranteed, so what was authorized at confirmation is captured.
+func (c *Core) NoShow(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+	var res reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		var err error
+		res, err = c.move(ctx, tx, reservationID, userID, reservation.StatusNoShow, nil)
+		if err != nil {
+			return err
+		}
+
+		_, _, err = c.capture(ctx, tx, res, -1, userID)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return res, nil
+}
+
+// ExpireReservations marks every HELD reservation whose hold ran out by now as
+// EXPIRED and returns them, offering their rooms to the waitlist. The
+// transitions are recorded against uuid.Nil since no user makes them.
+func (c *Core) ExpireReservations(ctx context.Context, now time.Time) ([]reservation.Reservation, error) {
+	var ress []reservation.Reservation
+
+	tran := func(tx sqlx.ExtContext) error {
+		resStore := c.Store.Tran(tx)
+
+		var err error
+		ress, err = resStore.Expire(ctx, now)
+		if err != nil {
+			return fmt.Errorf("expire: %w", err)
+		}
+
+		for _, res := range ress {
+			h := reservation.History{
+				ID:            uuid.New(),
+				ReservationID: res.ID,
+				FromStatus:    reservation.StatusHeld,
+				ToStatus:      reservation.StatusExpired,
+				UserID:        uuid.Nil,
+				DateCreated:   now,
+			}
+
+			if err := resStore.AddHistory(ctx, h); err != nil {
+				return fmt.Errorf("addhistory: %w", err)
+			}
+		}
+
+		for _, res := range ress {
+			if err := c.offer(ctx, tx, res.RoomTypeID, res.CheckIn, res.CheckOut, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return nil, err
+	}
+
+	return ress, nil
+}
+
+// History returns every status transition of the reservation.
+func (c *Core) History(ctx context.Context, reservationID uuid.UUID) ([]reservation.History, error) {
+	return c.Store.QueryHistory(ctx, reservationID)
+}
+
+// book inserts a HELD reservation and its nightly prices inside tx once the
+// stay is known to fit.
+func (c *Core) book(ctx context.Context, tx sqlx.ExtContext, nr reservation.NewReservation) (reservation.Reservation, error) {
+	rt, q, err := c.claim(ctx, tx, nr.RoomTypeID, nr.RatePlanID, nr.CheckIn, nr.CheckOut, nr.Guests)
+	if err != nil {
+		return reservation.Reservation{}, err
+	}
+
+	return c.create(ctx, tx, rt, nr, q, nil)
+}
+
+// create inserts a HELD reservation of the room type and its nightly prices
+// from q inside tx, picked up from the block when blockID is not nil.
+func (c *Core) create(ctx context.Context, tx sqlx.ExtContext, rt roomtype.RoomType, nr reservation.NewReservation, q pricing.Quote, blockID *uuid.UUID) (reservation.Reservation, error) {
+	now := time.Now()
+	expiresAt := now.Add(reservation.HoldMinutes * time.Minute)
+	res := reservation.Reservation{
+		ID:             uuid.New(),
+		PropertyID:     rt.PropertyID,
+		RoomTypeID:     rt.ID,
+		UserID:         nr.UserID,
+		CheckIn:        nr.CheckIn,
+		CheckOut:       nr.CheckOut,
+		Guests:         nr.Guests,
+		Status:         reservation.StatusHeld,
+		RatePlanID:     nr.RatePlanID,
+		Total:          q.Total,
+		ExpiresAt:      &expiresAt,
+		BlockID:        blockID,
+		DateCreated:    now,
+		DateUpdated:    now,
+		PreferredFloor: nr.PreferredFloor,
+		Accessible:     nr.Accessible,
+		ConnectWith:    nr.ConnectWith,
+	}
+
+	resStore := c.Store.Tran(tx)
+	if err := resStore.Create(ctx, res); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("create: %w", err)
+	}
+
+	nights := make([]reservation.Night, len(q.Nights))
+	for i, n := range q.Nights {
+		nights[i] = reservation.Night{
+			ReservationID: res.ID,
+			Night:         n.Date,
+			Rate:          n.Rate,
+		}
+	}
+
+	if err := resStore.CreateNights(ctx, nights); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("createnights: %w", err)
+	}
+
+	return res, nil
+}
+
+// claim locks the room type inside tx, checks that one of its rooms can host
+// the guests for the whole stay and prices the stay. Stays on a rate plan are
+// priced by the plan, others at the room type's base rate. The lock is held
+// until tx ends, so whatever the caller inserts next is checked against
+// availability one at a time.
+func (c *Core) claim(ctx context.Context, tx sqlx.ExtContext, roomTypeID uuid.UUID, ratePlanID *uuid.UUID, checkIn time.Time, checkOut time.Time, guests int) (roomtype.RoomType, pricing.Quote, error) {
+	rtStore := c.roomType.Tran(tx)
+	avlStore := c.Availability.Tran(tx)
+
+	rt, err := rtStore.QueryByIDForUpdate(ctx, roomTypeID)
+	if err != nil {
+		return roomtype.RoomType{}, pricing.Quote{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", roomTypeID, err)
+	}
+
+	if guests > rt.Capacity {
+		return roomtype.RoomType{}, pricing.Quote{}, ErrCapacity
+	}
+
+	avl, err := avlStore.QueryByRoomTypeID(ctx, rt.ID, checkIn, checkOut)
+	if err != nil {
+		return roomtype.RoomType{}, pricing.Quote{}, fmt.Errorf("querybyroomtypeid: %w", err)
+	}
+
+	if avl.Available < 1 {
+		return roomtype.RoomType{}, pricing.Quote{}, ErrUnavailable
+	}
+
+	if ratePlanID == nil {
+		q, err := pricing.Price(pricing.BasePlan(rt), nil, nil, checkIn, checkOut)
+		if err != nil {
+			return roomtype.RoomType{}, pricing.Quote{}, fmt.Errorf("price: %w", err)
+		}
+
+		return rt, q, nil
+	}
+
+	q, err := c.pricing.QuoteTx(ctx, tx, *ratePlanID, checkIn, checkOut)
+	if err != nil {
+		return roomtype.RoomType{}, pricing.Quote{}, fmt.Errorf("quote: %w", err)
+	}
+
+	if q.RoomTypeID != rt.ID {
+		return roomtype.RoomType{}, pricing.Quote{}, ErrRatePlanMismatch
+	}
+
+	return rt, q, nil
+}
+
+// move locks the reservation inside tx, checks the transition is allowed and
+// records it. Holding the row lock means two concurrent transitions cannot
+// both pass the check against the same current status. When apply is not nil
+// it may change the reservation, knowing the status it moved from, before it
+// is saved.
+func (c *Core) move(ctx context.Context, tx sqlx.ExtContext, reservationID uuid.UUID, userID uuid.UUID, to string, apply func(string, *reservation.Reservation) error) (reservation.Reservation, error) {
+	resStore := c.Store.Tran(tx)
+
+	res, err := resStore.QueryByIDForUpdate(ctx, reservationID)
+	if err != nil {
+		return reservation.Reservation{}, fmt.Errorf("querybyid: reservationID[%s]: %w", reservationID, err)
+	}
+
+	from := res.Status
+	if !CanTransition(from, to) {
+		return reservation.Reservation{}, &TransitionError{ReservationID: res.ID, From: from, To: to}
+	}
+
+	now := time.Now()
+	res.Status = to
+	res.DateUpdated = now
+
+	switch to {
+	case reservation.StatusConfirmed:
+		if res.ExpiresAt != nil && !res.ExpiresAt.After(now) {
+			return reservation.Reservation{}, ErrExpired
+		}
+		res.ExpiresAt = nil
+		res.DateConfirmed = &now
+	case reservation.StatusCheckedIn:
+		res.DateCheckedIn = &now
+	case reservation.StatusCheckedOut:
+		res.DateCheckedOut = &now
+	case reservation.StatusCancelled:
+		res.ExpiresAt = nil
+		res.DateCancelled = &now
+	case reservation.StatusNoShow:
+		res.DateNoShow = &now
+	}
+
+	if apply != nil {
+		if err := apply(from, &res); err != nil {
+			return reservation.Reservation{}, err
+		}
+	}
+
+	if err := resStore.UpdateStatus(ctx, res); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("updatestatus: %w", err)
+	}
+
+	h := reservation.History{
+		ID:            uuid.New(),
+		ReservationID: res.ID,
+		FromStatus:    from,
+		ToStatus:      to,
+		UserID:        userID,
+		DateCreated:   now,
+	}
+
+	if err := resStore.AddHistory(ctx, h); err != nil {
+		return reservation.Reservation{}, fmt.Errorf("addhistory: %w", err)
+	}
+
+	return res, nil
+}
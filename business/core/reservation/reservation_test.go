@@ -0,0 +1,1312 @@
+package reservation_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tcmhoang/sservices/business/core/assignment"
+	foliocore "github.com/tcmhoang/sservices/business/core/folio"
+	hkcore "github.com/tcmhoang/sservices/business/core/housekeeping"
+	"github.com/tcmhoang/sservices/business/core/inventory"
+	"github.com/tcmhoang/sservices/business/core/maintenance"
+	"github.com/tcmhoang/sservices/business/core/pricing"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/availability"
+	"github.com/tcmhoang/sservices/business/data/store/block"
+	"github.com/tcmhoang/sservices/business/data/store/folio"
+	"github.com/tcmhoang/sservices/business/data/store/hold"
+	"github.com/tcmhoang/sservices/business/data/store/outbox"
+	"github.com/tcmhoang/sservices/business/data/store/outoforder"
+	"github.com/tcmhoang/sservices/business/data/store/property"
+	"github.com/tcmhoang/sservices/business/data/store/rateplan"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+	"github.com/tcmhoang/sservices/business/data/store/roomtype"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/data/store/waitlist"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestReservation(t *testing.T) {
+	t.Run("book", book)
+	t.Run("concurrent", concurrent)
+	t.Run("lifecycle", lifecycle)
+	t.Run("holds", holds)
+	t.Run("ratePlans", ratePlans)
+	t.Run("waitlist", waitlists)
+	t.Run("blocks", blocks)
+	t.Run("folio", folios)
+	t.Run("payments", payments)
+	t.Run("housekeeping", housekeeping)
+	t.Run("outOfOrder", outOfOrder)
+}
+
+var (
+	flexibleKing = uuid.MustParse("d4e5f6a7-0001-4b2c-8d3e-4f5a6b7c8d01")
+	propertyID   = uuid.MustParse("9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21")
+	userID       = uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+	adminID      = uuid.MustParse("5cf37266-3473-4006-984f-9325122678b7")
+
+	card = reservation.ConfirmReservation{PaymentSource: payment.SourceApproved}
+)
+
+// confirm adapts Confirm with the approved card to the signature of the
+// other transitions.
+func confirm(core *rescore.Core) func(context.Context, uuid.UUID, uuid.UUID) (reservation.Reservation, error) {
+	return func(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+		return core.Confirm(ctx, reservationID, userID, card)
+	}
+}
+
+// arrive adapts CheckIn into whatever clean room is free to the signature of
+// the other transitions.
+func arrive(core *rescore.Core) func(context.Context, uuid.UUID, uuid.UUID) (reservation.Reservation, error) {
+	return func(ctx context.Context, reservationID uuid.UUID, userID uuid.UUID) (reservation.Reservation, error) {
+		return core.CheckIn(ctx, reservationID, userID, reservation.CheckInReservation{})
+	}
+}
+
+// singleRoomType creates a room type backed by exactly one physical room.
+func singleRoomType(t *testing.T, stest *tests.State) roomtype.RoomType {
+	ctx := context.Background()
+	inv := inventory.NewCore(stest.Log, stest.DB)
+
+	rt, err := inv.CreateRoomType(ctx, roomtype.NewRoomType{
+		PropertyID: propertyID,
+		Name:       "Penthouse",
+		Capacity:   2,
+		BedConfig:  "1 KING",
+		BaseRate:   500,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a room type : %s", err)
+	}
+
+	if _, err := inv.CreateRoom(ctx, room.NewRoom{RoomTypeID: rt.ID, Number: "PH1", Floor: 20}); err != nil {
+		t.Fatalf("Should be able to create a room : %s", err)
+	}
+
+	return rt
+}
+
+func book(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to book rooms.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen booking the only room of a room type.", testID)
+		{
+			ctx := context.Background()
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 3),
+				Guests:     2,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book the room.", tests.Success, testID)
+
+			if res.Total != 3*rt.BaseRate {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, res.Total)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, 3*rt.BaseRate)
+				t.Fatalf("\t%s\tTest %d:\tShould charge the base rate for every night.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould charge the base rate for every night.", tests.Success, testID)
+
+			_, err = core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 0, 2),
+				CheckOut:   checkIn.AddDate(0, 0, 4),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to book an overlapping stay : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to book an overlapping stay.", tests.Success, testID)
+
+			if _, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 0, 3),
+				CheckOut:   checkIn.AddDate(0, 0, 5),
+				Guests:     1,
+				UserID:     userID,
+			}); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book from the check-out day : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book from the check-out day.", tests.Success, testID)
+
+			_, err = core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 2, 0),
+				CheckOut:   checkIn.AddDate(0, 2, 1),
+				Guests:     3,
+				UserID:     userID,
+			})
+			if !errors.Is(err, rescore.ErrCapacity) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to exceed the capacity : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to exceed the capacity.", tests.Success, testID)
+
+			inv := inventory.NewCore(stest.Log, stest.DB)
+			if err := inv.RoomType.Delete(ctx, rt); !errors.Is(err, roomtype.ErrInUse) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to delete a booked room type : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to delete a booked room type.", tests.Success, testID)
+
+			prop, err := inv.Property.QueryByID(ctx, propertyID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the property : %s.", tests.Failed, testID, err)
+			}
+			if err := inv.Property.Delete(ctx, prop); !errors.Is(err, property.ErrInUse) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to delete a booked property : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to delete a booked property.", tests.Success, testID)
+		}
+	}
+}
+
+func concurrent(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to never double-book a room.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen firing parallel bookings at the same room.", testID)
+		{
+			const attempts = 10
+
+			var wg sync.WaitGroup
+			errs := make(chan error, attempts)
+
+			for i := 0; i < attempts; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+
+					_, err := core.Book(context.Background(), reservation.NewReservation{
+						RoomTypeID: rt.ID,
+						CheckIn:    checkIn.AddDate(0, 0, i%2),
+						CheckOut:   checkIn.AddDate(0, 0, 2),
+						Guests:     1,
+						UserID:     userID,
+					})
+					errs <- err
+				}(i)
+			}
+			wg.Wait()
+			close(errs)
+
+			var booked, rejected int
+			for err := range errs {
+				switch {
+				case err == nil:
+					booked++
+				case errors.Is(err, rescore.ErrUnavailable):
+					rejected++
+				default:
+					t.Fatalf("\t%s\tTest %d:\tShould only fail with an availability error : %s.", tests.Failed, testID, err)
+				}
+			}
+
+			if booked != 1 || rejected != attempts-1 {
+				t.Logf("\t\tTest %d:\tBooked: %v Rejected: %v", testID, booked, rejected)
+				t.Fatalf("\t%s\tTest %d:\tShould accept exactly one booking.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould accept exactly one booking.", tests.Success, testID)
+		}
+	}
+}
+
+func lifecycle(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to move reservations through their lifecycle.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen handling a single reservation.", testID)
+		{
+			ctx := context.Background()
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book the room.", tests.Success, testID)
+
+			if res.Status != reservation.StatusHeld {
+				t.Fatalf("\t%s\tTest %d:\tShould start out held : got %s.", tests.Failed, testID, res.Status)
+			}
+			t.Logf("\t%s\tTest %d:\tShould start out held.", tests.Success, testID)
+
+			_, err = arrive(core)(ctx, res.ID, userID)
+			var te *rescore.TransitionError
+			if !errors.As(err, &te) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to check in a held reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to check in a held reservation.", tests.Success, testID)
+
+			steps := []struct {
+				name string
+				fn   func(context.Context, uuid.UUID, uuid.UUID) (reservation.Reservation, error)
+				exp  string
+			}{
+				{"confirm", confirm(core), reservation.StatusConfirmed},
+				{"check in", arrive(core), reservation.StatusCheckedIn},
+				{"check out", core.CheckOut, reservation.StatusCheckedOut},
+			}
+
+			for _, step := range steps {
+				res, err = step.fn(ctx, res.ID, userID)
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to %s : %s.", tests.Failed, testID, step.name, err)
+				}
+				if res.Status != step.exp {
+					t.Fatalf("\t%s\tTest %d:\tShould be %s after %s : got %s.", tests.Failed, testID, step.exp, step.name, res.Status)
+				}
+				t.Logf("\t%s\tTest %d:\tShould be able to %s.", tests.Success, testID, step.name)
+			}
+
+			if res.DateConfirmed == nil || res.DateCheckedIn == nil || res.DateCheckedOut == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould record a timestamp per transition : %+v.", tests.Failed, testID, res)
+			}
+			t.Logf("\t%s\tTest %d:\tShould record a timestamp per transition.", tests.Success, testID)
+
+			if _, err := core.Cancel(ctx, res.ID, userID); !errors.As(err, &te) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to cancel a checked out reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to cancel a checked out reservation.", tests.Success, testID)
+
+			hs, err := core.History(ctx, res.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the history : %s.", tests.Failed, testID, err)
+			}
+			if len(hs) != len(steps) {
+				t.Fatalf("\t%s\tTest %d:\tShould record every transition : got %d.", tests.Failed, testID, len(hs))
+			}
+			t.Logf("\t%s\tTest %d:\tShould record every transition.", tests.Success, testID)
+		}
+
+		testID = 1
+		t.Logf("\tTest %d:\tWhen cancelling a reservation.", testID)
+		{
+			ctx := context.Background()
+
+			nr := reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 1, 0),
+				CheckOut:   checkIn.AddDate(0, 1, 2),
+				Guests:     1,
+				UserID:     userID,
+			}
+
+			res, err := core.Book(ctx, nr)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+
+			cxl, err := core.Cancel(ctx, res.ID, userID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to cancel a held reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to cancel a held reservation.", tests.Success, testID)
+
+			if cxl.CancelPenalty == nil || *cxl.CancelPenalty != 0 || cxl.CancelRefund == nil || *cxl.CancelRefund != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould neither charge nor refund a held reservation : %+v.", tests.Failed, testID, cxl)
+			}
+			t.Logf("\t%s\tTest %d:\tShould neither charge nor refund a held reservation.", tests.Success, testID)
+
+			res, err = core.Book(ctx, nr)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould release the room once cancelled : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould release the room once cancelled.", tests.Success, testID)
+
+			if _, err := core.Confirm(ctx, res.ID, userID, card); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm the reservation : %s.", tests.Failed, testID, err)
+			}
+
+			cxl, err = core.Cancel(ctx, res.ID, userID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to cancel a confirmed reservation : %s.", tests.Failed, testID, err)
+			}
+
+			if cxl.CancelPenalty == nil || *cxl.CancelPenalty != 0 || cxl.CancelRefund == nil || *cxl.CancelRefund != res.Total {
+				t.Fatalf("\t%s\tTest %d:\tShould refund everything well before arrival : %+v.", tests.Failed, testID, cxl)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refund everything well before arrival.", tests.Success, testID)
+		}
+
+		testID = 2
+		t.Logf("\tTest %d:\tWhen a held reservation is never confirmed.", testID)
+		{
+			ctx := context.Background()
+
+			nr := reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 2, 0),
+				CheckOut:   checkIn.AddDate(0, 2, 2),
+				Guests:     1,
+				UserID:     userID,
+			}
+
+			res, err := core.Book(ctx, nr)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+
+			if res.ExpiresAt == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould give a held reservation an expiry.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould give a held reservation an expiry.", tests.Success, testID)
+
+			ress, err := core.ExpireReservations(ctx, res.ExpiresAt.Add(time.Second))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to expire reservations : %s.", tests.Failed, testID, err)
+			}
+			if len(ress) != 1 || ress[0].ID != res.ID || ress[0].Status != reservation.StatusExpired {
+				t.Fatalf("\t%s\tTest %d:\tShould expire the lapsed reservation : %+v.", tests.Failed, testID, ress)
+			}
+			t.Logf("\t%s\tTest %d:\tShould expire the lapsed reservation.", tests.Success, testID)
+
+			var te *rescore.TransitionError
+			if _, err := core.Confirm(ctx, res.ID, userID, card); !errors.As(err, &te) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to confirm an expired reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to confirm an expired reservation.", tests.Success, testID)
+
+			if _, err := core.Book(ctx, nr); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould release the room once expired : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould release the room once expired.", tests.Success, testID)
+		}
+	}
+}
+
+func holds(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	nh := hold.NewHold{
+		RoomTypeID: rt.ID,
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 2),
+		Guests:     1,
+		UserID:     userID,
+	}
+
+	nr := reservation.NewReservation{
+		RoomTypeID: rt.ID,
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 2),
+		Guests:     1,
+		UserID:     userID,
+	}
+
+	t.Log("Given the need to hold rooms while guests check out.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen holding the only room of a room type.", testID)
+		{
+			ctx := context.Background()
+
+			h, err := core.PlaceHold(ctx, nh)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to place a hold : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to place a hold.", tests.Success, testID)
+
+			if exp := h.DateCreated.Add(hold.DefaultMinutes * time.Minute); !h.ExpiresAt.Equal(exp) {
+				t.Fatalf("\t%s\tTest %d:\tShould default to %d minutes : got %v.", tests.Failed, testID, hold.DefaultMinutes, h.ExpiresAt)
+			}
+			t.Logf("\t%s\tTest %d:\tShould default to %d minutes.", tests.Success, testID, hold.DefaultMinutes)
+
+			if _, err := core.Book(ctx, nr); !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to book a held room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to book a held room.", tests.Success, testID)
+
+			ext, err := core.ExtendHold(ctx, h.ID, hold.ExtendHold{Minutes: 30})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to extend the hold : %s.", tests.Failed, testID, err)
+			}
+			if !ext.ExpiresAt.After(h.ExpiresAt) {
+				t.Fatalf("\t%s\tTest %d:\tShould push the expiry back : got %v.", tests.Failed, testID, ext.ExpiresAt)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to extend the hold.", tests.Success, testID)
+
+			if err := core.ReleaseHold(ctx, h.ID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to release the hold : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to release the hold.", tests.Success, testID)
+
+			if _, err := core.ExtendHold(ctx, h.ID, hold.ExtendHold{Minutes: 30}); !errors.Is(err, rescore.ErrHoldInactive) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to extend a released hold : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to extend a released hold.", tests.Success, testID)
+		}
+
+		testID = 1
+		t.Logf("\tTest %d:\tWhen a hold runs out.", testID)
+		{
+			ctx := context.Background()
+
+			h, err := core.PlaceHold(ctx, nh)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to place a hold : %s.", tests.Failed, testID, err)
+			}
+
+			hs, err := core.ExpireHolds(ctx, h.ExpiresAt.Add(time.Second))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to expire holds : %s.", tests.Failed, testID, err)
+			}
+			if len(hs) != 1 || hs[0].ID != h.ID || hs[0].Status != hold.StatusExpired {
+				t.Fatalf("\t%s\tTest %d:\tShould expire the lapsed hold : %+v.", tests.Failed, testID, hs)
+			}
+			t.Logf("\t%s\tTest %d:\tShould expire the lapsed hold.", tests.Success, testID)
+
+			if _, err := core.ConfirmHold(ctx, h.ID, userID, card); !errors.Is(err, rescore.ErrHoldInactive) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to confirm an expired hold : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to confirm an expired hold.", tests.Success, testID)
+		}
+
+		testID = 2
+		t.Logf("\tTest %d:\tWhen confirming a hold.", testID)
+		{
+			ctx := context.Background()
+
+			h, err := core.PlaceHold(ctx, nh)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to place a hold : %s.", tests.Failed, testID, err)
+			}
+
+			res, err := core.ConfirmHold(ctx, h.ID, userID, card)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm the hold : %s.", tests.Failed, testID, err)
+			}
+			if res.Status != reservation.StatusConfirmed {
+				t.Fatalf("\t%s\tTest %d:\tShould get a confirmed reservation : got %s.", tests.Failed, testID, res.Status)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to confirm the hold.", tests.Success, testID)
+
+			if _, err := core.PlaceHold(ctx, nh); !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to hold a booked room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to hold a booked room.", tests.Success, testID)
+		}
+	}
+}
+
+func ratePlans(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	prc := pricing.NewCore(stest.Log, stest.DB)
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+	checkOut := checkIn.AddDate(0, 0, 3)
+
+	t.Log("Given the need to book stays on rate plans.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen booking on a rate plan.", testID)
+		{
+			ctx := context.Background()
+
+			rp, err := prc.CreateRatePlan(ctx, rateplan.NewRatePlan{
+				RoomTypeID: rt.ID,
+				Name:       "Weekend",
+				Refundable: true,
+				BaseRate:   400,
+				MinStay:    2,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a rate plan : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a rate plan.", tests.Success, testID)
+
+			wr := rateplan.WeekdayRate{RatePlanID: rp.ID, Weekday: int(checkIn.Weekday()), Rate: 650}
+			if err := prc.RatePlan.SetWeekdayRate(ctx, wr); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to set a weekday rate : %s.", tests.Failed, testID, err)
+			}
+
+			rate := 700
+			o := rateplan.Override{RatePlanID: rp.ID, Night: checkIn.AddDate(0, 0, 1), Rate: &rate}
+			if err := prc.RatePlan.SetOverride(ctx, o); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to set an override : %s.", tests.Failed, testID, err)
+			}
+
+			_, err = core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				RatePlanID: &rp.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 1),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if !errors.Is(err, pricing.ErrMinStay) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to book under the minimum stay : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to book under the minimum stay.", tests.Success, testID)
+
+			_, err = core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				RatePlanID: &flexibleKing,
+				CheckIn:    checkIn,
+				CheckOut:   checkOut,
+				Guests:     1,
+				UserID:     userID,
+			})
+			if !errors.Is(err, rescore.ErrRatePlanMismatch) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to book on another room type's plan : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to book on another room type's plan.", tests.Success, testID)
+
+			q, err := prc.Quote(ctx, rp.ID, checkIn, checkOut, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to get a quote : %s.", tests.Failed, testID, err)
+			}
+
+			offers, err := core.Search(ctx, availability.Filter{PropertyID: &propertyID, CheckIn: checkIn, CheckOut: checkOut, Guests: 1}, 1, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to search availability : %s.", tests.Failed, testID, err)
+			}
+
+			var offer *rescore.Offer
+			for i := range offers {
+				if offers[i].RoomTypeID == rt.ID {
+					offer = &offers[i]
+				}
+			}
+			if offer == nil || len(offer.RatePlans) != 1 || offer.RatePlans[0].Total != q.Total || offer.Base.Total != 3*rt.BaseRate {
+				t.Fatalf("\t%s\tTest %d:\tShould search with the same prices as the quote : %+v.", tests.Failed, testID, offer)
+			}
+			t.Logf("\t%s\tTest %d:\tShould search with the same prices as the quote.", tests.Success, testID)
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				RatePlanID: &rp.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkOut,
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book on the rate plan : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book on the rate plan.", tests.Success, testID)
+
+			if res.Total != q.Total || res.Total != 650+700+400 {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, res.Total)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, q.Total)
+				t.Fatalf("\t%s\tTest %d:\tShould charge the quoted total.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould charge the quoted total.", tests.Success, testID)
+		}
+	}
+}
+
+func waitlists(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to offer freed rooms to the waitlist.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen the only room of a room type frees up.", testID)
+		{
+			ctx := context.Background()
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 3),
+				Guests:     1,
+				UserID:     adminID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+			if _, err := core.Confirm(ctx, res.ID, adminID, card); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm the reservation : %s.", tests.Failed, testID, err)
+			}
+
+			first, err := core.JoinWaitlist(ctx, waitlist.NewEntry{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to join the waitlist : %s.", tests.Failed, testID, err)
+			}
+
+			second, err := core.JoinWaitlist(ctx, waitlist.NewEntry{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 0, 1),
+				CheckOut:   checkIn.AddDate(0, 0, 3),
+				Guests:     2,
+				UserID:     adminID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to join the waitlist : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to join the waitlist.", tests.Success, testID)
+
+			if _, err := core.Cancel(ctx, res.ID, adminID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to cancel the reservation : %s.", tests.Failed, testID, err)
+			}
+
+			first, err = core.Waitlist.QueryByID(ctx, first.ID)
+			if err != nil || first.Status != waitlist.StatusOffered || first.HoldID == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould offer the room to the first guest in line : %+v %v.", tests.Failed, testID, first, err)
+			}
+
+			second, err = core.Waitlist.QueryByID(ctx, second.ID)
+			if err != nil || second.Status != waitlist.StatusWaiting {
+				t.Fatalf("\t%s\tTest %d:\tShould keep the next guest waiting : %+v %v.", tests.Failed, testID, second, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould offer the room to the first guest in line.", tests.Success, testID)
+
+			ms, err := core.Outbox.QueryByUserID(ctx, userID)
+			if err != nil || len(ms) != 1 || ms[0].Kind != outbox.KindWaitlistOffer {
+				t.Fatalf("\t%s\tTest %d:\tShould record the offer in the outbox : %+v %v.", tests.Failed, testID, ms, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould record the offer in the outbox.", tests.Success, testID)
+
+			h, err := core.Hold.QueryByID(ctx, *first.HoldID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the offered hold : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := core.ExpireHolds(ctx, h.ExpiresAt.Add(time.Second)); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to expire holds : %s.", tests.Failed, testID, err)
+			}
+
+			first, err = core.Waitlist.QueryByID(ctx, first.ID)
+			if err != nil || first.Status != waitlist.StatusExpired {
+				t.Fatalf("\t%s\tTest %d:\tShould expire the lapsed offer : %+v %v.", tests.Failed, testID, first, err)
+			}
+
+			second, err = core.Waitlist.QueryByID(ctx, second.ID)
+			if err != nil || second.Status != waitlist.StatusOffered || second.HoldID == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould offer the room to the next guest in line : %+v %v.", tests.Failed, testID, second, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould offer the room to the next guest once an offer lapses.", tests.Success, testID)
+
+			if _, err := core.ConfirmHold(ctx, *second.HoldID, adminID, card); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm the offered hold : %s.", tests.Failed, testID, err)
+			}
+
+			second, err = core.Waitlist.QueryByID(ctx, second.ID)
+			if err != nil || second.Status != waitlist.StatusFulfilled {
+				t.Fatalf("\t%s\tTest %d:\tShould fulfil the entry once the hold is confirmed : %+v %v.", tests.Failed, testID, second, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould fulfil the entry once the hold is confirmed.", tests.Success, testID)
+
+			if err := core.LeaveWaitlist(ctx, second.ID); !errors.Is(err, rescore.ErrNotWaiting) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to leave the waitlist once fulfilled : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to leave the waitlist once fulfilled.", tests.Success, testID)
+		}
+	}
+}
+
+func blocks(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	y, m, d := time.Now().AddDate(0, 1, 0).Date()
+	checkIn := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	cutoff := checkIn.AddDate(0, 0, -7)
+
+	nb := block.NewBlock{
+		RoomTypeID: rt.ID,
+		Name:       "GopherCon",
+		Code:       "GOPHERCON",
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 3),
+		Rooms:      1,
+		Rate:       120,
+		Cutoff:     cutoff,
+	}
+
+	nr := reservation.NewReservation{
+		RoomTypeID: rt.ID,
+		CheckIn:    checkIn,
+		CheckOut:   checkIn.AddDate(0, 0, 1),
+		Guests:     1,
+		UserID:     userID,
+	}
+
+	t.Log("Given the need to set rooms aside for groups.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen blocking the only room of a room type.", testID)
+		{
+			ctx := context.Background()
+
+			big := nb
+			big.Rooms = 2
+			if _, err := core.CreateBlock(ctx, big); !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to block more rooms than are free : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to block more rooms than are free.", tests.Success, testID)
+
+			b, err := core.CreateBlock(ctx, nb)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a block : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a block.", tests.Success, testID)
+
+			if _, err := core.Book(ctx, nr); !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to book a blocked room : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to book a blocked room.", tests.Success, testID)
+
+			p := block.Pickup{
+				Code:     nb.Code,
+				CheckIn:  checkIn.AddDate(0, 0, 1),
+				CheckOut: checkIn.AddDate(0, 0, 3),
+				Guests:   1,
+				UserID:   userID,
+			}
+
+			res, err := core.PickUp(ctx, p)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to pick up a room : %s.", tests.Failed, testID, err)
+			}
+			if res.BlockID == nil || *res.BlockID != b.ID || res.Total != 2*nb.Rate {
+				t.Fatalf("\t%s\tTest %d:\tShould charge the negotiated rate : %+v.", tests.Failed, testID, res)
+			}
+			t.Logf("\t%s\tTest %d:\tShould pick up a room at the negotiated rate.", tests.Success, testID)
+
+			if _, err := core.PickUp(ctx, p); !errors.Is(err, rescore.ErrBlockFull) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to pick up more rooms than blocked : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to pick up more rooms than blocked.", tests.Success, testID)
+
+			out := p
+			out.CheckOut = checkIn.AddDate(0, 0, 4)
+			if _, err := core.PickUp(ctx, out); !errors.Is(err, rescore.ErrOutsideBlock) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to stay past the block : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to stay past the block.", tests.Success, testID)
+
+			bs, err := core.ReleaseBlocks(ctx, cutoff.Add(time.Second))
+			if err != nil || len(bs) != 1 || bs[0].Status != block.StatusReleased {
+				t.Fatalf("\t%s\tTest %d:\tShould release the block at its cutoff : %+v %v.", tests.Failed, testID, bs, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould release the block at its cutoff.", tests.Success, testID)
+
+			if _, err := core.Book(ctx, nr); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the night nobody picked up : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book the night nobody picked up.", tests.Success, testID)
+
+			late := nr
+			late.CheckIn = checkIn.AddDate(0, 0, 1)
+			late.CheckOut = checkIn.AddDate(0, 0, 2)
+			if _, err := core.Book(ctx, late); !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould keep the picked up room once released : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould keep the picked up room once released.", tests.Success, testID)
+		}
+	}
+}
+
+func folios(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	fcore := foliocore.NewCore(stest.Log, stest.DB)
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to bill stays.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen a guest checks out.", testID)
+		{
+			ctx := context.Background()
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := fcore.PostPayment(ctx, res.ID, folio.NewPayment{Amount: 10}, adminID, time.Now()); !errors.Is(err, foliocore.ErrNotBillable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to bill a held reservation : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to bill a held reservation.", tests.Success, testID)
+
+			for _, fn := range []func(context.Context, uuid.UUID, uuid.UUID) (reservation.Reservation, error){confirm(core), arrive(core), core.CheckOut} {
+				if _, err := fn(ctx, res.ID, adminID); err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to go through the stay : %s.", tests.Failed, testID, err)
+				}
+			}
+
+			out, err := core.Store.QueryByID(ctx, res.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the reservation : %s.", tests.Failed, testID, err)
+			}
+
+			st, err := fcore.Statement(ctx, res.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the folio : %s.", tests.Failed, testID, err)
+			}
+			if len(st.Lines) != 3 || st.Charges != res.Total || st.Payments != res.Total || st.Balance != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould charge every night and the captured card at checkout : %+v.", tests.Failed, testID, st)
+			}
+			t.Logf("\t%s\tTest %d:\tShould charge every night and the captured card at checkout.", tests.Success, testID)
+
+			for _, l := range st.Lines {
+				if l.Kind == folio.KindRoom && !l.DateCreated.Equal(*out.DateCheckedOut) {
+					t.Fatalf("\t%s\tTest %d:\tShould date the nights at the time of checkout : %v %v.", tests.Failed, testID, l.DateCreated, *out.DateCheckedOut)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould date the nights at the time of checkout.", tests.Success, testID)
+
+			st, err = fcore.PostCharge(ctx, res.ID, folio.NewCharge{Kind: folio.KindTax, Description: "City tax", Amount: 4}, adminID, time.Now())
+			if err != nil || st.Balance != 4 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post a tax : %+v %v.", tests.Failed, testID, st, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to post a tax.", tests.Success, testID)
+
+			st, err = fcore.PostPayment(ctx, res.ID, folio.NewPayment{Amount: 10, Reference: "CASH-1"}, adminID, time.Now())
+			if err != nil || st.Balance != -6 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to post a payment : %+v %v.", tests.Failed, testID, st, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to post a payment.", tests.Success, testID)
+
+			if _, err := fcore.PostRefund(ctx, res.ID, folio.NewPayment{Amount: res.Total + 11}, adminID, time.Now()); !errors.Is(err, foliocore.ErrOverRefund) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to refund more than was paid : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to refund more than was paid.", tests.Success, testID)
+
+			st, err = fcore.PostRefund(ctx, res.ID, folio.NewPayment{Amount: 6}, adminID, time.Now())
+			if err != nil || st.Balance != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould settle the folio with a refund : %+v %v.", tests.Failed, testID, st, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould settle the folio with a refund.", tests.Success, testID)
+		}
+	}
+}
+
+func payments(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to charge guests through the payment gateway.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen confirming and cancelling a reservation.", testID)
+		{
+			ctx := context.Background()
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+
+			_, err = core.Confirm(ctx, res.ID, userID, reservation.ConfirmReservation{PaymentSource: payment.SourceDeclined})
+			var de *payment.DeclineError
+			if !errors.As(err, &de) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT confirm with a declined card : %s.", tests.Failed, testID, err)
+			}
+
+			saved, err := core.Store.QueryByID(ctx, res.ID)
+			if err != nil || saved.Status != reservation.StatusHeld {
+				t.Fatalf("\t%s\tTest %d:\tShould keep the reservation held after a decline : %+v %v.", tests.Failed, testID, saved, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT confirm with a declined card.", tests.Success, testID)
+
+			if _, err := core.Confirm(ctx, res.ID, userID, card); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm with an approved card : %s.", tests.Failed, testID, err)
+			}
+
+			ts, err := core.Transaction.QueryByReservationID(ctx, res.ID)
+			if err != nil || len(ts) != 1 || ts[0].Kind != transaction.KindAuthorize || ts[0].Amount != res.Total {
+				t.Fatalf("\t%s\tTest %d:\tShould authorize the total on confirmation : %+v %v.", tests.Failed, testID, ts, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould authorize the total on confirmation.", tests.Success, testID)
+
+			res, err = core.Cancel(ctx, res.ID, userID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to cancel : %s.", tests.Failed, testID, err)
+			}
+
+			exp := transaction.KindVoid
+			if *res.CancelPenalty > 0 {
+				exp = transaction.KindCapture
+			}
+
+			ts, err = core.Transaction.QueryByReservationID(ctx, res.ID)
+			if err != nil || len(ts) != 2 || ts[1].Kind != exp {
+				t.Fatalf("\t%s\tTest %d:\tShould settle the authorization on cancellation with %s : %+v %v.", tests.Failed, testID, exp, ts, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould settle the authorization on cancellation.", tests.Success, testID)
+		}
+	}
+}
+
+func housekeeping(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	hk := hkcore.NewCore(stest.Log, stest.DB)
+	rmStore := room.NewStore(stest.Log, stest.DB)
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to only check guests in to clean rooms.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen a guest leaves the room the next guest arrives in.", testID)
+		{
+			ctx := context.Background()
+
+			rms, err := rmStore.QueryByRoomTypeID(ctx, rt.ID)
+			if err != nil || len(rms) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to find the room : %+v %v.", tests.Failed, testID, rms, err)
+			}
+			rm := rms[0]
+
+			var ress []reservation.Reservation
+			for i := 0; i < 2; i++ {
+				res, err := core.Book(ctx, reservation.NewReservation{
+					RoomTypeID: rt.ID,
+					CheckIn:    checkIn.AddDate(0, 0, 2*i),
+					CheckOut:   checkIn.AddDate(0, 0, 2*i+2),
+					Guests:     1,
+					UserID:     userID,
+				})
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+				}
+
+				if _, err := core.Confirm(ctx, res.ID, userID, card); err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to confirm : %s.", tests.Failed, testID, err)
+				}
+				ress = append(ress, res)
+			}
+
+			res, err := arrive(core)(ctx, ress[0].ID, adminID)
+			if err != nil || res.RoomID == nil || *res.RoomID != rm.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould check the first guest in to the clean room : %+v %v.", tests.Failed, testID, res, err)
+			}
+
+			rm, err = rmStore.QueryByID(ctx, rm.ID)
+			if err != nil || rm.Status != room.StatusOccupied {
+				t.Fatalf("\t%s\tTest %d:\tShould occupy the room : %+v %v.", tests.Failed, testID, rm, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould check the first guest in to the clean room.", tests.Success, testID)
+
+			if _, err := core.CheckOut(ctx, ress[0].ID, adminID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to check out : %s.", tests.Failed, testID, err)
+			}
+
+			rm, err = rmStore.QueryByID(ctx, rm.ID)
+			if err != nil || rm.Status != room.StatusAvailable || rm.Housekeeping != room.HousekeepingDirty {
+				t.Fatalf("\t%s\tTest %d:\tShould free the room dirty on check-out : %+v %v.", tests.Failed, testID, rm, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould free the room dirty on check-out.", tests.Success, testID)
+
+			if _, err := arrive(core)(ctx, ress[1].ID, adminID); !errors.Is(err, rescore.ErrNoRoomReady) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT find a clean room for the next guest : %v.", tests.Failed, testID, err)
+			}
+
+			_, err = core.CheckIn(ctx, ress[1].ID, adminID, reservation.CheckInReservation{RoomID: &rm.ID})
+			if !errors.Is(err, rescore.ErrRoomNotReady) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT check in to the dirty room : %v.", tests.Failed, testID, err)
+			}
+
+			saved, err := core.Store.QueryByID(ctx, ress[1].ID)
+			if err != nil || saved.Status != reservation.StatusConfirmed {
+				t.Fatalf("\t%s\tTest %d:\tShould keep the reservation confirmed : %+v %v.", tests.Failed, testID, saved, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT check in to the dirty room.", tests.Success, testID)
+
+			if _, err := hk.SetStatus(ctx, rm.ID, room.UpdateHousekeeping{Status: room.HousekeepingClean}, time.Now()); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to clean the room : %s.", tests.Failed, testID, err)
+			}
+
+			res, err = core.CheckIn(ctx, ress[1].ID, adminID, reservation.CheckInReservation{RoomID: &rm.ID})
+			if err != nil || res.RoomID == nil || *res.RoomID != rm.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould check in to the room once clean : %+v %v.", tests.Failed, testID, res, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould check in to the room once clean.", tests.Success, testID)
+		}
+	}
+}
+
+func outOfOrder(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := rescore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	mnt := maintenance.NewCore(stest.Log, stest.DB)
+	asg := assignment.NewCore(stest.Log, stest.DB)
+	rmStore := room.NewStore(stest.Log, stest.DB)
+	rt := singleRoomType(t, stest)
+
+	checkIn := time.Now().AddDate(0, 1, 0)
+
+	t.Log("Given the need to take rooms out of order.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen the only room of a room type is out of order.", testID)
+		{
+			ctx := context.Background()
+
+			rms, err := rmStore.QueryByRoomTypeID(ctx, rt.ID)
+			if err != nil || len(rms) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to find the room : %+v %v.", tests.Failed, testID, rms, err)
+			}
+			rm := rms[0]
+
+			res, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn,
+				CheckOut:   checkIn.AddDate(0, 0, 2),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book the room : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := core.Confirm(ctx, res.ID, userID, card); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to confirm : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := asg.Override(ctx, res.ID, reservation.AssignRoom{RoomID: &rm.ID}, time.Now()); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to assign the room : %s.", tests.Failed, testID, err)
+			}
+
+			cl, err := mnt.Create(ctx, outoforder.NewPeriod{
+				RoomID: rm.ID,
+				Starts: checkIn.AddDate(0, 0, 1),
+				Ends:   checkIn.AddDate(0, 0, 4),
+				Reason: "Leaking pipe",
+				UserID: adminID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to take the room out of order : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to take the room out of order.", tests.Success, testID)
+
+			if len(cl.Conflicts.Reservations) != 1 || cl.Conflicts.Reservations[0].ID != res.ID || cl.Conflicts.Overbooked != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould report the stay in the room as a conflict : %+v.", tests.Failed, testID, cl.Conflicts)
+			}
+			t.Logf("\t%s\tTest %d:\tShould report the stay in the room as a conflict.", tests.Success, testID)
+
+			_, err = mnt.Create(ctx, outoforder.NewPeriod{
+				RoomID: rm.ID,
+				Starts: checkIn.AddDate(0, 0, 8),
+				Ends:   checkIn.AddDate(0, 0, 8).Add(time.Hour),
+				Reason: "Repainting",
+				UserID: adminID,
+			})
+			if _, ok := validation.Cause(err).(validation.FieldErrors); !ok {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT take the room out of order for less than a night : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT take the room out of order for less than a night.", tests.Success, testID)
+
+			_, err = core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 0, 3),
+				CheckOut:   checkIn.AddDate(0, 0, 5),
+				Guests:     1,
+				UserID:     userID,
+			})
+			if !errors.Is(err, rescore.ErrUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT book over the out-of-order period : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT book over the out-of-order period.", tests.Success, testID)
+
+			if _, err := core.Book(ctx, reservation.NewReservation{
+				RoomTypeID: rt.ID,
+				CheckIn:    checkIn.AddDate(0, 0, 4),
+				CheckOut:   checkIn.AddDate(0, 0, 6),
+				Guests:     1,
+				UserID:     userID,
+			}); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to book once the room is back : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to book once the room is back.", tests.Success, testID)
+
+			if _, err := asg.Override(ctx, res.ID, reservation.AssignRoom{RoomID: &rm.ID}, time.Now()); !errors.Is(err, assignment.ErrOutOfService) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT assign a room out of order : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT assign a room out of order.", tests.Success, testID)
+
+			if _, err := core.CheckIn(ctx, res.ID, adminID, reservation.CheckInReservation{}); !errors.Is(err, rescore.ErrRoomUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT check in to an assigned room out of order : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT check in to an assigned room out of order.", tests.Success, testID)
+
+			if _, err := core.CheckIn(ctx, res.ID, adminID, reservation.CheckInReservation{RoomID: &rm.ID}); !errors.Is(err, rescore.ErrRoomUnavailable) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT check in to a chosen room out of order : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT check in to a chosen room out of order.", tests.Success, testID)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/room"
+)
+
+// occupy puts the guest of the reservation in a room inside tx: the room
+// asked for, else the room assigned to the reservation, else the first free
+// room of its room type ready for guests. Rooms that are not clean, out of
+// order any night of the stay or assigned to another stay on those nights
+// cannot be checked in to.
+func (c *Core) occupy(ctx context.Context, tx sqlx.ExtContext, res *reservation.Reservation, roomID *uuid.UUID, now time.Time) error {
+	rmStore := c.room.Tran(tx)
+	oooStore := c.outOfOrder.Tran(tx)
+	resStore := c.Store.Tran(tx)
+
+	if roomID == nil {
+		roomID = res.RoomID
+	}
+
+	var rm room.Room
+	var err error
+
+	switch {
+	case roomID != nil:
+		rm, err = rmStore.QueryByIDForUpdate(ctx, *roomID)
+		if err != nil {
+			return fmt.Errorf("querybyid: roomID[%s]: %w", *roomID, err)
+		}
+
+		if rm.RoomTypeID != res.RoomTypeID {
+			return ErrWrongRoom
+		}
+
+		if rm.Status != room.StatusAvailable {
+			return ErrRoomUnavailable
+		}
+
+		if rm.Housekeeping != room.HousekeepingClean && rm.Housekeeping != room.HousekeepingInspected {
+			return ErrRoomNotReady
+		}
+
+		periods, err := oooStore.QueryByRoomID(ctx, rm.ID, res.CheckIn, res.CheckOut)
+		if err != nil {
+			return fmt.Errorf("querybyroomid: %w", err)
+		}
+
+		if len(periods) > 0 {
+			return ErrRoomUnavailable
+		}
+
+		booked, err := resStore.QueryAssigned(ctx, res.PropertyID, res.CheckIn, res.CheckOut)
+		if err != nil {
+			return fmt.Errorf("queryassigned: %w", err)
+		}
+
+		for _, b := range booked {
+			if b.ID != res.ID && *b.RoomID == rm.ID {
+				return ErrRoomTaken
+			}
+		}
+
+	default:
+		rm, err = rmStore.QueryReadyForUpdate(ctx, res.RoomTypeID, res.ID, res.CheckIn, res.CheckOut)
+		if err != nil {
+			if errors.Is(err, room.ErrNotFound) {
+				return ErrNoRoomReady
+			}
+			return fmt.Errorf("queryready: roomTypeID[%s]: %w", res.RoomTypeID, err)
+		}
+	}
+
+	rm.Status = room.StatusOccupied
+	rm.DateUpdated = now
+
+	if err := rmStore.UpdateState(ctx, rm); err != nil {
+		return fmt.Errorf("updatestate: %w", err)
+	}
+
+	res.RoomID = &rm.ID
+
+	return nil
+}
+
+// vacate frees the room of the reservation inside tx once the guest left, and
+// marks it dirty for housekeeping.
+func (c *Core) vacate(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation, now time.Time) error {
+	if res.RoomID == nil {
+		return nil
+	}
+
+	rmStore := c.room.Tran(tx)
+
+	rm, err := rmStore.QueryByIDForUpdate(ctx, *res.RoomID)
+	if err != nil {
+		return fmt.Errorf("querybyid: roomID[%s]: %w", *res.RoomID, err)
+	}
+
+	rm.Status = room.StatusAvailable
+	rm.Housekeeping = room.HousekeepingDirty
+	rm.DateUpdated = now
+
+	if err := rmStore.UpdateState(ctx, rm); err != nil {
+		return fmt.Errorf("updatestate: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/hold"
+	"github.com/tcmhoang/sservices/business/data/store/outbox"
+	"github.com/tcmhoang/sservices/business/data/store/waitlist"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/date"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+var ErrNotWaiting = errors.New("waitlist entry is no longer waiting")
+
+// JoinWaitlist puts the guest in line for a room type that is sold out for
+// the stay. Guests are offered freed rooms in the order they joined.
+func (c *Core) JoinWaitlist(ctx context.Context, ne waitlist.NewEntry) (waitlist.Entry, error) {
+	ne.CheckIn = date.Truncate(ne.CheckIn)
+	ne.CheckOut = date.Truncate(ne.CheckOut)
+
+	if err := validation.Check(ne); err != nil {
+		return waitlist.Entry{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	rt, err := c.roomType.QueryByID(ctx, ne.RoomTypeID)
+	if err != nil {
+		return waitlist.Entry{}, fmt.Errorf("querybyid: roomTypeID[%s]: %w", ne.RoomTypeID, err)
+	}
+
+	if ne.Guests > rt.Capacity {
+		return waitlist.Entry{}, ErrCapacity
+	}
+
+	now := time.Now()
+	e := waitlist.Entry{
+		ID:          uuid.New(),
+		PropertyID:  rt.PropertyID,
+		RoomTypeID:  rt.ID,
+		UserID:      ne.UserID,
+		CheckIn:     ne.CheckIn,
+		CheckOut:    ne.CheckOut,
+		Guests:      ne.Guests,
+		Status:      waitlist.StatusWaiting,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := c.Waitlist.Create(ctx, e); err != nil {
+		return waitlist.Entry{}, fmt.Errorf("create: %w", err)
+	}
+
+	return e, nil
+}
+
+// LeaveWaitlist takes a waiting guest out of line. A guest who was already
+// offered a room releases its hold instead.
+func (c *Core) LeaveWaitlist(ctx context.Context, waitlistID uuid.UUID) error {
+	tran := func(tx sqlx.ExtContext) error {
+		wlStore := c.Waitlist.Tran(tx)
+
+		e, err := wlStore.QueryByIDForUpdate(ctx, waitlistID)
+		if err != nil {
+			return fmt.Errorf("querybyid: waitlistID[%s]: %w", waitlistID, err)
+		}
+
+		if e.Status != waitlist.StatusWaiting {
+			return ErrNotWaiting
+		}
+
+		e.Status = waitlist.StatusCancelled
+		e.DateUpdated = time.Now()
+
+		if err := wlStore.Update(ctx, e); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		return nil
+	}
+
+	return database.WithinTran(ctx, c.log, c.db, tran)
+}
+
+// offer hands the rooms of a room type freed for [checkIn, checkOut) to the
+// waitlist inside tx. Waiting entries whose stay overlaps the freed dates are
+// considered oldest first, and each one that now fits is given a hold for
+// waitlist.OfferMinutes along with a message in the outbox telling the guest.
+// Entries that still don't fit keep their place in line.
+func (c *Core) offer(ctx context.Context, tx sqlx.ExtContext, roomTypeID uuid.UUID, checkIn time.Time, checkOut time.Time, now time.Time) error {
+	wlStore := c.Waitlist.Tran(tx)
+	hStore := c.Hold.Tran(tx)
+	obStore := c.Outbox.Tran(tx)
+
+	es, err := wlStore.QueryWaiting(ctx, roomTypeID, checkIn, checkOut)
+	if err != nil {
+		return fmt.Errorf("querywaiting: %w", err)
+	}
+
+	for _, e := range es {
+		if _, _, err := c.claim(ctx, tx, e.RoomTypeID, nil, e.CheckIn, e.CheckOut, e.Guests); err != nil {
+			if errors.Is(err, ErrUnavailable) || errors.Is(err, ErrCapacity) {
+				continue
+			}
+			return err
+		}
+
+		h := hold.Hold{
+			ID:          uuid.New(),
+			PropertyID:  e.PropertyID,
+			RoomTypeID:  e.RoomTypeID,
+			UserID:      e.UserID,
+			CheckIn:     e.CheckIn,
+			CheckOut:    e.CheckOut,
+			Guests:      e.Guests,
+			Status:      hold.StatusActive,
+			ExpiresAt:   now.Add(waitlist.OfferMinutes * time.Minute),
+			DateCreated: now,
+			DateUpdated: now,
+		}
+
+		if err := hStore.Create(ctx, h); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		e.Status = waitlist.StatusOffered
+		e.HoldID = &h.ID
+		e.DateUpdated = now
+
+		if err := wlStore.Update(ctx, e); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		wo := outbox.WaitlistOffer{
+			WaitlistID: e.ID,
+			HoldID:     h.ID,
+			RoomTypeID: h.RoomTypeID,
+			CheckIn:    h.CheckIn,
+			CheckOut:   h.CheckOut,
+			ExpiresAt:  h.ExpiresAt,
+		}
+
+		if _, err := obStore.Create(ctx, outbox.KindWaitlistOffer, e.UserID, wo, now); err != nil {
+			return fmt.Errorf("create message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// settleOffer moves the waitlist entry that was offered the hold, if any, to
+// status inside tx.
+func (c *Core) settleOffer(ctx context.Context, tx sqlx.ExtContext, holdID uuid.UUID, status string, now time.Time) error {
+	wlStore := c.Waitlist.Tran(tx)
+
+	e, err := wlStore.QueryByHoldIDForUpdate(ctx, holdID)
+	if err != nil {
+		if errors.Is(err, waitlist.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("querybyholdid: %w", err)
+	}
+
+	e.Status = status
+	e.DateUpdated = now
+
+	if err := wlStore.Update(ctx, e); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
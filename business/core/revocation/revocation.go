@@ -0,0 +1,214 @@
+// Package revocation provides the core business API for revoking access
+// tokens before they expire. Every authenticated request checks its token,
+// so the revocations are served from memory and reloaded from the database
+// every CacheTTL. A revocation made by another instance of the service takes
+// effect here at the next reload.
+package revocation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/revocation"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"go.uber.org/zap"
+)
+
+var ErrNoTokenID = errors.New("token has no id")
+
+// CacheTTL is how long the revocations are served from memory.
+const CacheTTL = 15 * time.Second
+
+type Core struct {
+	log   *zap.SugaredLogger
+	Store revocation.Store
+
+	// load serializes reloads with revocations, so a reload that started
+	// before a revocation cannot drop it from memory.
+	load sync.Mutex
+
+	mu      sync.RWMutex
+	loaded  time.Time
+	revoked map[string]struct{}
+	cutoffs map[uuid.UUID]time.Time
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:     log,
+		Store:   *revocation.NewStore(log, db),
+		revoked: make(map[string]struct{}),
+		cutoffs: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Revoked returns auth.ErrRevoked when the token was revoked itself or was
+// not issued after the cutoff of its user. Tokens without an id cannot be
+// revoked one at a time, so they are refused.
+func (c *Core) Revoked(ctx context.Context, claims auth.Claims) error {
+	if claims.ID == "" {
+		return fmt.Errorf("%w: %w", auth.ErrRevoked, ErrNoTokenID)
+	}
+
+	if err := c.refresh(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, ok := c.revoked[claims.ID]; ok {
+		return auth.ErrRevoked
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil
+	}
+
+	cutoff, ok := c.cutoffs[userID]
+	if !ok {
+		return nil
+	}
+
+	if claims.IssuedAt == nil || !claims.IssuedAt.Time.After(cutoff) {
+		return auth.ErrRevoked
+	}
+
+	return nil
+}
+
+// RevokeToken revokes the token the claims came from.
+func (c *Core) RevokeToken(ctx context.Context, claims auth.Claims, now time.Time) error {
+	if claims.ID == "" {
+		return ErrNoTokenID
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("parsing subject[%s]: %w", claims.Subject, err)
+	}
+
+	expires := now
+	if claims.ExpiresAt != nil {
+		expires = claims.ExpiresAt.Time
+	}
+
+	r := revocation.Revoked{
+		ID:          claims.ID,
+		UserID:      userID,
+		ExpiresAt:   expires,
+		DateCreated: now,
+	}
+
+	c.load.Lock()
+	defer c.load.Unlock()
+
+	if err := c.Store.DeleteExpired(ctx, now); err != nil {
+		return fmt.Errorf("deleteexpired: %w", err)
+	}
+
+	if err := c.Store.Revoke(ctx, r); err != nil {
+		return fmt.Errorf("revoke: tokenID[%s]: %w", r.ID, err)
+	}
+
+	c.mu.Lock()
+	c.revoked[r.ID] = struct{}{}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// RevokeUser revokes every token the user was issued up to now. Tokens record
+// when they were issued to the second, so the cutoff is too, and every token
+// issued within the same second is revoked with it: a user who logs in again
+// in that second has to get another token in the next one.
+func (c *Core) RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	cut := revocation.Cutoff{
+		UserID:     userID,
+		ValidAfter: now.UTC().Truncate(time.Second),
+	}
+
+	c.load.Lock()
+	defer c.load.Unlock()
+
+	if err := c.Store.CutOff(ctx, cut); err != nil {
+		return fmt.Errorf("cutoff: %w", err)
+	}
+
+	c.mu.Lock()
+	if cut.ValidAfter.After(c.cutoffs[userID]) {
+		c.cutoffs[userID] = cut.ValidAfter
+	}
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Load replaces the revocations in memory with those in the database.
+func (c *Core) Load(ctx context.Context, now time.Time) error {
+	c.load.Lock()
+	defer c.load.Unlock()
+
+	return c.reload(ctx, now)
+}
+
+// refresh reloads the revocations once they are older than CacheTTL.
+func (c *Core) refresh(ctx context.Context, now time.Time) error {
+	if c.fresh(now) {
+		return nil
+	}
+
+	c.load.Lock()
+	defer c.load.Unlock()
+
+	if c.fresh(now) {
+		return nil
+	}
+
+	return c.reload(ctx, now)
+}
+
+func (c *Core) fresh(now time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return now.Sub(c.loaded) < CacheTTL
+}
+
+// reload must be called holding c.load.
+func (c *Core) reload(ctx context.Context, now time.Time) error {
+	rs, err := c.Store.QueryRevoked(ctx, now)
+	if err != nil {
+		return fmt.Errorf("queryrevoked: %w", err)
+	}
+
+	cs, err := c.Store.QueryCutoffs(ctx)
+	if err != nil {
+		return fmt.Errorf("querycutoffs: %w", err)
+	}
+
+	revoked := make(map[string]struct{}, len(rs))
+	for _, r := range rs {
+		revoked[r.ID] = struct{}{}
+	}
+
+	cutoffs := make(map[uuid.UUID]time.Time, len(cs))
+	for _, cut := range cs {
+		cutoffs[cut.UserID] = cut.ValidAfter
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.revoked = revoked
+	c.cutoffs = cutoffs
+	c.loaded = now
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package revocation_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	revcore "github.com/tcmhoang/sservices/business/core/revocation"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestRevocation(t *testing.T) {
+	t.Run("revoke", revoke)
+}
+
+const userID = "45b5fbd3-755f-4379-8f07-a58d4a30fa2f"
+
+func claims(issued time.Time) auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(issued.Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(issued),
+		},
+	}
+}
+
+func revoke(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := revcore.NewCore(stest.Log, stest.DB)
+	other := revcore.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to revoke access tokens before they expire.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen revoking tokens.", testID)
+		{
+			ctx := context.Background()
+			now := time.Now()
+
+			old := claims(now.Add(-time.Minute))
+			kept := claims(now.Add(-time.Minute))
+
+			if err := core.Revoked(ctx, old); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould accept a token nobody revoked : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould accept a token nobody revoked.", tests.Success, testID)
+
+			if err := core.Revoked(ctx, auth.Claims{}); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse a token without an id : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse a token without an id.", tests.Success, testID)
+
+			if err := core.RevokeToken(ctx, old, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to revoke a token : %s.", tests.Failed, testID, err)
+			}
+
+			if err := core.Revoked(ctx, old); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse a revoked token : %v.", tests.Failed, testID, err)
+			}
+			if err := core.Revoked(ctx, kept); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould still accept the other tokens : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse only the revoked token.", tests.Success, testID)
+
+			if err := other.Revoked(ctx, old); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse it on another instance once loaded : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse it on another instance once loaded.", tests.Success, testID)
+
+			if err := core.RevokeUser(ctx, uuid.MustParse(userID), now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to revoke the tokens of a user : %s.", tests.Failed, testID, err)
+			}
+
+			if err := core.Revoked(ctx, kept); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse a token issued before the cutoff : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse a token issued before the cutoff.", tests.Success, testID)
+
+			if err := core.Revoked(ctx, claims(now)); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould refuse a token issued in the second of the cutoff : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse a token issued in the second of the cutoff.", tests.Success, testID)
+
+			if err := core.Revoked(ctx, claims(now.Add(time.Second))); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould accept a token issued after the cutoff : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould accept a token issued after the cutoff.", tests.Success, testID)
+
+			if err := other.Load(ctx, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to reload : %s.", tests.Failed, testID, err)
+			}
+			if err := other.Revoked(ctx, kept); !errors.Is(err, auth.ErrRevoked) {
+				t.Fatalf("\t%s\tTest %d:\tShould see the cutoff on another instance after a reload : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould see the cutoff on another instance after a reload.", tests.Success, testID)
+		}
+	}
+}
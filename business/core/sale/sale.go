@@ -0,0 +1,222 @@
+// Package sale provides the core business API for recording sales.
+package sale
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/sale"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+// OversellError is returned when a sale asks for more units than the product
+// has in stock.
+type OversellError struct {
+	ProductID uuid.UUID
+	Requested int
+	Available int
+}
+
+func (e *OversellError) Error() string {
+	return fmt.Sprintf("product %s has %d units in stock, %d requested", e.ProductID, e.Available, e.Requested)
+}
+
+type Core struct {
+	log         *zap.SugaredLogger
+	db          *sqlx.DB
+	Store       sale.Store
+	Transaction transaction.Store
+	product     product.Store
+	payment     payment.Gateway
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB, gw payment.Gateway) *Core {
+	return &Core{
+		log:         log,
+		db:          db,
+		Store:       *sale.NewStore(log, db),
+		Transaction: *transaction.NewStore(log, db),
+		product:     *product.NewStore(log, db),
+		payment:     gw,
+	}
+}
+
+// Create records a sale and takes the sold units out of stock. Both writes
+// happen in the same transaction with the product row locked, so concurrent
+// sales can never push the quantity below zero. The buyer is charged on the
+// payment source right away: the amount is authorized and captured, and a
+// decline rolls the sale back as a *payment.DeclineError.
+//
+// The gateway requests are keyed by the buyer's request key, so a retried
+// request is never charged twice: once the sale is recorded, retrying returns
+// it as it is. When the sale is rolled back after the gateway moved money,
+// the money is given back, and the request key stays failed like any request
+// the gateway already answered.
+func (c *Core) Create(ctx context.Context, ns sale.NewSale) (sale.Sale, error) {
+	if err := validation.Check(ns); err != nil {
+		return sale.Sale{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	key := requestKey(ns)
+
+	var sl sale.Sale
+	var chg charged
+
+	tran := func(tx sqlx.ExtContext) error {
+		prdStore := c.product.Tran(tx)
+		saleStore := c.Store.Tran(tx)
+		tStore := c.Transaction.Tran(tx)
+
+		prd, err := prdStore.QueryByIDForUpdate(ctx, ns.ProductID)
+		if err != nil {
+			return fmt.Errorf("querybyid: productID[%s]: %w", ns.ProductID, err)
+		}
+
+		cpt, err := tStore.QueryByIdempotencyKey(ctx, idempotencyKey(key, transaction.KindCapture))
+		switch {
+		case err == nil && cpt.SaleID != nil:
+			if sl, err = saleStore.QueryByID(ctx, *cpt.SaleID); err != nil {
+				return fmt.Errorf("querybyid: saleID[%s]: %w", *cpt.SaleID, err)
+			}
+			return nil
+		case err != nil && !errors.Is(err, transaction.ErrNotFound):
+			return fmt.Errorf("querybyidempotencykey: %w", err)
+		}
+
+		if prd.Quantity < ns.Quantity {
+			return &OversellError{
+				ProductID: prd.ID,
+				Requested: ns.Quantity,
+				Available: prd.Quantity,
+			}
+		}
+
+		if err := prdStore.UpdateQuantity(ctx, prd.ID, -ns.Quantity); err != nil {
+			return fmt.Errorf("updatequantity: %w", err)
+		}
+
+		sl = sale.Sale{
+			ID:          uuid.New(),
+			UserID:      ns.UserID,
+			ProductID:   prd.ID,
+			Quantity:    ns.Quantity,
+			Paid:        prd.Cost.Mul(ns.Quantity),
+			DateCreated: time.Now(),
+		}
+
+		if err := saleStore.Create(ctx, sl); err != nil {
+			return fmt.Errorf("create: %w", err)
+		}
+
+		return c.charge(ctx, tx, sl, ns.PaymentSource, key, &chg)
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		if rerr := c.reverse(ctx, sl, key, chg); rerr != nil {
+			return sale.Sale{}, fmt.Errorf("%w: %w", err, rerr)
+		}
+		return sale.Sale{}, err
+	}
+
+	return sl, nil
+}
+
+// charged holds the gateway references of what charge moved for a sale.
+type charged struct {
+	authorization string
+	capture       string
+}
+
+// charge authorizes and captures what the sale costs on the payment source
+// inside tx and records both transactions. It notes in chg what the gateway
+// accepted, so it can be given back when the sale is rolled back. Free sales
+// are not sent to the gateway.
+func (c *Core) charge(ctx context.Context, tx sqlx.ExtContext, sl sale.Sale, source string, key string, chg *charged) error {
+	if sl.Paid.IsZero() {
+		return nil
+	}
+
+	akey := idempotencyKey(key, transaction.KindAuthorize)
+	auth, err := c.payment.Authorize(ctx, akey, source, sl.Paid.Amount, sl.Paid.Currency)
+	if err != nil {
+		return fmt.Errorf("authorize: %w", err)
+	}
+	chg.authorization = auth.ID
+
+	if err := c.record(ctx, tx, sl, transaction.KindAuthorize, auth, akey); err != nil {
+		return err
+	}
+
+	ckey := idempotencyKey(key, transaction.KindCapture)
+	cpt, err := c.payment.Capture(ctx, ckey, auth.ID, sl.Paid.Amount)
+	if err != nil {
+		return fmt.Errorf("capture: %w", err)
+	}
+	chg.capture = cpt.ID
+
+	return c.record(ctx, tx, sl, transaction.KindCapture, cpt, ckey)
+}
+
+// reverse gives back what the gateway moved for a sale that was rolled back:
+// the capture is refunded, or else the authorization is voided.
+func (c *Core) reverse(ctx context.Context, sl sale.Sale, key string, chg charged) error {
+	switch {
+	case chg.capture != "":
+		if _, err := c.payment.Refund(ctx, idempotencyKey(key, transaction.KindRefund), chg.capture, sl.Paid.Amount); err != nil {
+			return fmt.Errorf("refund: captureID[%s]: %w", chg.capture, err)
+		}
+	case chg.authorization != "":
+		if _, err := c.payment.Void(ctx, idempotencyKey(key, transaction.KindVoid), chg.authorization); err != nil {
+			return fmt.Errorf("void: authorizationID[%s]: %w", chg.authorization, err)
+		}
+	}
+
+	return nil
+}
+
+// record stores what the gateway answered for the sale inside tx.
+func (c *Core) record(ctx context.Context, tx sqlx.ExtContext, sl sale.Sale, kind string, pr payment.Result, key string) error {
+	tStore := c.Transaction.Tran(tx)
+
+	t := transaction.Transaction{
+		ID:             uuid.New(),
+		SaleID:         &sl.ID,
+		Kind:           kind,
+		Amount:         pr.Amount,
+		Currency:       sl.Paid.Currency,
+		GatewayRef:     pr.ID,
+		IdempotencyKey: key,
+		UserID:         sl.UserID,
+		DateCreated:    sl.DateCreated,
+	}
+
+	if err := tStore.Create(ctx, t); err != nil {
+		return fmt.Errorf("create transaction: %w", err)
+	}
+
+	return nil
+}
+
+// requestKey names the purchase the buyer asked for with their request key.
+// The gateway replays its answer to a key it already saw, declines included,
+// so the key changes with the source to let the buyer retry with another
+// card. The source is hashed to keep card tokens out of the stored keys.
+func requestKey(ns sale.NewSale) string {
+	sum := sha256.Sum256([]byte(ns.PaymentSource))
+	return fmt.Sprintf("sale/%s/%s/%x", ns.UserID, ns.RequestKey, sum[:8])
+}
+
+// idempotencyKey names a gateway request of the purchase.
+func idempotencyKey(key string, kind string) string {
+	return fmt.Sprintf("%s/%s", key, kind)
+}
@@ -0,0 +1,201 @@
+package sale_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+
+	"github.com/google/uuid"
+
+	salecore "github.com/tcmhoang/sservices/business/core/sale"
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/store/sale"
+	"github.com/tcmhoang/sservices/business/data/store/transaction"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/payment"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestSale(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := salecore.NewCore(stest.Log, stest.DB, payment.NewFake())
+	prdStore := product.NewStore(stest.Log, stest.DB)
+
+	userID := uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+
+	t.Log("Given the need to record Sale records.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen selling a product in stock.", testID)
+		{
+			ctx := context.Background()
+
+			prd, err := prdStore.Create(ctx, product.NewProduct{
+				Name:     "Lamp",
+				Cost:     money.New(30, "USD"),
+				Quantity: 5,
+				UserID:   userID,
+			})
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a product.", tests.Success, testID)
+
+			_, err = core.Create(ctx, sale.NewSale{
+				ProductID:     prd.ID,
+				Quantity:      1,
+				UserID:        userID,
+				PaymentSource: payment.SourceDeclined,
+				RequestKey:    "lamp-1",
+			})
+			var de *payment.DeclineError
+			if !errors.As(err, &de) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT record a sale the gateway declined : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT record a sale the gateway declined.", tests.Success, testID)
+
+			ns := sale.NewSale{
+				ProductID:     prd.ID,
+				Quantity:      3,
+				UserID:        userID,
+				PaymentSource: payment.SourceApproved,
+				RequestKey:    "lamp-1",
+			}
+
+			sl, err := core.Create(ctx, ns)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to record a sale : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to record a sale.", tests.Success, testID)
+
+			if sl.Paid != money.New(90, "USD") {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, sl.Paid)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, money.New(90, "USD"))
+				t.Fatalf("\t%s\tTest %d:\tShould charge cost times quantity.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould charge cost times quantity.", tests.Success, testID)
+
+			ts, err := core.Transaction.QueryBySaleID(ctx, sl.ID)
+			if err != nil || len(ts) != 2 || ts[1].Kind != transaction.KindCapture || ts[1].Amount != 90 {
+				t.Fatalf("\t%s\tTest %d:\tShould authorize and capture the sale : %+v %v.", tests.Failed, testID, ts, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould authorize and capture the sale.", tests.Success, testID)
+
+			saved, err := prdStore.QueryByID(ctx, prd.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the product : %s.", tests.Failed, testID, err)
+			}
+
+			if saved.Quantity != 2 {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, saved.Quantity)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, 2)
+				t.Fatalf("\t%s\tTest %d:\tShould decrement the quantity in stock.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould decrement the quantity in stock.", tests.Success, testID)
+
+			retried, err := core.Create(ctx, ns)
+			if err != nil || retried.ID != sl.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould return the recorded sale to a retried request : %+v %v.", tests.Failed, testID, retried, err)
+			}
+
+			ts, err = core.Transaction.QueryBySaleID(ctx, sl.ID)
+			if err != nil || len(ts) != 2 {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT charge a retried request again : %+v %v.", tests.Failed, testID, ts, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould return the recorded sale to a retried request.", tests.Success, testID)
+
+			_, err = core.Create(ctx, sale.NewSale{
+				ProductID:     prd.ID,
+				Quantity:      3,
+				UserID:        userID,
+				PaymentSource: payment.SourceApproved,
+				RequestKey:    "lamp-2",
+			})
+			var oerr *salecore.OversellError
+			if !errors.As(err, &oerr) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to oversell : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to oversell.", tests.Success, testID)
+
+			saved, err = prdStore.QueryByID(ctx, prd.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the product : %s.", tests.Failed, testID, err)
+			}
+
+			if saved.Quantity != 2 {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, saved.Quantity)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, 2)
+				t.Fatalf("\t%s\tTest %d:\tShould leave the stock untouched on a rejected sale.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould leave the stock untouched on a rejected sale.", tests.Success, testID)
+
+			gw := failingCapture{Fake: payment.NewFake()}
+			failing := salecore.NewCore(stest.Log, stest.DB, &gw)
+
+			if _, err := failing.Create(ctx, sale.NewSale{
+				ProductID:     prd.ID,
+				Quantity:      1,
+				UserID:        userID,
+				PaymentSource: payment.SourceApproved,
+				RequestKey:    "lamp-3",
+			}); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT record a sale that could not be captured.", tests.Failed, testID)
+			}
+
+			if len(gw.voided) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould void the authorization of a sale that could not be captured : %v.", tests.Failed, testID, gw.voided)
+			}
+			t.Logf("\t%s\tTest %d:\tShould void the authorization of a sale that could not be captured.", tests.Success, testID)
+
+			sls, err := core.Store.QueryByProductID(ctx, prd.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to list sales by product : %s.", tests.Failed, testID, err)
+			}
+
+			if len(sls) != 1 || sls[0].ID != sl.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould have only the accepted sale recorded : %v.", tests.Failed, testID, sls)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have only the accepted sale recorded.", tests.Success, testID)
+		}
+	}
+}
+
+// failingCapture is a gateway that cannot capture and notes what it voided.
+type failingCapture struct {
+	*payment.Fake
+	voided []string
+}
+
+func (g *failingCapture) Capture(ctx context.Context, key string, authorizationID string, amount int) (payment.Result, error) {
+	return payment.Result{}, errors.New("gateway unavailable")
+}
+
+func (g *failingCapture) Void(ctx context.Context, key string, authorizationID string) (payment.Result, error) {
+	g.voided = append(g.voided, authorizationID)
+	return g.Fake.Void(ctx, key, authorizationID)
+}
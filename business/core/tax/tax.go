@@ -0,0 +1,148 @@
+// Package tax provides the core business API for the taxes and fees charged
+// on stays. The arithmetic is pure and works on whole amounts of money, so
+// every rounding decision is explicit.
+package tax
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/store/taxrule"
+	"go.uber.org/zap"
+)
+
+// basisPoints is what a PERCENT rule's amount is a fraction of.
+const basisPoints = 10000
+
+// Charge is what one rule charges on a stay.
+type Charge struct {
+	RuleID   uuid.UUID `json:"ruleID"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	Amount   int       `json:"amount"`
+}
+
+// Calculate works out what each rule charges on a stay of guests paying the
+// given nightly rates. PERCENT rules apply to the room charges of the whole
+// stay and round once, the other kinds are whole amounts already. Rules that
+// charge nothing are left out.
+func Calculate(rules []taxrule.Rule, rates []int, guests int) []Charge {
+	room := 0
+	for _, r := range rates {
+		room += r
+	}
+	nights := len(rates)
+
+	cs := make([]Charge, 0, len(rules))
+	for _, r := range rules {
+		var amount int
+
+		switch r.Kind {
+		case taxrule.KindPercent:
+			amount = Round(room*r.Amount, basisPoints, r.Rounding)
+		case taxrule.KindPerPersonNight:
+			amount = r.Amount * guests * nights
+		case taxrule.KindPerNight:
+			amount = r.Amount * nights
+		case taxrule.KindPerStay:
+			amount = r.Amount
+		}
+
+		if amount == 0 {
+			continue
+		}
+
+		cs = append(cs, Charge{
+			RuleID:   r.ID,
+			Name:     r.Name,
+			Category: r.Category,
+			Amount:   amount,
+		})
+	}
+
+	return cs
+}
+
+// Total adds up the charges.
+func Total(cs []Charge) int {
+	total := 0
+	for _, c := range cs {
+		total += c.Amount
+	}
+	return total
+}
+
+// Round divides n by d, both at least zero, rounding the way mode says.
+// Unknown modes round half up.
+func Round(n int, d int, mode string) int {
+	q, r := n/d, n%d
+	if r == 0 {
+		return q
+	}
+
+	switch mode {
+	case taxrule.RoundDown:
+		return q
+	case taxrule.RoundUp:
+		return q + 1
+	case taxrule.RoundHalfEven:
+		if 2*r > d || (2*r == d && q%2 == 1) {
+			return q + 1
+		}
+		return q
+	default:
+		if 2*r >= d {
+			return q + 1
+		}
+		return q
+	}
+}
+
+type Core struct {
+	log         *zap.SugaredLogger
+	Store       taxrule.Store
+	reservation reservation.Store
+}
+
+func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
+	return &Core{
+		log:         log,
+		Store:       *taxrule.NewStore(log, db),
+		reservation: *reservation.NewStore(log, db),
+	}
+}
+
+// Stay works out the taxes and fees of a stay at the property.
+func (c *Core) Stay(ctx context.Context, propertyID uuid.UUID, rates []int, guests int) ([]Charge, error) {
+	return stay(ctx, c.Store, propertyID, rates, guests)
+}
+
+// Reservation works out the taxes and fees of a reservation inside tx, from
+// the nightly prices it was booked at.
+func (c *Core) Reservation(ctx context.Context, tx sqlx.ExtContext, res reservation.Reservation) ([]Charge, error) {
+	resStore := c.reservation.Tran(tx)
+
+	nights, err := resStore.QueryNights(ctx, res.ID)
+	if err != nil {
+		return nil, fmt.Errorf("querynights: %w", err)
+	}
+
+	rates := make([]int, len(nights))
+	for i, n := range nights {
+		rates[i] = n.Rate
+	}
+
+	return stay(ctx, c.Store.Tran(tx), res.PropertyID, rates, res.Guests)
+}
+
+func stay(ctx context.Context, store taxrule.Store, propertyID uuid.UUID, rates []int, guests int) ([]Charge, error) {
+	rules, err := store.QueryByPropertyID(ctx, propertyID)
+	if err != nil {
+		return nil, fmt.Errorf("querybypropertyid: %w", err)
+	}
+
+	return Calculate(rules, rates, guests), nil
+}
@@ -0,0 +1,229 @@
+package tax_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/core/tax"
+	"github.com/tcmhoang/sservices/business/data/store/taxrule"
+	"github.com/tcmhoang/sservices/business/data/tests"
+)
+
+func TestRound(t *testing.T) {
+	table := []struct {
+		name string
+		n    int
+		d    int
+		mode string
+		exp  int
+	}{
+		{"exact half up", 20000, 10000, taxrule.RoundHalfUp, 2},
+		{"exact half even", 20000, 10000, taxrule.RoundHalfEven, 2},
+		{"exact up", 20000, 10000, taxrule.RoundUp, 2},
+		{"exact down", 20000, 10000, taxrule.RoundDown, 2},
+		{"zero", 0, 10000, taxrule.RoundUp, 0},
+
+		{"below half, half up", 24999, 10000, taxrule.RoundHalfUp, 2},
+		{"below half, half even", 24999, 10000, taxrule.RoundHalfEven, 2},
+		{"below half, up", 24999, 10000, taxrule.RoundUp, 3},
+		{"below half, down", 24999, 10000, taxrule.RoundDown, 2},
+
+		{"half to odd, half up", 15000, 10000, taxrule.RoundHalfUp, 2},
+		{"half to odd, half even", 15000, 10000, taxrule.RoundHalfEven, 2},
+		{"half to odd, up", 15000, 10000, taxrule.RoundUp, 2},
+		{"half to odd, down", 15000, 10000, taxrule.RoundDown, 1},
+
+		{"half to even, half up", 25000, 10000, taxrule.RoundHalfUp, 3},
+		{"half to even, half even", 25000, 10000, taxrule.RoundHalfEven, 2},
+		{"half to even, up", 25000, 10000, taxrule.RoundUp, 3},
+		{"half to even, down", 25000, 10000, taxrule.RoundDown, 2},
+
+		{"above half, half up", 25001, 10000, taxrule.RoundHalfUp, 3},
+		{"above half, half even", 25001, 10000, taxrule.RoundHalfEven, 3},
+		{"above half, up", 25001, 10000, taxrule.RoundUp, 3},
+		{"above half, down", 25001, 10000, taxrule.RoundDown, 2},
+
+		{"smallest fraction, up", 1, 10000, taxrule.RoundUp, 1},
+		{"smallest fraction, half up", 1, 10000, taxrule.RoundHalfUp, 0},
+		{"half of one, half up", 5000, 10000, taxrule.RoundHalfUp, 1},
+		{"half of one, half even", 5000, 10000, taxrule.RoundHalfEven, 0},
+		{"unknown mode rounds half up", 5000, 10000, "", 1},
+	}
+
+	t.Log("Given the need to round money to whole amounts.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got := tax.Round(tt.n, tt.d, tt.mode)
+
+				if got != tt.exp {
+					t.Logf("\t\tTest %d:\tGot: %d", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %d", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould round %d/%d %s.", tests.Failed, testID, tt.n, tt.d, tt.mode)
+				}
+				t.Logf("\t%s\tTest %d:\tShould round %d/%d %s.", tests.Success, testID, tt.n, tt.d, tt.mode)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	rule := func(name, category, kind string, amount int, rounding string) taxrule.Rule {
+		return taxrule.Rule{
+			ID:       uuid.New(),
+			Name:     name,
+			Category: category,
+			Kind:     kind,
+			Amount:   amount,
+			Rounding: rounding,
+		}
+	}
+
+	vat := rule("VAT", taxrule.CategoryTax, taxrule.KindPercent, 1000, taxrule.RoundHalfUp)
+	vatEven := rule("VAT", taxrule.CategoryTax, taxrule.KindPercent, 750, taxrule.RoundHalfEven)
+	vatDown := rule("VAT", taxrule.CategoryTax, taxrule.KindPercent, 825, taxrule.RoundDown)
+	vatUp := rule("VAT", taxrule.CategoryTax, taxrule.KindPercent, 825, taxrule.RoundUp)
+	city := rule("City tax", taxrule.CategoryTax, taxrule.KindPerPersonNight, 3, taxrule.RoundHalfUp)
+	resort := rule("Resort fee", taxrule.CategoryFee, taxrule.KindPerNight, 25, taxrule.RoundHalfUp)
+	cleaning := rule("Cleaning fee", taxrule.CategoryFee, taxrule.KindPerStay, 40, taxrule.RoundHalfUp)
+	free := rule("Tourism levy", taxrule.CategoryTax, taxrule.KindPercent, 0, taxrule.RoundHalfUp)
+
+	table := []struct {
+		name   string
+		rules  []taxrule.Rule
+		rates  []int
+		guests int
+		exp    []int
+	}{
+		{
+			name:   "no rules",
+			rates:  []int{100, 100},
+			guests: 2,
+			exp:    []int{},
+		},
+		{
+			name:   "percentage of the whole stay",
+			rules:  []taxrule.Rule{vat},
+			rates:  []int{100, 150, 150},
+			guests: 1,
+			exp:    []int{40},
+		},
+		{
+			name:   "percentage rounds once for the stay",
+			rules:  []taxrule.Rule{vat},
+			rates:  []int{105, 105, 105},
+			guests: 1,
+			exp:    []int{32},
+		},
+		{
+			name:   "percentage at a half rounds up to even",
+			rules:  []taxrule.Rule{vatEven},
+			rates:  []int{100},
+			guests: 1,
+			exp:    []int{8},
+		},
+		{
+			name:   "percentage at a half rounds down to even",
+			rules:  []taxrule.Rule{vatEven},
+			rates:  []int{140},
+			guests: 1,
+			exp:    []int{10},
+		},
+		{
+			name:   "percentage rounds down",
+			rules:  []taxrule.Rule{vatDown},
+			rates:  []int{199},
+			guests: 1,
+			exp:    []int{16},
+		},
+		{
+			name:   "percentage rounds up",
+			rules:  []taxrule.Rule{vatUp},
+			rates:  []int{199},
+			guests: 1,
+			exp:    []int{17},
+		},
+		{
+			name:   "per person per night",
+			rules:  []taxrule.Rule{city},
+			rates:  []int{100, 100, 100},
+			guests: 2,
+			exp:    []int{18},
+		},
+		{
+			name:   "per night",
+			rules:  []taxrule.Rule{resort},
+			rates:  []int{100, 100, 100},
+			guests: 4,
+			exp:    []int{75},
+		},
+		{
+			name:   "per stay",
+			rules:  []taxrule.Rule{cleaning},
+			rates:  []int{100, 100, 100},
+			guests: 4,
+			exp:    []int{40},
+		},
+		{
+			name:   "every kind together in rule order",
+			rules:  []taxrule.Rule{vat, city, resort, cleaning},
+			rates:  []int{120, 80},
+			guests: 3,
+			exp:    []int{20, 18, 50, 40},
+		},
+		{
+			name:   "free stay still pays per guest and flat charges",
+			rules:  []taxrule.Rule{vat, city, cleaning},
+			rates:  []int{0, 0},
+			guests: 2,
+			exp:    []int{12, 40},
+		},
+		{
+			name:   "rules that charge nothing are left out",
+			rules:  []taxrule.Rule{free, vat},
+			rates:  []int{100},
+			guests: 1,
+			exp:    []int{10},
+		},
+	}
+
+	t.Log("Given the need to charge taxes and fees on stays.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				cs := tax.Calculate(tt.rules, tt.rates, tt.guests)
+
+				got := make([]int, len(cs))
+				for i, c := range cs {
+					got[i] = c.Amount
+				}
+
+				if len(got) != len(tt.exp) {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould charge each rule.", tests.Failed, testID)
+				}
+
+				total := 0
+				for i := range got {
+					if got[i] != tt.exp[i] {
+						t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+						t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+						t.Fatalf("\t%s\tTest %d:\tShould charge each rule.", tests.Failed, testID)
+					}
+					total += tt.exp[i]
+				}
+				t.Logf("\t%s\tTest %d:\tShould charge each rule.", tests.Success, testID)
+
+				if tax.Total(cs) != total {
+					t.Fatalf("\t%s\tTest %d:\tShould total the charges : got %d, exp %d.", tests.Failed, testID, tax.Total(cs), total)
+				}
+				t.Logf("\t%s\tTest %d:\tShould total the charges.", tests.Success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
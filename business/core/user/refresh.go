@@ -0,0 +1,167 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/refresh"
+	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+var (
+	ErrInvalidRefresh = errors.New("refresh token is invalid or expired")
+	ErrRefreshReused  = errors.New("refresh token was already used, every token of its login is revoked")
+)
+
+// RefreshTTL is how long a refresh token can be traded for the next one.
+const RefreshTTL = 30 * 24 * time.Hour
+
+// IssueRefresh starts a new family of refresh tokens for the user, as at a
+// login, and returns its first token. Only a hash of the token is stored.
+func (c *Core) IssueRefresh(ctx context.Context, userID uuid.UUID, now time.Time) (string, error) {
+	return c.issue(ctx, c.refresh, userID, uuid.New(), now)
+}
+
+// Refresh trades a refresh token for the next one of its family and returns
+// the user it belongs to. A token is traded once, and never by a disabled
+// user. Presenting one that was already traded means it leaked, so its whole
+// family is revoked, the token the legitimate client holds included.
+func (c *Core) Refresh(ctx context.Context, rf refresh.Refresh, now time.Time) (user.User, string, error) {
+	if err := validation.Check(rf); err != nil {
+		return user.User{}, "", fmt.Errorf("validating data: %w", err)
+	}
+
+	var usr user.User
+	var raw string
+	var reused bool
+
+	tran := func(tx sqlx.ExtContext) error {
+		rfStore := c.refresh.Tran(tx)
+
+		t, err := rfStore.QueryByHashForUpdate(ctx, hash(rf.Token))
+		if err != nil {
+			if errors.Is(err, refresh.ErrNotFound) {
+				return ErrInvalidRefresh
+			}
+			return fmt.Errorf("querybyhash: %w", err)
+		}
+
+		if t.DateRevoked != nil || !t.ExpiresAt.After(now) {
+			return ErrInvalidRefresh
+		}
+
+		if t.DateUsed != nil {
+			reused = true
+			if err := rfStore.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+				return fmt.Errorf("revokefamily: %w", err)
+			}
+			return nil
+		}
+
+		usr, err = c.Store.QueryByID(ctx, t.UserID)
+		if err != nil {
+			return fmt.Errorf("querybyid: userID[%s]: %w", t.UserID, err)
+		}
+
+		if !usr.Enabled {
+			return ErrDisabled
+		}
+
+		t.DateUsed = &now
+		if err := rfStore.MarkUsed(ctx, t); err != nil {
+			return fmt.Errorf("markused: %w", err)
+		}
+
+		raw, err = c.issue(ctx, rfStore, t.UserID, t.FamilyID, now)
+		return err
+	}
+
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return user.User{}, "", err
+	}
+
+	if reused {
+		return user.User{}, "", ErrRefreshReused
+	}
+
+	return usr, raw, nil
+}
+
+// Logout revokes the family of the refresh token, ending that login. Unknown
+// tokens are ignored.
+func (c *Core) Logout(ctx context.Context, rf refresh.Refresh, now time.Time) error {
+	if err := validation.Check(rf); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	tran := func(tx sqlx.ExtContext) error {
+		rfStore := c.refresh.Tran(tx)
+
+		t, err := rfStore.QueryByHashForUpdate(ctx, hash(rf.Token))
+		if err != nil {
+			if errors.Is(err, refresh.ErrNotFound) {
+				return nil
+			}
+			return fmt.Errorf("querybyhash: %w", err)
+		}
+
+		if err := rfStore.RevokeFamily(ctx, t.FamilyID, now); err != nil {
+			return fmt.Errorf("revokefamily: %w", err)
+		}
+
+		return nil
+	}
+
+	return database.WithinTran(ctx, c.log, c.db, tran)
+}
+
+// LogoutAll revokes every refresh token of the user, ending all their logins.
+func (c *Core) LogoutAll(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	if err := c.refresh.RevokeUser(ctx, userID, now); err != nil {
+		return fmt.Errorf("revokeuser: %w", err)
+	}
+
+	return nil
+}
+
+// issue stores a new refresh token of the family and returns what the client
+// holds.
+func (c *Core) issue(ctx context.Context, store refresh.Store, userID uuid.UUID, familyID uuid.UUID, now time.Time) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating refresh token: %w", err)
+	}
+	raw := base64.RawURLEncoding.EncodeToString(b)
+
+	t := refresh.Token{
+		ID:          uuid.New(),
+		FamilyID:    familyID,
+		UserID:      userID,
+		Hash:        hash(raw),
+		ExpiresAt:   now.Add(RefreshTTL),
+		DateCreated: now,
+	}
+
+	if err := store.Create(ctx, t); err != nil {
+		return "", fmt.Errorf("create: %w", err)
+	}
+
+	return raw, nil
+}
+
+// hash is what is stored of a refresh token. Tokens are random enough that a
+// plain SHA-256 cannot be reversed.
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -6,36 +6,123 @@ import (
 	"errors"
 	"fmt"
 	"net/mail"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/data/store/refresh"
 	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/sys/database"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var ErrAuthenticationFailure = errors.New("authentication failed")
+var (
+	ErrAuthenticationFailure = errors.New("authentication failed")
+	ErrDisabled              = errors.New("user is disabled")
+	ErrLocked                = errors.New("user is locked out after too many failed logins")
+)
+
+// Wrong passwords tolerated in a row before the account is locked out, and
+// for how long.
+const (
+	MaxFailedLogins = 5
+	LockoutTTL      = 15 * time.Minute
+)
 
 type Core struct {
-	log   *zap.SugaredLogger
-	Store user.Store
+	log     *zap.SugaredLogger
+	db      *sqlx.DB
+	Store   user.Store
+	refresh refresh.Store
 }
 
 func NewCore(log *zap.SugaredLogger, db *sqlx.DB) *Core {
 	return &Core{
-		log,
-		*user.NewStore(log, db),
+		log:     log,
+		db:      db,
+		Store:   *user.NewStore(log, db),
+		refresh: *refresh.NewStore(log, db),
 	}
 }
 
-func (c *Core) Authenticate(ctx context.Context, email mail.Address, password string) (user.User, error) {
-	usr, err := c.Store.QueryByEmail(ctx, email)
-	if err != nil {
-		return user.User{}, fmt.Errorf("query: %w", err)
+// Authenticate checks the password of the user with the email. While the
+// account is locked out no password is checked at all, so guessing gets no
+// further than MaxFailedLogins attempts every LockoutTTL. Disabled users are
+// told so only once they gave the right password.
+func (c *Core) Authenticate(ctx context.Context, email mail.Address, password string, now time.Time) (user.User, error) {
+	var usr user.User
+	var failed bool
+
+	tran := func(tx sqlx.ExtContext) error {
+		usrStore := c.Store.Tran(tx)
+
+		var err error
+		usr, err = usrStore.QueryByEmailForUpdate(ctx, email)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		if usr.LockedUntil != nil && usr.LockedUntil.After(now) {
+			return ErrLocked
+		}
+
+		if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+			failed = true
+
+			usr.FailedLogins++
+			usr.LockedUntil = nil
+			if usr.FailedLogins >= MaxFailedLogins {
+				until := now.Add(LockoutTTL)
+				usr.FailedLogins = 0
+				usr.LockedUntil = &until
+			}
+
+			if err := usrStore.UpdateLogins(ctx, usr); err != nil {
+				return fmt.Errorf("updatelogins: %w", err)
+			}
+			return nil
+		}
+
+		if usr.FailedLogins != 0 || usr.LockedUntil != nil {
+			usr.FailedLogins = 0
+			usr.LockedUntil = nil
+			if err := usrStore.UpdateLogins(ctx, usr); err != nil {
+				return fmt.Errorf("updatelogins: %w", err)
+			}
+		}
+
+		return nil
 	}
 
-	if err := bcrypt.CompareHashAndPassword(usr.PasswordHash, []byte(password)); err != nil {
+	if err := database.WithinTran(ctx, c.log, c.db, tran); err != nil {
+		return user.User{}, err
+	}
+
+	if failed {
 		return user.User{}, ErrAuthenticationFailure
 	}
 
+	if !usr.Enabled {
+		return user.User{}, ErrDisabled
+	}
+
+	return usr, nil
+}
+
+// Unlock ends the lockout of the user and forgets their failed logins.
+func (c *Core) Unlock(ctx context.Context, userID uuid.UUID) (user.User, error) {
+	usr, err := c.Store.QueryByID(ctx, userID)
+	if err != nil {
+		return user.User{}, fmt.Errorf("querybyid: %w", err)
+	}
+
+	usr.FailedLogins = 0
+	usr.LockedUntil = nil
+
+	if err := c.Store.UpdateLogins(ctx, usr); err != nil {
+		return user.User{}, fmt.Errorf("updatelogins: %w", err)
+	}
+
 	return usr, nil
 }
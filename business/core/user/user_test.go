@@ -0,0 +1,243 @@
+package user_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	usercore "github.com/tcmhoang/sservices/business/core/user"
+	"github.com/tcmhoang/sservices/business/data/store/refresh"
+	"github.com/tcmhoang/sservices/business/data/store/user"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestUser(t *testing.T) {
+	t.Run("refresh", refreshTokens)
+	t.Run("lockout", lockout)
+	t.Run("disabled", disabled)
+}
+
+var userID = uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+
+func refreshTokens(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := usercore.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to keep clients logged in with refresh tokens.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen rotating the refresh tokens of a login.", testID)
+		{
+			ctx := context.Background()
+			now := time.Now()
+
+			first, err := core.IssueRefresh(ctx, userID, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to issue a refresh token : %s.", tests.Failed, testID, err)
+			}
+
+			usr, second, err := core.Refresh(ctx, refresh.Refresh{Token: first}, now)
+			if err != nil || usr.ID != userID || second == first {
+				t.Fatalf("\t%s\tTest %d:\tShould trade the token for the next one : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould trade the token for the next one.", tests.Success, testID)
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: first}, now); !errors.Is(err, usercore.ErrRefreshReused) {
+				t.Fatalf("\t%s\tTest %d:\tShould detect a token used twice : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould detect a token used twice.", tests.Success, testID)
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: second}, now); !errors.Is(err, usercore.ErrInvalidRefresh) {
+				t.Fatalf("\t%s\tTest %d:\tShould revoke the whole family on reuse : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould revoke the whole family on reuse.", tests.Success, testID)
+
+			other, err := core.IssueRefresh(ctx, userID, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to issue a refresh token : %s.", tests.Failed, testID, err)
+			}
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: other}, now.Add(usercore.RefreshTTL)); !errors.Is(err, usercore.ErrInvalidRefresh) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT trade an expired token : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT trade an expired token.", tests.Success, testID)
+
+			if err := core.Logout(ctx, refresh.Refresh{Token: other}, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to log out : %s.", tests.Failed, testID, err)
+			}
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: other}, now); !errors.Is(err, usercore.ErrInvalidRefresh) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT trade a token after logout : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT trade a token after logout.", tests.Success, testID)
+
+			last, err := core.IssueRefresh(ctx, userID, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to issue a refresh token : %s.", tests.Failed, testID, err)
+			}
+
+			if err := core.LogoutAll(ctx, userID, now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to log out everywhere : %s.", tests.Failed, testID, err)
+			}
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: last}, now); !errors.Is(err, usercore.ErrInvalidRefresh) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT trade a token after logging out everywhere : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT trade a token after logging out everywhere.", tests.Success, testID)
+		}
+	}
+}
+
+func lockout(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := usercore.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to throttle guessing passwords.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen giving the wrong password again and again.", testID)
+		{
+			ctx := context.Background()
+			now := time.Now()
+			email := mail.Address{Address: "user@example.com"}
+
+			for i := 0; i < usercore.MaxFailedLogins; i++ {
+				if _, err := core.Authenticate(ctx, email, "guess", now); !errors.Is(err, usercore.ErrAuthenticationFailure) {
+					t.Fatalf("\t%s\tTest %d:\tShould refuse wrong password %d : %v.", tests.Failed, testID, i, err)
+				}
+			}
+			t.Logf("\t%s\tTest %d:\tShould refuse the wrong passwords.", tests.Success, testID)
+
+			if _, err := core.Authenticate(ctx, email, "gophers", now.Add(time.Minute)); !errors.Is(err, usercore.ErrLocked) {
+				t.Fatalf("\t%s\tTest %d:\tShould lock the account out, even for the right password : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould lock the account out, even for the right password.", tests.Success, testID)
+
+			if _, err := core.Authenticate(ctx, email, "gophers", now.Add(usercore.LockoutTTL)); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould let the right password in after the lockout : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould let the right password in after the lockout.", tests.Success, testID)
+
+			for i := 0; i < usercore.MaxFailedLogins-1; i++ {
+				if _, err := core.Authenticate(ctx, email, "guess", now); !errors.Is(err, usercore.ErrAuthenticationFailure) {
+					t.Fatalf("\t%s\tTest %d:\tShould refuse wrong password %d : %v.", tests.Failed, testID, i, err)
+				}
+			}
+
+			if _, err := core.Authenticate(ctx, email, "gophers", now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT lock out before the limit : %s.", tests.Failed, testID, err)
+			}
+
+			usr, err := core.Store.QueryByEmail(ctx, email)
+			if err != nil || usr.FailedLogins != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould forget the failed logins after a login : %d %v.", tests.Failed, testID, usr.FailedLogins, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould forget the failed logins after a login.", tests.Success, testID)
+
+			for i := 0; i < usercore.MaxFailedLogins; i++ {
+				core.Authenticate(ctx, email, "guess", now)
+			}
+
+			if _, err := core.Unlock(ctx, userID); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to unlock the account : %s.", tests.Failed, testID, err)
+			}
+
+			if _, err := core.Authenticate(ctx, email, "gophers", now); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould let the right password in once unlocked : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould let the right password in once unlocked.", tests.Success, testID)
+		}
+	}
+}
+
+func disabled(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	core := usercore.NewCore(stest.Log, stest.DB)
+
+	t.Log("Given the need to keep disabled users out.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen a user is disabled.", testID)
+		{
+			ctx := context.Background()
+			now := time.Now()
+			email := mail.Address{Address: "user@example.com"}
+
+			rf, err := core.IssueRefresh(ctx, userID, now)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to issue a refresh token : %s.", tests.Failed, testID, err)
+			}
+
+			usr, err := core.Store.QueryByID(ctx, userID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve the user : %s.", tests.Failed, testID, err)
+			}
+
+			enabled := false
+			if _, err := core.Store.Update(ctx, usr, user.UpdateUser{Enabled: &enabled, Department: tests.StringPointer("sales")}); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to disable the user : %s.", tests.Failed, testID, err)
+			}
+
+			usr, err = core.Store.QueryByID(ctx, userID)
+			if err != nil || usr.Enabled || usr.Department != "sales" {
+				t.Fatalf("\t%s\tTest %d:\tShould persist enabled and department : %+v %v.", tests.Failed, testID, usr, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould persist enabled and department.", tests.Success, testID)
+
+			if _, err := core.Authenticate(ctx, email, "gophers", now); !errors.Is(err, usercore.ErrDisabled) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT log a disabled user in : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT log a disabled user in.", tests.Success, testID)
+
+			if _, _, err := core.Refresh(ctx, refresh.Refresh{Token: rf}, now); !errors.Is(err, usercore.ErrDisabled) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT refresh the tokens of a disabled user : %v.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT refresh the tokens of a disabled user.", tests.Success, testID)
+		}
+	}
+}
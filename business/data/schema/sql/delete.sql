@@ -1,3 +1,28 @@
+DELETE FROM token_cutoffs;
+DELETE FROM revoked_tokens;
+DELETE FROM refresh_tokens;
+DELETE FROM out_of_order;
+DELETE FROM housekeeping_tasks;
+DELETE FROM fx_rates;
+DELETE FROM tax_rules;
+DELETE FROM payment_transactions;
+DELETE FROM folio_lines;
+DELETE FROM folios;
+DELETE FROM reservation_guests;
+DELETE FROM reservation_nights;
+DELETE FROM rate_plan_calendar;
+DELETE FROM rate_plan_weekdays;
+DELETE FROM outbox;
+DELETE FROM waitlist;
+DELETE FROM holds;
+DELETE FROM reservation_history;
+DELETE FROM reservations;
+DELETE FROM blocks;
+DELETE FROM guests;
+DELETE FROM rate_plans;
+DELETE FROM rooms;
+DELETE FROM room_types;
+DELETE FROM properties;
 DELETE FROM sales;
 DELETE FROM products;
 DELETE FROM users;
\ No newline at end of file
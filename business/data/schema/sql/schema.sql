@@ -43,3 +43,508 @@ CREATE TABLE sales (
 	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
 	FOREIGN KEY (product_id) REFERENCES products(product_id) ON DELETE CASCADE
 );
+
+-- Version: 1.04
+-- Description: Create table properties
+CREATE TABLE properties (
+	property_id  UUID      NOT NULL,
+	name         TEXT      NOT NULL,
+	address      TEXT      NOT NULL,
+	city         TEXT      NOT NULL,
+	country      TEXT      NOT NULL,
+	time_zone    TEXT      NOT NULL,
+	currency     TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (property_id)
+);
+
+-- Version: 1.05
+-- Description: Create table room_types
+CREATE TABLE room_types (
+	room_type_id UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	name         TEXT      NOT NULL,
+	description  TEXT      NOT NULL,
+	capacity     INT       NOT NULL,
+	bed_config   TEXT      NOT NULL,
+	amenities    TEXT[]    NOT NULL,
+	base_rate    INT       NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (room_type_id),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE
+);
+
+-- Version: 1.06
+-- Description: Create table rooms
+CREATE TABLE rooms (
+	room_id      UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	room_type_id UUID      NOT NULL,
+	number       TEXT      NOT NULL,
+	floor        INT       NOT NULL,
+	status       TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (room_id),
+	UNIQUE (property_id, number),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE,
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE CASCADE
+);
+
+-- Version: 1.07
+-- Description: Create table reservations
+CREATE TABLE reservations (
+	reservation_id UUID      NOT NULL,
+	property_id    UUID      NOT NULL,
+	room_type_id   UUID      NOT NULL,
+	user_id        UUID      NOT NULL,
+	check_in       DATE      NOT NULL,
+	check_out      DATE      NOT NULL,
+	guests         INT       NOT NULL,
+	status         TEXT      NOT NULL,
+	total          INT       NOT NULL,
+	date_created   TIMESTAMP NOT NULL,
+	date_updated   TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (reservation_id),
+	CHECK (check_out > check_in),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE RESTRICT,
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE RESTRICT,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE RESTRICT
+);
+
+CREATE INDEX reservations_room_type_stay_idx ON reservations (room_type_id, check_in, check_out);
+
+-- Version: 1.08
+-- Description: Track reservation lifecycle transitions
+ALTER TABLE reservations
+	ADD COLUMN date_confirmed   TIMESTAMP NULL,
+	ADD COLUMN date_checked_in  TIMESTAMP NULL,
+	ADD COLUMN date_checked_out TIMESTAMP NULL,
+	ADD COLUMN date_cancelled   TIMESTAMP NULL,
+	ADD COLUMN date_no_show     TIMESTAMP NULL,
+	ADD COLUMN expires_at       TIMESTAMP NULL;
+
+CREATE TABLE reservation_history (
+	history_id     UUID      NOT NULL,
+	reservation_id UUID      NOT NULL,
+	from_status    TEXT      NOT NULL,
+	to_status      TEXT      NOT NULL,
+	user_id        UUID      NOT NULL,
+	date_created   TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (history_id),
+	FOREIGN KEY (reservation_id) REFERENCES reservations(reservation_id) ON DELETE CASCADE
+);
+
+-- Version: 1.09
+-- Description: Create table holds
+CREATE TABLE holds (
+	hold_id      UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	room_type_id UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	check_in     DATE      NOT NULL,
+	check_out    DATE      NOT NULL,
+	guests       INT       NOT NULL,
+	status       TEXT      NOT NULL,
+	expires_at   TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (hold_id),
+	CHECK (check_out > check_in),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE,
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX holds_active_idx ON holds (room_type_id, check_in, check_out) WHERE status = 'ACTIVE';
+
+-- Version: 1.10
+-- Description: Create rate plans with weekday rates and a per-date calendar
+CREATE TABLE rate_plans (
+	rate_plan_id       UUID      NOT NULL,
+	room_type_id       UUID      NOT NULL,
+	name               TEXT      NOT NULL,
+	refundable         BOOLEAN   NOT NULL,
+	breakfast_included BOOLEAN   NOT NULL,
+	base_rate          INT       NOT NULL,
+	min_stay           INT       NOT NULL,
+	date_created       TIMESTAMP NOT NULL,
+	date_updated       TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (rate_plan_id),
+	CHECK (base_rate >= 0),
+	CHECK (min_stay >= 1),
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE CASCADE
+);
+
+CREATE TABLE rate_plan_weekdays (
+	rate_plan_id UUID NOT NULL,
+	weekday      INT  NOT NULL,
+	rate         INT  NOT NULL,
+
+	PRIMARY KEY (rate_plan_id, weekday),
+	CHECK (weekday BETWEEN 0 AND 6),
+	CHECK (rate >= 0),
+	FOREIGN KEY (rate_plan_id) REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE
+);
+
+CREATE TABLE rate_plan_calendar (
+	rate_plan_id      UUID    NOT NULL,
+	night             DATE    NOT NULL,
+	rate              INT     NULL,
+	min_stay          INT     NULL,
+	closed_to_arrival BOOLEAN NOT NULL,
+
+	PRIMARY KEY (rate_plan_id, night),
+	CHECK (rate >= 0),
+	CHECK (min_stay >= 1),
+	FOREIGN KEY (rate_plan_id) REFERENCES rate_plans(rate_plan_id) ON DELETE CASCADE
+);
+
+ALTER TABLE reservations ADD COLUMN rate_plan_id UUID NULL REFERENCES rate_plans(rate_plan_id) ON DELETE SET NULL;
+ALTER TABLE holds ADD COLUMN rate_plan_id UUID NULL REFERENCES rate_plans(rate_plan_id) ON DELETE SET NULL;
+
+-- Version: 1.11
+-- Description: Add cancellation policies and keep the nightly price of reservations
+ALTER TABLE rate_plans
+	ADD COLUMN free_cancel_hours INT  NOT NULL DEFAULT 24 CHECK (free_cancel_hours >= 0),
+	ADD COLUMN penalty_type      TEXT NOT NULL DEFAULT 'FIRST_NIGHT',
+	ADD COLUMN penalty_percent   INT  NOT NULL DEFAULT 0 CHECK (penalty_percent BETWEEN 0 AND 100);
+
+ALTER TABLE reservations
+	ADD COLUMN cancel_penalty INT NULL,
+	ADD COLUMN cancel_refund  INT NULL;
+
+CREATE TABLE reservation_nights (
+	reservation_id UUID NOT NULL,
+	night          DATE NOT NULL,
+	rate           INT  NOT NULL,
+
+	PRIMARY KEY (reservation_id, night),
+	FOREIGN KEY (reservation_id) REFERENCES reservations(reservation_id) ON DELETE CASCADE
+);
+
+-- Version: 1.12
+-- Description: Create guest profiles and attach them to reservations
+CREATE TABLE guests (
+	guest_id          UUID      NOT NULL,
+	user_id           UUID      NULL,
+	name              TEXT      NOT NULL,
+	email             TEXT      NOT NULL,
+	phone             TEXT      NOT NULL,
+	nationality       TEXT      NOT NULL,
+	document_type     TEXT      NOT NULL,
+	document_number   TEXT      NOT NULL,
+	preferences       TEXT[]    NOT NULL,
+	marketing_consent BOOLEAN   NOT NULL,
+	date_created      TIMESTAMP NOT NULL,
+	date_updated      TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (guest_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE SET NULL
+);
+
+CREATE INDEX guests_user_idx ON guests (user_id);
+
+CREATE TABLE reservation_guests (
+	reservation_id UUID    NOT NULL,
+	guest_id       UUID    NOT NULL,
+	is_primary     BOOLEAN NOT NULL,
+
+	PRIMARY KEY (reservation_id, guest_id),
+	FOREIGN KEY (reservation_id) REFERENCES reservations(reservation_id) ON DELETE CASCADE,
+	FOREIGN KEY (guest_id) REFERENCES guests(guest_id) ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX reservation_guests_primary_idx ON reservation_guests (reservation_id) WHERE is_primary;
+
+-- Version: 1.13
+-- Description: Create the waitlist for sold out stays and the notification outbox
+CREATE TABLE waitlist (
+	waitlist_id  UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	room_type_id UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	check_in     DATE      NOT NULL,
+	check_out    DATE      NOT NULL,
+	guests       INT       NOT NULL,
+	status       TEXT      NOT NULL,
+	hold_id      UUID      NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (waitlist_id),
+	CHECK (check_out > check_in),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE,
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (hold_id) REFERENCES holds(hold_id) ON DELETE SET NULL
+);
+
+CREATE INDEX waitlist_waiting_idx ON waitlist (room_type_id, date_created) WHERE status = 'WAITING';
+
+CREATE TABLE outbox (
+	message_id   UUID      NOT NULL,
+	kind         TEXT      NOT NULL,
+	user_id      UUID      NOT NULL,
+	payload      TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_sent    TIMESTAMP NULL,
+
+	PRIMARY KEY (message_id),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (date_created) WHERE date_sent IS NULL;
+
+-- Version: 1.14
+-- Description: Create room blocks for groups and link the reservations picked up from them
+CREATE TABLE blocks (
+	block_id     UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	room_type_id UUID      NOT NULL,
+	name         TEXT      NOT NULL,
+	code         TEXT      NOT NULL,
+	check_in     DATE      NOT NULL,
+	check_out    DATE      NOT NULL,
+	rooms        INT       NOT NULL,
+	rate         INT       NOT NULL,
+	cutoff       TIMESTAMP NOT NULL,
+	status       TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (block_id),
+	UNIQUE (code),
+	CHECK (check_out > check_in),
+	CHECK (rooms >= 1),
+	CHECK (rate >= 0),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE RESTRICT,
+	FOREIGN KEY (room_type_id) REFERENCES room_types(room_type_id) ON DELETE RESTRICT
+);
+
+CREATE INDEX blocks_active_idx ON blocks (room_type_id, check_in, check_out) WHERE status = 'ACTIVE';
+
+ALTER TABLE reservations ADD COLUMN block_id UUID NULL REFERENCES blocks(block_id) ON DELETE RESTRICT;
+
+-- Version: 1.15
+-- Description: Create folios and the lines charged and paid on them
+CREATE TABLE folios (
+	folio_id       UUID      NOT NULL,
+	reservation_id UUID      NOT NULL,
+	date_created   TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (folio_id),
+	UNIQUE (reservation_id),
+	FOREIGN KEY (reservation_id) REFERENCES reservations(reservation_id) ON DELETE RESTRICT
+);
+
+CREATE TABLE folio_lines (
+	line_id      UUID      NOT NULL,
+	folio_id     UUID      NOT NULL,
+	kind         TEXT      NOT NULL,
+	description  TEXT      NOT NULL,
+	product_id   UUID      NULL,
+	night        DATE      NULL,
+	quantity     INT       NOT NULL,
+	unit_amount  INT       NOT NULL,
+	amount       INT       NOT NULL,
+	reference    TEXT      NOT NULL,
+	user_id      UUID      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (line_id),
+	FOREIGN KEY (folio_id) REFERENCES folios(folio_id) ON DELETE CASCADE,
+	FOREIGN KEY (product_id) REFERENCES products(product_id) ON DELETE SET NULL
+);
+
+CREATE INDEX folio_lines_folio_idx ON folio_lines (folio_id);
+
+-- Version: 1.16
+-- Description: Create the payment transactions sent to the gateway
+CREATE TABLE payment_transactions (
+	transaction_id  UUID      NOT NULL,
+	reservation_id  UUID      NULL,
+	sale_id         UUID      NULL,
+	kind            TEXT      NOT NULL,
+	amount          INT       NOT NULL,
+	currency        TEXT      NOT NULL,
+	gateway_ref     TEXT      NOT NULL,
+	idempotency_key TEXT      NOT NULL,
+	user_id         UUID      NOT NULL,
+	date_created    TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (transaction_id),
+	UNIQUE (idempotency_key),
+	CHECK ((reservation_id IS NULL) <> (sale_id IS NULL)),
+	FOREIGN KEY (reservation_id) REFERENCES reservations(reservation_id) ON DELETE RESTRICT,
+	FOREIGN KEY (sale_id) REFERENCES sales(sale_id) ON DELETE CASCADE
+);
+
+CREATE INDEX payment_transactions_reservation_idx ON payment_transactions (reservation_id);
+CREATE INDEX payment_transactions_sale_idx ON payment_transactions (sale_id);
+
+-- Version: 1.17
+-- Description: Create the tax and fee rules of properties and jurisdictions
+ALTER TABLE properties ADD COLUMN jurisdiction TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE tax_rules (
+	tax_rule_id  UUID      NOT NULL,
+	property_id  UUID      NULL,
+	jurisdiction TEXT      NULL,
+	name         TEXT      NOT NULL,
+	category     TEXT      NOT NULL,
+	kind         TEXT      NOT NULL,
+	amount       INT       NOT NULL,
+	rounding     TEXT      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+	date_updated TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (tax_rule_id),
+	CHECK ((property_id IS NULL) <> (jurisdiction IS NULL)),
+	CHECK (amount >= 0),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE
+);
+
+CREATE INDEX tax_rules_property_idx ON tax_rules (property_id);
+CREATE INDEX tax_rules_jurisdiction_idx ON tax_rules (jurisdiction);
+
+-- Version: 1.18
+-- Description: Carry the currency with product costs and sale payments
+CREATE TYPE money_amount AS (
+	amount   INT,
+	currency TEXT
+);
+
+ALTER TABLE products ALTER COLUMN cost TYPE money_amount USING ROW(cost, 'USD')::money_amount;
+ALTER TABLE sales ALTER COLUMN paid TYPE money_amount USING ROW(paid, 'USD')::money_amount;
+
+-- Version: 1.19
+-- Description: Create table fx_rates
+CREATE TABLE fx_rates (
+	base         TEXT      NOT NULL,
+	quote        TEXT      NOT NULL,
+	rate         NUMERIC   NOT NULL,
+	as_of        TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (base, quote, as_of),
+	CHECK (rate > 0)
+);
+
+-- Version: 1.20
+-- Description: Track housekeeping on rooms and the room each stay is in
+ALTER TABLE rooms ADD COLUMN housekeeping TEXT NOT NULL DEFAULT 'CLEAN';
+
+ALTER TABLE reservations ADD COLUMN room_id UUID NULL REFERENCES rooms(room_id) ON DELETE SET NULL;
+
+CREATE INDEX reservations_room_idx ON reservations (room_id);
+
+CREATE TABLE housekeeping_tasks (
+	task_id        UUID      NOT NULL,
+	room_id        UUID      NOT NULL,
+	assignee_id    UUID      NOT NULL,
+	day            DATE      NOT NULL,
+	note           TEXT      NOT NULL,
+	user_id        UUID      NOT NULL,
+	date_completed TIMESTAMP NULL,
+	date_created   TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (task_id),
+	UNIQUE (room_id, day),
+	FOREIGN KEY (room_id) REFERENCES rooms(room_id) ON DELETE CASCADE,
+	FOREIGN KEY (assignee_id) REFERENCES users(user_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX housekeeping_tasks_assignee_idx ON housekeeping_tasks (assignee_id, day);
+
+-- Version: 1.21
+-- Description: Describe rooms and the stays that prefer them for room assignment
+ALTER TABLE rooms ADD COLUMN accessible BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE rooms ADD COLUMN connects_to UUID NULL REFERENCES rooms(room_id) ON DELETE SET NULL;
+
+ALTER TABLE reservations ADD COLUMN preferred_floor INT NULL;
+
+ALTER TABLE reservations ADD COLUMN accessible BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE reservations ADD COLUMN connect_with UUID NULL REFERENCES reservations(reservation_id) ON DELETE SET NULL;
+
+CREATE INDEX reservations_arrival_idx ON reservations (property_id, check_in);
+
+-- Version: 1.22
+-- Description: Add out-of-order periods taking rooms out of inventory
+CREATE TABLE out_of_order (
+	period_id    UUID      NOT NULL,
+	property_id  UUID      NOT NULL,
+	room_id      UUID      NOT NULL,
+	starts       DATE      NOT NULL,
+	ends         DATE      NOT NULL,
+	reason       TEXT      NOT NULL,
+	user_id      UUID      NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (period_id),
+	CHECK (ends > starts),
+	FOREIGN KEY (property_id) REFERENCES properties(property_id) ON DELETE CASCADE,
+	FOREIGN KEY (room_id) REFERENCES rooms(room_id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX out_of_order_room_idx ON out_of_order (room_id, starts, ends);
+
+-- Version: 1.23
+-- Description: Add refresh tokens, rotated one use at a time within a family
+CREATE TABLE refresh_tokens (
+	token_id     UUID      NOT NULL,
+	family_id    UUID      NOT NULL,
+	user_id      UUID      NOT NULL,
+	token_hash   TEXT      NOT NULL,
+	expires_at   TIMESTAMP NOT NULL,
+	date_used    TIMESTAMP NULL,
+	date_revoked TIMESTAMP NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (token_id),
+	UNIQUE (token_hash),
+	FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+CREATE INDEX refresh_tokens_user_idx ON refresh_tokens (user_id);
+
+-- Version: 1.24
+-- Description: Add revoked access tokens and a per-user cutoff for older tokens
+CREATE TABLE revoked_tokens (
+	token_id     TEXT      NOT NULL,
+	user_id      UUID      NOT NULL,
+	expires_at   TIMESTAMP NOT NULL,
+	date_created TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (token_id)
+);
+
+CREATE INDEX revoked_tokens_expires_idx ON revoked_tokens (expires_at);
+
+CREATE TABLE token_cutoffs (
+	user_id            UUID      NOT NULL,
+	tokens_valid_after TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (user_id)
+);
+
+-- Version: 1.25
+-- Description: Count failed logins and lock accounts out for a while
+ALTER TABLE users ADD COLUMN failed_logins INT NOT NULL DEFAULT 0;
+
+ALTER TABLE users ADD COLUMN locked_until TIMESTAMP NULL;
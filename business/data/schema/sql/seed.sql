@@ -4,12 +4,39 @@ INSERT INTO users (user_id, name, email, roles, password_hash, department, enabl
 	ON CONFLICT DO NOTHING;
 
 INSERT INTO products (product_id, user_id, name, cost, quantity, date_created, date_updated) VALUES
-	('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Comic Books', 50, 42, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
-	('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'McDonalds Toys', 75, 120, '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
+	('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Comic Books', '(50,USD)', 42, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'McDonalds Toys', '(75,USD)', 120, '2019-01-01 00:00:02.000001+00', '2019-01-01 00:00:02.000001+00')
 	ON CONFLICT DO NOTHING;
 
 INSERT INTO sales (sale_id, user_id, product_id, quantity, paid, date_created) VALUES
-	('98b6d4b8-f04b-4c79-8c2e-a0aef46854b7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 2, 100, '2019-01-01 00:00:03.000001+00'),
-	('85f6fb09-eb05-4874-ae39-82d1a30fe0d7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 5, 250, '2019-01-01 00:00:04.000001+00'),
-	('a235be9e-ab5d-44e6-a987-fa1c749264c7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', '72f8b983-3eb4-48db-9ed0-e45cc6bd716b', 3, 225, '2019-01-01 00:00:05.000001+00')
+	('98b6d4b8-f04b-4c79-8c2e-a0aef46854b7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 2, '(100,USD)', '2019-01-01 00:00:03.000001+00'),
+	('85f6fb09-eb05-4874-ae39-82d1a30fe0d7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'a2b0639f-2cc6-44b8-b97b-15d69dbb511e', 5, '(250,USD)', '2019-01-01 00:00:04.000001+00'),
+	('a235be9e-ab5d-44e6-a987-fa1c749264c7', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', '72f8b983-3eb4-48db-9ed0-e45cc6bd716b', 3, '(225,USD)', '2019-01-01 00:00:05.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO properties (property_id, name, address, city, country, time_zone, currency, date_created, date_updated) VALUES
+	('9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', 'Gopher Grand', '1 Gopher Way', 'Ho Chi Minh City', 'VN', 'Asia/Ho_Chi_Minh', 'USD', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO room_types (room_type_id, property_id, name, description, capacity, bed_config, amenities, base_rate, date_created, date_updated) VALUES
+	('3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', '9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', 'Standard King', 'City view room with a king bed', 2, '1 KING', '{wifi,tv}', 120, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('6a2d9b4c-1c3e-4f5a-8b7d-9e0f1a2b3c02', '9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', 'Deluxe Twin', 'River view room with two twin beds', 3, '2 TWIN', '{wifi,tv,minibar}', 180, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO rooms (room_id, property_id, room_type_id, number, floor, status, date_created, date_updated) VALUES
+	('b1e2c3d4-0001-4a5b-8c6d-7e8f9a0b1c01', '9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', '3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', '101', 1, 'AVAILABLE', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('b1e2c3d4-0002-4a5b-8c6d-7e8f9a0b1c02', '9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', '3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', '102', 1, 'AVAILABLE', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('b1e2c3d4-0003-4a5b-8c6d-7e8f9a0b1c03', '9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21', '6a2d9b4c-1c3e-4f5a-8b7d-9e0f1a2b3c02', '201', 2, 'AVAILABLE', '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO rate_plans (rate_plan_id, room_type_id, name, refundable, breakfast_included, base_rate, min_stay, date_created, date_updated) VALUES
+	('d4e5f6a7-0001-4b2c-8d3e-4f5a6b7c8d01', '3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', 'Flexible', TRUE, FALSE, 120, 1, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('d4e5f6a7-0002-4b2c-8d3e-4f5a6b7c8d02', '3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', 'Non-refundable', FALSE, FALSE, 100, 1, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('d4e5f6a7-0003-4b2c-8d3e-4f5a6b7c8d03', '3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01', 'Bed & Breakfast', TRUE, TRUE, 140, 2, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00'),
+	('d4e5f6a7-0004-4b2c-8d3e-4f5a6b7c8d04', '6a2d9b4c-1c3e-4f5a-8b7d-9e0f1a2b3c02', 'Flexible', TRUE, FALSE, 180, 1, '2019-01-01 00:00:01.000001+00', '2019-01-01 00:00:01.000001+00')
+	ON CONFLICT DO NOTHING;
+
+INSERT INTO rate_plan_weekdays (rate_plan_id, weekday, rate) VALUES
+	('d4e5f6a7-0001-4b2c-8d3e-4f5a6b7c8d01', 5, 150),
+	('d4e5f6a7-0001-4b2c-8d3e-4f5a6b7c8d01', 6, 150)
 	ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,229 @@
+// Package availability computes room availability from the room inventory
+// and the reservations and holds placed against it.
+package availability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("room type not found")
+
+// availabilityCTE computes, for every room type, the rooms in service minus
+// the reservations, active holds and active blocks on the busiest night
+// between :check_in and :check_out. Holds and HELD reservations count until
+// their expiry, compared against :now, so a lapsed hold frees its room even
+// before the reaper marks it expired. An active block takes all of its rooms
+// out every night it covers, whether picked up or not, so reservations picked
+// up from it only count on their own once it is released. Rooms in service
+// but out of order on a night are taken out that night.
+const availabilityCTE = `
+	WITH nights AS (
+		SELECT
+			CAST(n AS DATE) AS night
+		FROM
+			generate_series(CAST(:check_in AS DATE), CAST(:check_out AS DATE) - 1, INTERVAL '1 day') AS n
+	),
+	inventory AS (
+		SELECT
+			room_type_id,
+			COUNT(*) AS rooms
+		FROM
+			rooms
+		WHERE
+			status <> 'OUT_OF_SERVICE'
+		GROUP BY
+			room_type_id
+	),
+	occupancy AS (
+		SELECT
+			rt.room_type_id,
+			MAX(booked.cnt) AS booked
+		FROM
+			room_types rt
+		CROSS JOIN
+			nights n
+		CROSS JOIN LATERAL (
+			SELECT
+				(
+					SELECT
+						COUNT(*)
+					FROM
+						reservations r
+					LEFT JOIN
+						blocks b ON b.block_id = r.block_id
+					WHERE
+						r.room_type_id = rt.room_type_id AND
+						(r.status IN ('CONFIRMED', 'CHECKED_IN') OR (r.status = 'HELD' AND r.expires_at > :now)) AND
+						(b.block_id IS NULL OR b.status <> 'ACTIVE') AND
+						r.check_in <= n.night AND
+						r.check_out > n.night
+				) + (
+					SELECT
+						COUNT(*)
+					FROM
+						holds h
+					WHERE
+						h.room_type_id = rt.room_type_id AND
+						h.status = 'ACTIVE' AND
+						h.expires_at > :now AND
+						h.check_in <= n.night AND
+						h.check_out > n.night
+				) + (
+					SELECT
+						COALESCE(SUM(b.rooms), 0)
+					FROM
+						blocks b
+					WHERE
+						b.room_type_id = rt.room_type_id AND
+						b.status = 'ACTIVE' AND
+						b.check_in <= n.night AND
+						b.check_out > n.night
+				) + (
+					SELECT
+						COUNT(DISTINCT o.room_id)
+					FROM
+						out_of_order o
+					JOIN
+						rooms rm ON rm.room_id = o.room_id
+					WHERE
+						rm.room_type_id = rt.room_type_id AND
+						rm.status <> 'OUT_OF_SERVICE' AND
+						o.starts <= n.night AND
+						o.ends > n.night
+				) AS cnt
+		) AS booked
+		GROUP BY
+			rt.room_type_id
+	),
+	availability AS (
+		SELECT
+			rt.room_type_id,
+			rt.property_id,
+			rt.name,
+			rt.capacity,
+			rt.base_rate,
+			COALESCE(i.rooms, 0) - COALESCE(o.booked, 0) AS available,
+			(SELECT COUNT(*) FROM nights) AS nights
+		FROM
+			room_types rt
+		LEFT JOIN
+			inventory i ON i.room_type_id = rt.room_type_id
+		LEFT JOIN
+			occupancy o ON o.room_type_id = rt.room_type_id
+	)
+	`
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Query returns the room types with at least one free room for the whole
+// stay that can host the number of guests, optionally within one property,
+// cheapest base rate first.
+func (s *Store) Query(ctx context.Context, filter Filter, pageNumber int, rowsPerPage int) ([]Availability, error) {
+
+	if err := validation.Check(filter); err != nil {
+		return nil, fmt.Errorf("validating data: %w", err)
+	}
+
+	data := struct {
+		PropertyID  string    `db:"property_id"`
+		CheckIn     time.Time `db:"check_in"`
+		CheckOut    time.Time `db:"check_out"`
+		Guests      int       `db:"guests"`
+		Now         time.Time `db:"now"`
+		Offset      int       `db:"offset"`
+		RowsPerPage int       `db:"rows_per_page"`
+	}{
+		CheckIn:     filter.CheckIn,
+		CheckOut:    filter.CheckOut,
+		Guests:      filter.Guests,
+		Now:         time.Now(),
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+	if filter.PropertyID != nil {
+		data.PropertyID = filter.PropertyID.String()
+	}
+
+	const q = availabilityCTE + `
+	SELECT
+		room_type_id, property_id, name, capacity, available, nights
+	FROM
+		availability
+	WHERE
+		available > 0 AND
+		capacity >= :guests AND
+		(:property_id = '' OR CAST(property_id AS TEXT) = :property_id)
+	ORDER BY
+		base_rate, room_type_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
+	`
+
+	var avls []Availability
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &avls); err != nil {
+		return nil, fmt.Errorf("selecting availability: %w", err)
+	}
+
+	return avls, nil
+}
+
+// QueryByRoomTypeID returns the availability of a single room type for the
+// stay, even when it is sold out.
+func (s *Store) QueryByRoomTypeID(ctx context.Context, roomTypeID uuid.UUID, checkIn time.Time, checkOut time.Time) (Availability, error) {
+	data := struct {
+		RoomTypeID string    `db:"room_type_id"`
+		CheckIn    time.Time `db:"check_in"`
+		CheckOut   time.Time `db:"check_out"`
+		Now        time.Time `db:"now"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+		Now:        time.Now(),
+	}
+
+	const q = availabilityCTE + `
+	SELECT
+		room_type_id, property_id, name, capacity, available, nights
+	FROM
+		availability
+	WHERE
+		room_type_id = :room_type_id
+	`
+
+	var avl Availability
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &avl); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Availability{}, ErrNotFound
+		}
+		return Availability{}, fmt.Errorf("selecting availability roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return avl, nil
+}
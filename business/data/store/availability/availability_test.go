@@ -0,0 +1,202 @@
+package availability_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tcmhoang/sservices/business/data/store/availability"
+	"github.com/tcmhoang/sservices/business/data/store/reservation"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+// Seeded inventory: "Standard King" has two rooms for 2 guests and
+// "Deluxe Twin" has a single room for 3 guests.
+var (
+	propertyID   = uuid.MustParse("9c1d1e4b-5f0c-4ad2-9a43-3f0b6f1f8d21")
+	standardKing = uuid.MustParse("3f0c7a2e-8e7b-4b1f-9d43-2a4c6b8e1f01")
+	deluxeTwin   = uuid.MustParse("6a2d9b4c-1c3e-4f5a-8b7d-9e0f1a2b3c02")
+	userID       = uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f")
+	checkIn      = time.Date(2030, time.March, 10, 0, 0, 0, 0, time.UTC)
+	checkOut     = checkIn.AddDate(0, 0, 3)
+)
+
+func TestAvailability(t *testing.T) {
+	t.Run("search", search)
+	t.Run("paging", paging)
+}
+
+func search(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	store := availability.NewStore(stest.Log, stest.DB)
+	resStore := reservation.NewStore(stest.Log, stest.DB)
+
+	t.Log("Given the need to search for free rooms.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen searching the seeded property.", testID)
+		{
+			ctx := context.Background()
+
+			filter := availability.Filter{
+				PropertyID: &propertyID,
+				CheckIn:    checkIn,
+				CheckOut:   checkOut,
+				Guests:     3,
+			}
+
+			avls, err := store.Query(ctx, filter, 1, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to search availability : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to search availability.", tests.Success, testID)
+
+			if len(avls) != 1 || avls[0].RoomTypeID != deluxeTwin {
+				t.Fatalf("\t%s\tTest %d:\tShould only get room types fitting 3 guests : %+v.", tests.Failed, testID, avls)
+			}
+			t.Logf("\t%s\tTest %d:\tShould only get room types fitting 3 guests.", tests.Success, testID)
+
+			if avls[0].Nights != 3 {
+				t.Fatalf("\t%s\tTest %d:\tShould count every night of the stay : %+v.", tests.Failed, testID, avls[0])
+			}
+			t.Logf("\t%s\tTest %d:\tShould count every night of the stay.", tests.Success, testID)
+
+			now := time.Now()
+			res := reservation.Reservation{
+				ID:          uuid.New(),
+				PropertyID:  propertyID,
+				RoomTypeID:  deluxeTwin,
+				UserID:      userID,
+				CheckIn:     checkIn.AddDate(0, 0, 2),
+				CheckOut:    checkIn.AddDate(0, 0, 5),
+				Guests:      2,
+				Status:      reservation.StatusConfirmed,
+				DateCreated: now,
+				DateUpdated: now,
+			}
+			if err := resStore.Create(ctx, res); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a reservation : %s.", tests.Failed, testID, err)
+			}
+
+			avls, err = store.Query(ctx, filter, 1, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to search availability : %s.", tests.Failed, testID, err)
+			}
+
+			if len(avls) != 0 {
+				t.Fatalf("\t%s\tTest %d:\tShould not offer a room type booked on the last night : %+v.", tests.Failed, testID, avls)
+			}
+			t.Logf("\t%s\tTest %d:\tShould not offer a room type booked on the last night.", tests.Success, testID)
+
+			filter.CheckOut = checkIn.AddDate(0, 0, 2)
+			avls, err = store.Query(ctx, filter, 1, 10)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to search availability : %s.", tests.Failed, testID, err)
+			}
+
+			if len(avls) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould offer a stay checking out on the arrival day : %+v.", tests.Failed, testID, avls)
+			}
+			t.Logf("\t%s\tTest %d:\tShould offer a stay checking out on the arrival day.", tests.Success, testID)
+
+			avl, err := store.QueryByRoomTypeID(ctx, standardKing, checkIn, checkOut)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to get a single room type : %s.", tests.Failed, testID, err)
+			}
+
+			if avl.Available != 2 {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, avl.Available)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, 2)
+				t.Fatalf("\t%s\tTest %d:\tShould count every room in service.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould count every room in service.", tests.Success, testID)
+
+			if _, err := store.QueryByRoomTypeID(ctx, uuid.New(), checkIn, checkOut); !errors.Is(err, availability.ErrNotFound) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT find an unknown room type : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT find an unknown room type.", tests.Success, testID)
+		}
+	}
+}
+
+func paging(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	store := availability.NewStore(stest.Log, stest.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Log("Given the need to page through availability.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen paging through 2 room types.", testID)
+		{
+			filter := availability.Filter{
+				CheckIn:  checkIn,
+				CheckOut: checkOut,
+				Guests:   1,
+			}
+
+			page1, err := store.Query(ctx, filter, 1, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve page 1 : %s.", tests.Failed, testID, err)
+			}
+
+			page2, err := store.Query(ctx, filter, 2, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve page 2 : %s.", tests.Failed, testID, err)
+			}
+
+			if len(page1) != 1 || len(page2) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould have a single room type per page.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have a single room type per page.", tests.Success, testID)
+
+			if page1[0].RoomTypeID == page2[0].RoomTypeID {
+				t.Fatalf("\t%s\tTest %d:\tShould have different room types.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have different room types.", tests.Success, testID)
+
+			if page1[0].RoomTypeID != standardKing {
+				t.Fatalf("\t%s\tTest %d:\tShould list the cheapest room type first.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould list the cheapest room type first.", tests.Success, testID)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package availability
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxNights is the longest stay availability can be searched for.
+const MaxNights = 90
+
+// Availability describes how many rooms of a room type are free on every
+// night of a stay. Prices come from the pricing core, not from here.
+type Availability struct {
+	RoomTypeID uuid.UUID `db:"room_type_id" json:"roomTypeID"`
+	PropertyID uuid.UUID `db:"property_id" json:"propertyID"`
+	Name       string    `db:"name" json:"name"`
+	Capacity   int       `db:"capacity" json:"capacity"`
+	Available  int       `db:"available" json:"available"`
+	Nights     int       `db:"nights" json:"nights"`
+}
+
+// Filter holds the search criteria for room types with free rooms. Currency
+// is the one prices are also shown in, if any.
+type Filter struct {
+	PropertyID *uuid.UUID `json:"property"`
+	CheckIn    time.Time  `json:"checkin" validate:"required"`
+	CheckOut   time.Time  `json:"checkout" validate:"required,gtfield=CheckIn"`
+	Guests     int        `json:"guests" validate:"required,gte=1"`
+	Currency   string     `json:"currency" validate:"omitempty,iso4217"`
+}
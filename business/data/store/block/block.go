@@ -0,0 +1,262 @@
+// Package block supports storing the room blocks set aside for groups.
+package block
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound  = errors.New("block not found")
+	ErrCodeTaken = errors.New("block code is already taken")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, b Block) error {
+	const q = `
+		INSERT INTO blocks
+			(block_id, property_id, room_type_id, name, code, check_in, check_out, rooms, rate, cutoff, status, date_created, date_updated)
+		VALUES
+			(:block_id, :property_id, :room_type_id, :name, :code, :check_in, :check_out, :rooms, :rate, :cutoff, :status, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, b); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return ErrCodeTaken
+		}
+		return fmt.Errorf("inserting block: %w", err)
+	}
+
+	return nil
+}
+
+// Update persists the status of a block.
+func (s *Store) Update(ctx context.Context, b Block) error {
+	const q = `
+		UPDATE
+			blocks
+		SET
+			"status" = :status,
+			"date_updated" = :date_updated
+		WHERE
+			block_id = :block_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, b); err != nil {
+		return fmt.Errorf("updating blockID[%s]: %w", b.ID, err)
+	}
+
+	return nil
+}
+
+// ReleaseDue marks every ACTIVE block whose cutoff passed by now as RELEASED
+// and returns them.
+func (s *Store) ReleaseDue(ctx context.Context, now time.Time) ([]Block, error) {
+	data := struct {
+		Status string    `db:"status"`
+		Now    time.Time `db:"now"`
+	}{
+		Status: StatusReleased,
+		Now:    now,
+	}
+
+	const q = `
+		UPDATE
+			blocks
+		SET
+			"status" = :status,
+			"date_updated" = :now
+		WHERE
+			status = 'ACTIVE' AND
+			cutoff <= :now
+		RETURNING
+			*
+		`
+
+	var bs []Block
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &bs); err != nil {
+		return nil, fmt.Errorf("releasing blocks: %w", err)
+	}
+
+	return bs, nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, blockID uuid.UUID) (Block, error) {
+	data := struct {
+		BlockID string `db:"block_id"`
+	}{
+		BlockID: blockID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			blocks
+		WHERE
+			block_id = :block_id
+		`
+	var b Block
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &b); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Block{}, ErrNotFound
+		}
+		return Block{}, fmt.Errorf("selecting blockID[%q]: %w", blockID, err)
+	}
+
+	return b, nil
+}
+
+// QueryByIDForUpdate gets the specified block and locks its row until the
+// surrounding transaction ends.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, blockID uuid.UUID) (Block, error) {
+	data := struct {
+		BlockID string `db:"block_id"`
+	}{
+		BlockID: blockID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			blocks
+		WHERE
+			block_id = :block_id
+		FOR UPDATE
+		`
+	var b Block
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &b); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Block{}, ErrNotFound
+		}
+		return Block{}, fmt.Errorf("selecting blockID[%q] for update: %w", blockID, err)
+	}
+
+	return b, nil
+}
+
+// QueryByCodeForUpdate gets the block with the given code and locks its row
+// until the surrounding transaction ends, so pickups from the same block are
+// checked one at a time.
+func (s *Store) QueryByCodeForUpdate(ctx context.Context, code string) (Block, error) {
+	data := struct {
+		Code string `db:"code"`
+	}{
+		Code: code,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			blocks
+		WHERE
+			code = :code
+		FOR UPDATE
+		`
+	var b Block
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &b); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Block{}, ErrNotFound
+		}
+		return Block{}, fmt.Errorf("selecting block by code for update: %w", err)
+	}
+
+	return b, nil
+}
+
+func (s *Store) QueryByPropertyID(ctx context.Context, propertyID uuid.UUID) ([]Block, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			blocks
+		WHERE
+			property_id = :property_id
+		ORDER BY
+			check_in, name
+		`
+	var bs []Block
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &bs); err != nil {
+		return nil, fmt.Errorf("selecting blocks propertyID[%q]: %w", propertyID, err)
+	}
+
+	return bs, nil
+}
+
+// PickedUp returns the most rooms picked up from the block on any single
+// night between checkIn and checkOut. HELD pickups count until their expiry,
+// compared against now.
+func (s *Store) PickedUp(ctx context.Context, blockID uuid.UUID, checkIn time.Time, checkOut time.Time, now time.Time) (int, error) {
+	data := struct {
+		BlockID  string    `db:"block_id"`
+		CheckIn  time.Time `db:"check_in"`
+		CheckOut time.Time `db:"check_out"`
+		Now      time.Time `db:"now"`
+	}{
+		BlockID:  blockID.String(),
+		CheckIn:  checkIn,
+		CheckOut: checkOut,
+		Now:      now,
+	}
+
+	const q = `
+		SELECT
+			COALESCE(MAX(picked.cnt), 0) AS picked_up
+		FROM
+			generate_series(CAST(:check_in AS DATE), CAST(:check_out AS DATE) - 1, INTERVAL '1 day') AS n
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) AS cnt
+			FROM
+				reservations r
+			WHERE
+				r.block_id = :block_id AND
+				(r.status IN ('CONFIRMED', 'CHECKED_IN') OR (r.status = 'HELD' AND r.expires_at > :now)) AND
+				r.check_in <= CAST(n AS DATE) AND
+				r.check_out > CAST(n AS DATE)
+		) AS picked
+		`
+
+	var result struct {
+		PickedUp int `db:"picked_up"`
+	}
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &result); err != nil {
+		return 0, fmt.Errorf("counting pickups blockID[%q]: %w", blockID, err)
+	}
+
+	return result.PickedUp, nil
+}
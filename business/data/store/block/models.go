@@ -0,0 +1,55 @@
+package block
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses a block can be in. An ACTIVE block keeps its rooms out of
+// general inventory until it is RELEASED, at its cutoff at the latest.
+const (
+	StatusActive   = "ACTIVE"
+	StatusReleased = "RELEASED"
+)
+
+// Block is a number of rooms of a room type set aside every night of a stay
+// for a group, such as the attendees of an event, at a negotiated nightly
+// rate. Attendees pick up rooms from the block with its code until the
+// cutoff, when the rooms nobody picked up go back to general inventory.
+type Block struct {
+	ID          uuid.UUID `db:"block_id" json:"id"`
+	PropertyID  uuid.UUID `db:"property_id" json:"propertyID"`
+	RoomTypeID  uuid.UUID `db:"room_type_id" json:"roomTypeID"`
+	Name        string    `db:"name" json:"name"`
+	Code        string    `db:"code" json:"code"`
+	CheckIn     time.Time `db:"check_in" json:"checkIn"`
+	CheckOut    time.Time `db:"check_out" json:"checkOut"`
+	Rooms       int       `db:"rooms" json:"rooms"`
+	Rate        int       `db:"rate" json:"rate"`
+	Cutoff      time.Time `db:"cutoff" json:"cutoff"`
+	Status      string    `db:"status" json:"status"`
+	DateCreated time.Time `db:"date_created" json:"dateCreated"`
+	DateUpdated time.Time `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewBlock struct {
+	RoomTypeID uuid.UUID `json:"roomTypeID" validate:"required"`
+	Name       string    `json:"name" validate:"required"`
+	Code       string    `json:"code" validate:"required,alphanum,min=4,max=32"`
+	CheckIn    time.Time `json:"checkIn" validate:"required"`
+	CheckOut   time.Time `json:"checkOut" validate:"required,gtfield=CheckIn"`
+	Rooms      int       `json:"rooms" validate:"required,gte=1"`
+	Rate       int       `json:"rate" validate:"gte=0"`
+	Cutoff     time.Time `json:"cutoff" validate:"required,ltefield=CheckIn"`
+}
+
+// Pickup is an attendee's request for a room out of the block with the
+// given code. The stay may be any part of the block's dates.
+type Pickup struct {
+	Code     string    `json:"code" validate:"required"`
+	CheckIn  time.Time `json:"checkIn" validate:"required"`
+	CheckOut time.Time `json:"checkOut" validate:"required,gtfield=CheckIn"`
+	Guests   int       `json:"guests" validate:"required,gte=1"`
+	UserID   uuid.UUID `json:"userID"`
+}
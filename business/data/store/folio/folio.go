@@ -0,0 +1,130 @@
+// Package folio supports storing the itemized account of each reservation.
+package folio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("folio not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Open returns the folio of the reservation, creating it on first use.
+func (s *Store) Open(ctx context.Context, reservationID uuid.UUID, now time.Time) (Folio, error) {
+	f := Folio{
+		ID:            uuid.New(),
+		ReservationID: reservationID,
+		DateCreated:   now,
+	}
+
+	const q = `
+		INSERT INTO folios
+			(folio_id, reservation_id, date_created)
+		VALUES
+			(:folio_id, :reservation_id, :date_created)
+		ON CONFLICT (reservation_id) DO NOTHING
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, f); err != nil {
+		return Folio{}, fmt.Errorf("inserting folio: %w", err)
+	}
+
+	return s.QueryByReservationID(ctx, reservationID)
+}
+
+func (s *Store) QueryByReservationID(ctx context.Context, reservationID uuid.UUID) (Folio, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			folios
+		WHERE
+			reservation_id = :reservation_id
+		`
+	var f Folio
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &f); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Folio{}, ErrNotFound
+		}
+		return Folio{}, fmt.Errorf("selecting folio reservationID[%q]: %w", reservationID, err)
+	}
+
+	return f, nil
+}
+
+// AddLines posts lines to a folio.
+func (s *Store) AddLines(ctx context.Context, lines []Line) error {
+	const q = `
+		INSERT INTO folio_lines
+			(line_id, folio_id, kind, description, product_id, night, quantity, unit_amount, amount, reference, user_id, date_created)
+		VALUES
+			(:line_id, :folio_id, :kind, :description, :product_id, :night, :quantity, :unit_amount, :amount, :reference, :user_id, :date_created)
+		`
+
+	for _, l := range lines {
+		if err := database.NamedExecContext(ctx, s.log, s.db, q, l); err != nil {
+			return fmt.Errorf("inserting line folioID[%s]: %w", l.FolioID, err)
+		}
+	}
+
+	return nil
+}
+
+// QueryLines returns the lines of a folio in the order they were posted,
+// room nights in date order.
+func (s *Store) QueryLines(ctx context.Context, folioID uuid.UUID) ([]Line, error) {
+	data := struct {
+		FolioID string `db:"folio_id"`
+	}{
+		FolioID: folioID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			folio_lines
+		WHERE
+			folio_id = :folio_id
+		ORDER BY
+			date_created, night, line_id
+		`
+	var ls []Line
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ls); err != nil {
+		return nil, fmt.Errorf("selecting lines folioID[%q]: %w", folioID, err)
+	}
+
+	return ls, nil
+}
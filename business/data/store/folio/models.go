@@ -0,0 +1,64 @@
+package folio
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of kinds of line a folio holds. ROOM, TAX, FEE and EXTRA lines are
+// charges to the guest, PAYMENT lines are money received and REFUND lines
+// money given back.
+const (
+	KindRoom    = "ROOM"
+	KindTax     = "TAX"
+	KindFee     = "FEE"
+	KindExtra   = "EXTRA"
+	KindPayment = "PAYMENT"
+	KindRefund  = "REFUND"
+)
+
+// Folio is the account of everything billed and paid for a reservation.
+type Folio struct {
+	ID            uuid.UUID `db:"folio_id" json:"id"`
+	ReservationID uuid.UUID `db:"reservation_id" json:"reservationID"`
+	DateCreated   time.Time `db:"date_created" json:"dateCreated"`
+}
+
+// Line is a single entry on a folio. Amount is signed from the guest's point
+// of view: charges and refunds add to what the guest owes, payments take
+// from it, so the balance is the sum of every line.
+type Line struct {
+	ID          uuid.UUID  `db:"line_id" json:"id"`
+	FolioID     uuid.UUID  `db:"folio_id" json:"folioID"`
+	Kind        string     `db:"kind" json:"kind"`
+	Description string     `db:"description" json:"description"`
+	ProductID   *uuid.UUID `db:"product_id" json:"productID,omitempty"`
+	Night       *time.Time `db:"night" json:"night,omitempty"`
+	Quantity    int        `db:"quantity" json:"quantity"`
+	UnitAmount  int        `db:"unit_amount" json:"unitAmount"`
+	Amount      int        `db:"amount" json:"amount"`
+	Reference   string     `db:"reference" json:"reference,omitempty"`
+	UserID      uuid.UUID  `db:"user_id" json:"userID"`
+	DateCreated time.Time  `db:"date_created" json:"dateCreated"`
+}
+
+// NewExtra is a charge for items from the product catalog, such as the
+// minibar or parking.
+type NewExtra struct {
+	ProductID uuid.UUID `json:"productID" validate:"required"`
+	Quantity  int       `json:"quantity" validate:"required,gte=1"`
+}
+
+// NewCharge is a tax or fee posted by hand.
+type NewCharge struct {
+	Kind        string `json:"kind" validate:"required,oneof=TAX FEE"`
+	Description string `json:"description" validate:"required"`
+	Amount      int    `json:"amount" validate:"gte=0"`
+}
+
+// NewPayment is money received from or given back to the guest.
+type NewPayment struct {
+	Amount    int    `json:"amount" validate:"required,gte=1"`
+	Reference string `json:"reference"`
+}
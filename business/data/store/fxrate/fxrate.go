@@ -0,0 +1,104 @@
+// Package fxrate supports loading and querying foreign exchange rates.
+package fxrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("fx rate not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Save records a rate. Loading the same pair as of the same time again
+// replaces the rate, so a rate table can be loaded more than once.
+func (s *Store) Save(ctx context.Context, nr NewRate) (Rate, error) {
+	if err := validation.Check(nr); err != nil {
+		return Rate{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	r := Rate{
+		Base:        nr.Base,
+		Quote:       nr.Quote,
+		Rate:        nr.Rate,
+		AsOf:        nr.AsOf.UTC(),
+		DateCreated: time.Now(),
+	}
+
+	const q = `
+		INSERT INTO fx_rates
+			(base, quote, rate, as_of, date_created)
+		VALUES
+			(:base, :quote, :rate, :as_of, :date_created)
+		ON CONFLICT (base, quote, as_of) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			date_created = EXCLUDED.date_created
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, r); err != nil {
+		return Rate{}, fmt.Errorf("inserting fx rate: %w", err)
+	}
+
+	return r, nil
+}
+
+// QueryLatest retrieves the most recent rate from base to quote known at the
+// given time.
+func (s *Store) QueryLatest(ctx context.Context, base string, quote string, at time.Time) (Rate, error) {
+	data := struct {
+		Base  string    `db:"base"`
+		Quote string    `db:"quote"`
+		At    time.Time `db:"at"`
+	}{
+		Base:  base,
+		Quote: quote,
+		At:    at.UTC(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			fx_rates
+		WHERE
+			base = :base AND quote = :quote AND as_of <= :at
+		ORDER BY
+			as_of DESC
+		LIMIT 1
+		`
+
+	var r Rate
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &r); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Rate{}, ErrNotFound
+		}
+		return Rate{}, fmt.Errorf("selecting fx rate base[%s] quote[%s]: %w", base, quote, err)
+	}
+
+	return r, nil
+}
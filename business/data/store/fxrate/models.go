@@ -0,0 +1,23 @@
+package fxrate
+
+import (
+	"time"
+)
+
+// Rate is what one major unit of the base currency was worth in the quote
+// currency as of a point in time. Rate is a decimal kept as text, so it is
+// never rounded through a float.
+type Rate struct {
+	Base        string    `db:"base" json:"base"`
+	Quote       string    `db:"quote" json:"quote"`
+	Rate        string    `db:"rate" json:"rate"`
+	AsOf        time.Time `db:"as_of" json:"asOf"`
+	DateCreated time.Time `db:"date_created" json:"dateCreated"`
+}
+
+type NewRate struct {
+	Base  string    `json:"base" validate:"required,iso4217"`
+	Quote string    `json:"quote" validate:"required,iso4217,nefield=Base"`
+	Rate  string    `json:"rate" validate:"required,numeric"`
+	AsOf  time.Time `json:"asOf" validate:"required"`
+}
@@ -0,0 +1,339 @@
+// Package guest supports storing guest profiles and the guests staying on
+// each reservation.
+package guest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound        = errors.New("guest not found")
+	ErrInUse           = errors.New("guest is still on reservations")
+	ErrAlreadyAttached = errors.New("guest is already on the reservation")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, ng NewGuest) (Guest, error) {
+
+	if err := validation.Check(ng); err != nil {
+		return Guest{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	g := Guest{
+		ID:               uuid.New(),
+		UserID:           ng.UserID,
+		Name:             ng.Name,
+		Email:            ng.Email,
+		Phone:            ng.Phone,
+		Nationality:      ng.Nationality,
+		DocumentType:     ng.DocumentType,
+		DocumentNumber:   ng.DocumentNumber,
+		Preferences:      database.StringArray(ng.Preferences),
+		MarketingConsent: ng.MarketingConsent,
+		DateCreated:      now,
+		DateUpdated:      now,
+	}
+	if g.Preferences == nil {
+		g.Preferences = database.StringArray{}
+	}
+
+	const q = `
+		INSERT INTO guests
+			(guest_id, user_id, name, email, phone, nationality, document_type, document_number, preferences, marketing_consent, date_created, date_updated)
+		VALUES
+			(:guest_id, :user_id, :name, :email, :phone, :nationality, :document_type, :document_number, :preferences, :marketing_consent, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, g); err != nil {
+		return Guest{}, fmt.Errorf("inserting guest: %w", err)
+	}
+
+	return g, nil
+}
+
+func (s *Store) Update(ctx context.Context, g Guest, ug UpdateGuest) (Guest, error) {
+
+	if err := validation.Check(ug); err != nil {
+		return Guest{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if ug.Name != nil {
+		g.Name = *ug.Name
+	}
+	if ug.Email != nil {
+		g.Email = *ug.Email
+	}
+	if ug.Phone != nil {
+		g.Phone = *ug.Phone
+	}
+	if ug.Nationality != nil {
+		g.Nationality = *ug.Nationality
+	}
+	if ug.DocumentType != nil {
+		g.DocumentType = *ug.DocumentType
+	}
+	if ug.DocumentNumber != nil {
+		g.DocumentNumber = *ug.DocumentNumber
+	}
+	if ug.Preferences != nil {
+		g.Preferences = ug.Preferences
+	}
+	if ug.MarketingConsent != nil {
+		g.MarketingConsent = *ug.MarketingConsent
+	}
+	g.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			guests
+		SET
+			"name" = :name,
+			"email" = :email,
+			"phone" = :phone,
+			"nationality" = :nationality,
+			"document_type" = :document_type,
+			"document_number" = :document_number,
+			"preferences" = :preferences,
+			"marketing_consent" = :marketing_consent,
+			"date_updated" = :date_updated
+		WHERE
+			guest_id = :guest_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, g); err != nil {
+		return Guest{}, fmt.Errorf("updating guestID[%s]: %w", g.ID, err)
+	}
+
+	return g, nil
+}
+
+func (s *Store) Delete(ctx context.Context, g Guest) error {
+	data := struct {
+		GuestID string `db:"guest_id"`
+	}{
+		GuestID: g.ID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			guests
+		WHERE
+			guest_id = :guest_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return ErrInUse
+		}
+		return fmt.Errorf("deleting guestID[%s]: %w", g.ID, err)
+	}
+
+	return nil
+}
+
+// Query searches guest profiles. An empty filter lists every guest.
+func (s *Store) Query(ctx context.Context, filter Filter, pageNumber int, rowsPerPage int) ([]Guest, error) {
+	data := struct {
+		Name           string          `db:"name"`
+		Email          string          `db:"email"`
+		Phone          string          `db:"phone"`
+		DocumentNumber database.Secret `db:"document_number"`
+		Offset         int             `db:"offset"`
+		RowsPerPage    int             `db:"rows_per_page"`
+	}{
+		Name:           filter.Name,
+		Email:          filter.Email,
+		Phone:          filter.Phone,
+		DocumentNumber: filter.DocumentNumber,
+		Offset:         (pageNumber - 1) * rowsPerPage,
+		RowsPerPage:    rowsPerPage,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			guests
+		WHERE
+			(:name = '' OR name ILIKE '%' || :name || '%') AND
+			(:email = '' OR email ILIKE '%' || :email || '%') AND
+			(:phone = '' OR phone = :phone) AND
+			(:document_number = '' OR document_number = :document_number)
+		ORDER BY
+			name, guest_id
+		OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
+		`
+
+	var gs []Guest
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &gs); err != nil {
+		return nil, fmt.Errorf("selecting guests: %w", err)
+	}
+
+	return gs, nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, guestID uuid.UUID) (Guest, error) {
+	data := struct {
+		GuestID string `db:"guest_id"`
+	}{
+		GuestID: guestID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			guests
+		WHERE
+			guest_id = :guest_id
+		`
+	var g Guest
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &g); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Guest{}, ErrNotFound
+		}
+		return Guest{}, fmt.Errorf("selecting guestID[%q]: %w", guestID, err)
+	}
+
+	return g, nil
+}
+
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Guest, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			guests
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			name
+		`
+	var gs []Guest
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &gs); err != nil {
+		return nil, fmt.Errorf("selecting guests userID[%q]: %w", userID, err)
+	}
+
+	return gs, nil
+}
+
+// AddOccupant puts a guest on a reservation.
+func (s *Store) AddOccupant(ctx context.Context, reservationID uuid.UUID, no NewOccupant) error {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+		GuestID       string `db:"guest_id"`
+		Primary       bool   `db:"is_primary"`
+	}{
+		ReservationID: reservationID.String(),
+		GuestID:       no.GuestID.String(),
+		Primary:       no.Primary,
+	}
+
+	const q = `
+		INSERT INTO reservation_guests
+			(reservation_id, guest_id, is_primary)
+		VALUES
+			(:reservation_id, :guest_id, :is_primary)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return ErrAlreadyAttached
+		}
+		return fmt.Errorf("inserting occupant reservationID[%s]: %w", reservationID, err)
+	}
+
+	return nil
+}
+
+// RemoveOccupant takes a guest off a reservation.
+func (s *Store) RemoveOccupant(ctx context.Context, reservationID uuid.UUID, guestID uuid.UUID) error {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+		GuestID       string `db:"guest_id"`
+	}{
+		ReservationID: reservationID.String(),
+		GuestID:       guestID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			reservation_guests
+		WHERE
+			reservation_id = :reservation_id AND
+			guest_id = :guest_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting occupant reservationID[%s]: %w", reservationID, err)
+	}
+
+	return nil
+}
+
+// QueryOccupants returns the guests staying on a reservation, primary guest
+// first.
+func (s *Store) QueryOccupants(ctx context.Context, reservationID uuid.UUID) ([]Occupant, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			g.*,
+			rg.reservation_id,
+			rg.is_primary
+		FROM
+			reservation_guests rg
+		JOIN
+			guests g ON g.guest_id = rg.guest_id
+		WHERE
+			rg.reservation_id = :reservation_id
+		ORDER BY
+			rg.is_primary DESC, g.name
+		`
+	var ocs []Occupant
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ocs); err != nil {
+		return nil, fmt.Errorf("selecting occupants reservationID[%q]: %w", reservationID, err)
+	}
+
+	return ocs, nil
+}
@@ -0,0 +1,80 @@
+package guest
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/sys/database"
+)
+
+// Set of identity documents a guest can register with.
+const (
+	DocumentPassport      = "PASSPORT"
+	DocumentNationalID    = "NATIONAL_ID"
+	DocumentDriverLicense = "DRIVER_LICENSE"
+)
+
+// Guest is a person staying at a property. Unlike a user, a guest doesn't
+// need an account to sign in with. UserID optionally links the profile to
+// the account that manages it, such as the guest's own or that of whoever
+// booked for a family. The document number is a database.Secret so it never
+// shows up in logged queries.
+type Guest struct {
+	ID               uuid.UUID            `db:"guest_id" json:"id"`
+	UserID           *uuid.UUID           `db:"user_id" json:"userID,omitempty"`
+	Name             string               `db:"name" json:"name"`
+	Email            string               `db:"email" json:"email"`
+	Phone            string               `db:"phone" json:"phone"`
+	Nationality      string               `db:"nationality" json:"nationality"`
+	DocumentType     string               `db:"document_type" json:"documentType"`
+	DocumentNumber   database.Secret      `db:"document_number" json:"documentNumber"`
+	Preferences      database.StringArray `db:"preferences" json:"preferences"`
+	MarketingConsent bool                 `db:"marketing_consent" json:"marketingConsent"`
+	DateCreated      time.Time            `db:"date_created" json:"dateCreated"`
+	DateUpdated      time.Time            `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewGuest struct {
+	UserID           *uuid.UUID      `json:"userID"`
+	Name             string          `json:"name" validate:"required"`
+	Email            string          `json:"email" validate:"omitempty,email"`
+	Phone            string          `json:"phone" validate:"omitempty,e164"`
+	Nationality      string          `json:"nationality" validate:"omitempty,iso3166_1_alpha2"`
+	DocumentType     string          `json:"documentType" validate:"omitempty,oneof=PASSPORT NATIONAL_ID DRIVER_LICENSE"`
+	DocumentNumber   database.Secret `json:"documentNumber" validate:"required_with=DocumentType"`
+	Preferences      []string        `json:"preferences"`
+	MarketingConsent bool            `json:"marketingConsent"`
+}
+
+type UpdateGuest struct {
+	Name             *string          `json:"name"`
+	Email            *string          `json:"email" validate:"omitempty,email"`
+	Phone            *string          `json:"phone" validate:"omitempty,e164"`
+	Nationality      *string          `json:"nationality" validate:"omitempty,iso3166_1_alpha2"`
+	DocumentType     *string          `json:"documentType" validate:"omitempty,oneof=PASSPORT NATIONAL_ID DRIVER_LICENSE"`
+	DocumentNumber   *database.Secret `json:"documentNumber"`
+	Preferences      []string         `json:"preferences"`
+	MarketingConsent *bool            `json:"marketingConsent"`
+}
+
+// Filter holds the search criteria for guests. Every set field must match,
+// names and emails by case-insensitive substring, the rest exactly.
+type Filter struct {
+	Name           string
+	Email          string
+	Phone          string
+	DocumentNumber database.Secret
+}
+
+// Occupant is a guest staying on a reservation. Every reservation has at
+// most one primary guest, the one the booking is in the name of.
+type Occupant struct {
+	Guest
+	ReservationID uuid.UUID `db:"reservation_id" json:"reservationID"`
+	Primary       bool      `db:"is_primary" json:"primary"`
+}
+
+type NewOccupant struct {
+	GuestID uuid.UUID `json:"guestID" validate:"required"`
+	Primary bool      `json:"primary"`
+}
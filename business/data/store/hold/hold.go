@@ -0,0 +1,159 @@
+// Package hold supports storing the short-lived holds guests place on a room
+// while they check out.
+package hold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("hold not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, h Hold) error {
+	const q = `
+		INSERT INTO holds
+			(hold_id, property_id, room_type_id, user_id, check_in, check_out, guests, rate_plan_id, status, expires_at, date_created, date_updated)
+		VALUES
+			(:hold_id, :property_id, :room_type_id, :user_id, :check_in, :check_out, :guests, :rate_plan_id, :status, :expires_at, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, h); err != nil {
+		return fmt.Errorf("inserting hold: %w", err)
+	}
+
+	return nil
+}
+
+// Update persists the status and expiry of a hold.
+func (s *Store) Update(ctx context.Context, h Hold) error {
+	const q = `
+		UPDATE
+			holds
+		SET
+			"status" = :status,
+			"expires_at" = :expires_at,
+			"date_updated" = :date_updated
+		WHERE
+			hold_id = :hold_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, h); err != nil {
+		return fmt.Errorf("updating holdID[%s]: %w", h.ID, err)
+	}
+
+	return nil
+}
+
+// Expire marks every active hold whose time ran out by now as EXPIRED and
+// returns them.
+func (s *Store) Expire(ctx context.Context, now time.Time) ([]Hold, error) {
+	data := struct {
+		Status string    `db:"status"`
+		Now    time.Time `db:"now"`
+	}{
+		Status: StatusExpired,
+		Now:    now,
+	}
+
+	const q = `
+		UPDATE
+			holds
+		SET
+			"status" = :status,
+			"date_updated" = :now
+		WHERE
+			status = 'ACTIVE' AND
+			expires_at <= :now
+		RETURNING
+			*
+		`
+
+	var hs []Hold
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &hs); err != nil {
+		return nil, fmt.Errorf("expiring holds: %w", err)
+	}
+
+	return hs, nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, holdID uuid.UUID) (Hold, error) {
+	data := struct {
+		HoldID string `db:"hold_id"`
+	}{
+		HoldID: holdID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			holds
+		WHERE
+			hold_id = :hold_id
+		`
+	var h Hold
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &h); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Hold{}, ErrNotFound
+		}
+		return Hold{}, fmt.Errorf("selecting holdID[%q]: %w", holdID, err)
+	}
+
+	return h, nil
+}
+
+// QueryByIDForUpdate gets the specified hold and locks its row until the
+// surrounding transaction ends.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, holdID uuid.UUID) (Hold, error) {
+	data := struct {
+		HoldID string `db:"hold_id"`
+	}{
+		HoldID: holdID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			holds
+		WHERE
+			hold_id = :hold_id
+		FOR UPDATE
+		`
+	var h Hold
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &h); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Hold{}, ErrNotFound
+		}
+		return Hold{}, fmt.Errorf("selecting holdID[%q] for update: %w", holdID, err)
+	}
+
+	return h, nil
+}
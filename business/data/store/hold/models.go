@@ -0,0 +1,54 @@
+package hold
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses a hold can be in. Only ACTIVE holds that have not yet
+// expired take a room out of availability.
+const (
+	StatusActive    = "ACTIVE"
+	StatusReleased  = "RELEASED"
+	StatusExpired   = "EXPIRED"
+	StatusConverted = "CONVERTED"
+)
+
+// DefaultMinutes is how long a hold lasts when the guest doesn't ask for a
+// specific duration.
+const DefaultMinutes = 15
+
+type Hold struct {
+	ID          uuid.UUID  `db:"hold_id" json:"id"`
+	PropertyID  uuid.UUID  `db:"property_id" json:"propertyID"`
+	RoomTypeID  uuid.UUID  `db:"room_type_id" json:"roomTypeID"`
+	UserID      uuid.UUID  `db:"user_id" json:"userID"`
+	CheckIn     time.Time  `db:"check_in" json:"checkIn"`
+	CheckOut    time.Time  `db:"check_out" json:"checkOut"`
+	Guests      int        `db:"guests" json:"guests"`
+	RatePlanID  *uuid.UUID `db:"rate_plan_id" json:"ratePlanID,omitempty"`
+	Status      string     `db:"status" json:"status"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expiresAt"`
+	DateCreated time.Time  `db:"date_created" json:"dateCreated"`
+	DateUpdated time.Time  `db:"date_updated" json:"dateUpdated"`
+}
+
+// Active reports whether the hold still holds its room at time now.
+func (h Hold) Active(now time.Time) bool {
+	return h.Status == StatusActive && h.ExpiresAt.After(now)
+}
+
+type NewHold struct {
+	RoomTypeID uuid.UUID  `json:"roomTypeID" validate:"required"`
+	CheckIn    time.Time  `json:"checkIn" validate:"required"`
+	CheckOut   time.Time  `json:"checkOut" validate:"required,gtfield=CheckIn"`
+	Guests     int        `json:"guests" validate:"required,gte=1"`
+	RatePlanID *uuid.UUID `json:"ratePlanID"`
+	Minutes    int        `json:"minutes" validate:"omitempty,gte=1,lte=60"`
+	UserID     uuid.UUID  `json:"userID"`
+}
+
+type ExtendHold struct {
+	Minutes int `json:"minutes" validate:"required,gte=1,lte=60"`
+}
@@ -0,0 +1,241 @@
+// Package housekeeping supports CRUD operations on the cleaning tasks of
+// rooms and the daily housekeeping board.
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound      = errors.New("housekeeping task not found")
+	ErrUnknownRoom   = errors.New("housekeeping task room or assignee not found")
+	ErrAlreadyTasked = errors.New("room already has a housekeeping task that day")
+)
+
+// dayLayout is how days are bound to DATE columns.
+const dayLayout = "2006-01-02"
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, nt NewTask) (Task, error) {
+	if err := validation.Check(nt); err != nil {
+		return Task{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	t := Task{
+		ID:          uuid.New(),
+		RoomID:      nt.RoomID,
+		AssigneeID:  nt.AssigneeID,
+		Day:         day(nt.Day),
+		Note:        nt.Note,
+		UserID:      nt.UserID,
+		DateCreated: time.Now(),
+	}
+
+	const q = `
+		INSERT INTO housekeeping_tasks
+			(task_id, room_id, assignee_id, day, note, user_id, date_completed, date_created)
+		VALUES
+			(:task_id, :room_id, :assignee_id, :day, :note, :user_id, :date_completed, :date_created)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, t); err != nil {
+		switch {
+		case errors.Is(err, database.ErrDBForeignKey):
+			return Task{}, ErrUnknownRoom
+		case errors.Is(err, database.ErrDBDuplicatedEntry):
+			return Task{}, ErrAlreadyTasked
+		default:
+			return Task{}, fmt.Errorf("inserting housekeeping task: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+func (s *Store) Delete(ctx context.Context, t Task) error {
+	data := struct {
+		TaskID string `db:"task_id"`
+	}{
+		TaskID: t.ID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			housekeeping_tasks
+		WHERE
+			task_id = :task_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting taskID[%s]: %w", t.ID, err)
+	}
+
+	return nil
+}
+
+// Complete marks the task of the room on the day as done, if it has one.
+func (s *Store) Complete(ctx context.Context, roomID uuid.UUID, d time.Time, now time.Time) error {
+	data := struct {
+		RoomID        string    `db:"room_id"`
+		Day           string    `db:"day"`
+		DateCompleted time.Time `db:"date_completed"`
+	}{
+		RoomID:        roomID.String(),
+		Day:           d.Format(dayLayout),
+		DateCompleted: now,
+	}
+
+	const q = `
+		UPDATE
+			housekeeping_tasks
+		SET
+			"date_completed" = :date_completed
+		WHERE
+			room_id = :room_id AND day = :day AND date_completed IS NULL
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("completing roomID[%s] day[%s]: %w", roomID, data.Day, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, taskID uuid.UUID) (Task, error) {
+	data := struct {
+		TaskID string `db:"task_id"`
+	}{
+		TaskID: taskID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			housekeeping_tasks
+		WHERE
+			task_id = :task_id
+		`
+
+	var t Task
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &t); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Task{}, ErrNotFound
+		}
+		return Task{}, fmt.Errorf("selecting taskID[%q]: %w", taskID, err)
+	}
+
+	return t, nil
+}
+
+// QueryByAssignee returns the tasks of a member of staff on the day.
+func (s *Store) QueryByAssignee(ctx context.Context, assigneeID uuid.UUID, d time.Time) ([]Task, error) {
+	data := struct {
+		AssigneeID string `db:"assignee_id"`
+		Day        string `db:"day"`
+	}{
+		AssigneeID: assigneeID.String(),
+		Day:        d.Format(dayLayout),
+	}
+
+	const q = `
+		SELECT
+			t.*
+		FROM
+			housekeeping_tasks AS t
+		JOIN
+			rooms AS r ON r.room_id = t.room_id
+		WHERE
+			t.assignee_id = :assignee_id AND t.day = :day
+		ORDER BY
+			r.floor, r.number
+		`
+
+	var ts []Task
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ts); err != nil {
+		return nil, fmt.Errorf("selecting tasks assigneeID[%q] day[%s]: %w", assigneeID, data.Day, err)
+	}
+
+	return ts, nil
+}
+
+// Board returns every room of the property with its housekeeping status on
+// the day, its task if it has one, and whether a guest leaves it or is due
+// to arrive in it.
+func (s *Store) Board(ctx context.Context, propertyID uuid.UUID, d time.Time) ([]BoardRoom, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+		Day        string `db:"day"`
+	}{
+		PropertyID: propertyID.String(),
+		Day:        d.Format(dayLayout),
+	}
+
+	const q = `
+		SELECT
+			r.room_id,
+			r.number,
+			r.floor,
+			r.status,
+			r.housekeeping,
+			t.task_id,
+			t.assignee_id,
+			t.date_completed,
+			EXISTS (
+				SELECT 1 FROM reservations AS res
+				WHERE res.room_id = r.room_id AND res.status = 'CHECKED_IN' AND res.check_out = :day
+			) AS departure,
+			EXISTS (
+				SELECT 1 FROM reservations AS res
+				WHERE res.room_id = r.room_id AND res.status = 'CONFIRMED' AND res.check_in = :day
+			) AS arrival
+		FROM
+			rooms AS r
+		LEFT JOIN
+			housekeeping_tasks AS t ON t.room_id = r.room_id AND t.day = :day
+		WHERE
+			r.property_id = :property_id
+		ORDER BY
+			r.floor, r.number
+		`
+
+	var rs []BoardRoom
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rs); err != nil {
+		return nil, fmt.Errorf("selecting board propertyID[%q] day[%s]: %w", propertyID, data.Day, err)
+	}
+
+	return rs, nil
+}
+
+// day truncates t to the calendar day it falls on.
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
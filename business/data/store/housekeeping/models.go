@@ -0,0 +1,43 @@
+package housekeeping
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Task is a room a member of the housekeeping staff has to clean on a day.
+// A room has at most one task per day.
+type Task struct {
+	ID            uuid.UUID  `db:"task_id" json:"id"`
+	RoomID        uuid.UUID  `db:"room_id" json:"roomID"`
+	AssigneeID    uuid.UUID  `db:"assignee_id" json:"assigneeID"`
+	Day           time.Time  `db:"day" json:"day"`
+	Note          string     `db:"note" json:"note"`
+	UserID        uuid.UUID  `db:"user_id" json:"userID"`
+	DateCompleted *time.Time `db:"date_completed" json:"dateCompleted,omitempty"`
+	DateCreated   time.Time  `db:"date_created" json:"dateCreated"`
+}
+
+type NewTask struct {
+	RoomID     uuid.UUID `json:"roomID" validate:"required"`
+	AssigneeID uuid.UUID `json:"assigneeID" validate:"required"`
+	Day        time.Time `json:"day" validate:"required"`
+	Note       string    `json:"note"`
+	UserID     uuid.UUID `json:"-"`
+}
+
+// BoardRoom is a line of the daily housekeeping board: a room, its status,
+// who cleans it that day and whether guests leave or arrive in it.
+type BoardRoom struct {
+	RoomID        uuid.UUID  `db:"room_id" json:"roomID"`
+	Number        string     `db:"number" json:"number"`
+	Floor         int        `db:"floor" json:"floor"`
+	Status        string     `db:"status" json:"status"`
+	Housekeeping  string     `db:"housekeeping" json:"housekeeping"`
+	TaskID        *uuid.UUID `db:"task_id" json:"taskID,omitempty"`
+	AssigneeID    *uuid.UUID `db:"assignee_id" json:"assigneeID,omitempty"`
+	DateCompleted *time.Time `db:"date_completed" json:"dateCompleted,omitempty"`
+	Departure     bool       `db:"departure" json:"departure"`
+	Arrival       bool       `db:"arrival" json:"arrival"`
+}
@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of kinds of message the outbox carries.
+const (
+	KindWaitlistOffer = "WAITLIST_OFFER"
+)
+
+// Message is a notification waiting to be delivered to a user. Messages are
+// written in the same transaction as the change they announce, so a
+// notification is recorded if and only if that change commits.
+type Message struct {
+	ID          uuid.UUID  `db:"message_id" json:"id"`
+	Kind        string     `db:"kind" json:"kind"`
+	UserID      uuid.UUID  `db:"user_id" json:"userID"`
+	Payload     string     `db:"payload" json:"payload"`
+	DateCreated time.Time  `db:"date_created" json:"dateCreated"`
+	DateSent    *time.Time `db:"date_sent" json:"dateSent,omitempty"`
+}
+
+// WaitlistOffer is the payload of a KindWaitlistOffer message.
+type WaitlistOffer struct {
+	WaitlistID uuid.UUID `json:"waitlistID"`
+	HoldID     uuid.UUID `json:"holdID"`
+	RoomTypeID uuid.UUID `json:"roomTypeID"`
+	CheckIn    time.Time `json:"checkIn"`
+	CheckOut   time.Time `json:"checkOut"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,146 @@
+// Package outbox supports storing notifications until they are delivered.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Create records a message of the given kind for the user, encoding payload
+// as JSON.
+func (s *Store) Create(ctx context.Context, kind string, userID uuid.UUID, payload any, now time.Time) (Message, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("encoding payload: %w", err)
+	}
+
+	m := Message{
+		ID:          uuid.New(),
+		Kind:        kind,
+		UserID:      userID,
+		Payload:     string(b),
+		DateCreated: now,
+	}
+
+	const q = `
+		INSERT INTO outbox
+			(message_id, kind, user_id, payload, date_created, date_sent)
+		VALUES
+			(:message_id, :kind, :user_id, :payload, :date_created, :date_sent)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, m); err != nil {
+		return Message{}, fmt.Errorf("inserting message: %w", err)
+	}
+
+	return m, nil
+}
+
+// QueryPending returns up to limit messages that have not been sent yet,
+// oldest first.
+func (s *Store) QueryPending(ctx context.Context, limit int) ([]Message, error) {
+	data := struct {
+		Limit int `db:"limit"`
+	}{
+		Limit: limit,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			outbox
+		WHERE
+			date_sent IS NULL
+		ORDER BY
+			date_created, message_id
+		LIMIT :limit
+		`
+
+	var ms []Message
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ms); err != nil {
+		return nil, fmt.Errorf("selecting pending messages: %w", err)
+	}
+
+	return ms, nil
+}
+
+// QueryByUserID returns every message for the user, newest first.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Message, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			outbox
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			date_created DESC
+		`
+
+	var ms []Message
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ms); err != nil {
+		return nil, fmt.Errorf("selecting messages userID[%q]: %w", userID, err)
+	}
+
+	return ms, nil
+}
+
+// MarkSent records that the message was delivered at now.
+func (s *Store) MarkSent(ctx context.Context, messageID uuid.UUID, now time.Time) error {
+	data := struct {
+		MessageID string    `db:"message_id"`
+		Now       time.Time `db:"now"`
+	}{
+		MessageID: messageID.String(),
+		Now:       now,
+	}
+
+	const q = `
+		UPDATE
+			outbox
+		SET
+			"date_sent" = :now
+		WHERE
+			message_id = :message_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("updating messageID[%s]: %w", messageID, err)
+	}
+
+	return nil
+}
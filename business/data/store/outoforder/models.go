@@ -0,0 +1,29 @@
+package outoforder
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Period takes a room out of inventory every night from Starts up to, but not
+// including, Ends, such as while it is repaired.
+type Period struct {
+	ID          uuid.UUID `db:"period_id" json:"id"`
+	PropertyID  uuid.UUID `db:"property_id" json:"propertyID"`
+	RoomID      uuid.UUID `db:"room_id" json:"roomID"`
+	Starts      time.Time `db:"starts" json:"starts"`
+	Ends        time.Time `db:"ends" json:"ends"`
+	Reason      string    `db:"reason" json:"reason"`
+	UserID      uuid.UUID `db:"user_id" json:"userID"`
+	DateCreated time.Time `db:"date_created" json:"dateCreated"`
+}
+
+type NewPeriod struct {
+	RoomID     uuid.UUID `json:"roomID" validate:"required"`
+	Starts     time.Time `json:"starts" validate:"required"`
+	Ends       time.Time `json:"ends" validate:"required,gtfield=Starts"`
+	Reason     string    `json:"reason" validate:"required"`
+	PropertyID uuid.UUID `json:"-"`
+	UserID     uuid.UUID `json:"-"`
+}
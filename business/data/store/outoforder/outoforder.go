@@ -0,0 +1,184 @@
+// Package outoforder supports CRUD operations on the periods rooms are out of
+// order and cannot be sold.
+package outoforder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("out-of-order period not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, np NewPeriod) (Period, error) {
+	if err := validation.Check(np); err != nil {
+		return Period{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	p := Period{
+		ID:          uuid.New(),
+		PropertyID:  np.PropertyID,
+		RoomID:      np.RoomID,
+		Starts:      np.Starts,
+		Ends:        np.Ends,
+		Reason:      np.Reason,
+		UserID:      np.UserID,
+		DateCreated: time.Now(),
+	}
+
+	const q = `
+		INSERT INTO out_of_order
+			(period_id, property_id, room_id, starts, ends, reason, user_id, date_created)
+		VALUES
+			(:period_id, :property_id, :room_id, :starts, :ends, :reason, :user_id, :date_created)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, p); err != nil {
+		return Period{}, fmt.Errorf("inserting out-of-order period: %w", err)
+	}
+
+	return p, nil
+}
+
+func (s *Store) Delete(ctx context.Context, p Period) error {
+	data := struct {
+		PeriodID string `db:"period_id"`
+	}{
+		PeriodID: p.ID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			out_of_order
+		WHERE
+			period_id = :period_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting periodID[%s]: %w", p.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, periodID uuid.UUID) (Period, error) {
+	data := struct {
+		PeriodID string `db:"period_id"`
+	}{
+		PeriodID: periodID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			out_of_order
+		WHERE
+			period_id = :period_id
+		`
+
+	var p Period
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &p); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Period{}, ErrNotFound
+		}
+		return Period{}, fmt.Errorf("selecting periodID[%q]: %w", periodID, err)
+	}
+
+	return p, nil
+}
+
+// QueryByPropertyID returns the periods of the property's rooms covering any
+// night between from and to.
+func (s *Store) QueryByPropertyID(ctx context.Context, propertyID uuid.UUID, from time.Time, to time.Time) ([]Period, error) {
+	data := struct {
+		PropertyID string    `db:"property_id"`
+		From       time.Time `db:"from"`
+		To         time.Time `db:"to"`
+	}{
+		PropertyID: propertyID.String(),
+		From:       from,
+		To:         to,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			out_of_order
+		WHERE
+			property_id = :property_id AND
+			starts < :to AND
+			ends > :from
+		ORDER BY
+			starts, period_id
+		`
+
+	var ps []Period
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ps); err != nil {
+		return nil, fmt.Errorf("selecting periods propertyID[%q]: %w", propertyID, err)
+	}
+
+	return ps, nil
+}
+
+// QueryByRoomID returns the periods of the room covering any night between
+// from and to.
+func (s *Store) QueryByRoomID(ctx context.Context, roomID uuid.UUID, from time.Time, to time.Time) ([]Period, error) {
+	data := struct {
+		RoomID string    `db:"room_id"`
+		From   time.Time `db:"from"`
+		To     time.Time `db:"to"`
+	}{
+		RoomID: roomID.String(),
+		From:   from,
+		To:     to,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			out_of_order
+		WHERE
+			room_id = :room_id AND
+			starts < :to AND
+			ends > :from
+		ORDER BY
+			starts, period_id
+		`
+
+	var ps []Period
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ps); err != nil {
+		return nil, fmt.Errorf("selecting periods roomID[%q]: %w", roomID, err)
+	}
+
+	return ps, nil
+}
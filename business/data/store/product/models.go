@@ -0,0 +1,31 @@
+package product
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/sys/money"
+)
+
+type Product struct {
+	ID          uuid.UUID   `db:"product_id" json:"id"`
+	Name        string      `db:"name" json:"name"`
+	Cost        money.Money `db:"cost" json:"cost"`
+	Quantity    int         `db:"quantity" json:"quantity"`
+	UserID      uuid.UUID   `db:"user_id" json:"userID"`
+	DateCreated time.Time   `db:"date_created" json:"dateCreated"`
+	DateUpdated time.Time   `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewProduct struct {
+	Name     string      `json:"name" validate:"required"`
+	Cost     money.Money `json:"cost"`
+	Quantity int         `json:"quantity" validate:"gte=1"`
+	UserID   uuid.UUID   `json:"userID"`
+}
+
+type UpdateProduct struct {
+	Name     *string      `json:"name"`
+	Cost     *money.Money `json:"cost"`
+	Quantity *int         `json:"quantity" validate:"omitempty,gte=1"`
+}
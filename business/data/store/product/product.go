@@ -0,0 +1,282 @@
+// Package product supports CRUD operations
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound  = errors.New("product not found")
+	ErrForbidden = errors.New("forbidden operation")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, np NewProduct) (Product, error) {
+
+	if err := validation.Check(np); err != nil {
+		return Product{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	prd := Product{
+		ID:          uuid.New(),
+		Name:        np.Name,
+		Cost:        np.Cost,
+		Quantity:    np.Quantity,
+		UserID:      np.UserID,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	const q = `
+		INSERT INTO products
+			(product_id, user_id, name, cost, quantity, date_created, date_updated)
+		VALUES
+			(:product_id, :user_id, :name, :cost, :quantity, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, prd); err != nil {
+		return Product{}, fmt.Errorf("inserting product: %w", err)
+	}
+
+	return prd, nil
+}
+
+func (s *Store) Update(ctx context.Context, claims auth.Claims, prd Product, up UpdateProduct) (Product, error) {
+
+	if !owned(claims, prd) {
+		return Product{}, ErrForbidden
+	}
+
+	if err := validation.Check(up); err != nil {
+		return Product{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if up.Name != nil {
+		prd.Name = *up.Name
+	}
+	if up.Cost != nil {
+		prd.Cost = *up.Cost
+	}
+	if up.Quantity != nil {
+		prd.Quantity = *up.Quantity
+	}
+	prd.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			products
+		SET
+			"name" = :name,
+			"cost" = :cost,
+			"quantity" = :quantity,
+			"date_updated" = :date_updated
+		WHERE
+			product_id = :product_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, prd); err != nil {
+		return Product{}, fmt.Errorf("updating productID[%s]: %w", prd.ID, err)
+	}
+
+	return prd, nil
+}
+
+// UpdateQuantity adds delta to the quantity in stock for the product.
+func (s *Store) UpdateQuantity(ctx context.Context, productID uuid.UUID, delta int) error {
+	data := struct {
+		ProductID   string    `db:"product_id"`
+		Delta       int       `db:"delta"`
+		DateUpdated time.Time `db:"date_updated"`
+	}{
+		ProductID:   productID.String(),
+		Delta:       delta,
+		DateUpdated: time.Now(),
+	}
+
+	const q = `
+		UPDATE
+			products
+		SET
+			"quantity" = quantity + :delta,
+			"date_updated" = :date_updated
+		WHERE
+			product_id = :product_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("updating quantity productID[%s]: %w", productID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, claims auth.Claims, prd Product) error {
+
+	if !owned(claims, prd) {
+		return ErrForbidden
+	}
+
+	data := struct {
+		ProductID string `db:"product_id"`
+	}{
+		ProductID: prd.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		products
+	WHERE
+		product_id = :product_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting productID[%s]: %w", prd.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Product, error) {
+
+	data := struct {
+		Offset      int `db:"offset"`
+		RowsPerPage int `db:"rows_per_page"`
+	}{
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		products
+	ORDER BY
+		product_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
+	`
+
+	var prds []Product
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &prds); err != nil {
+		return nil, fmt.Errorf("selecting products: %w", err)
+	}
+
+	return prds, nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (Product, error) {
+	data := struct {
+		ProductID string `db:"product_id"`
+	}{
+		ProductID: productID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			products
+		WHERE
+			product_id = :product_id
+		`
+	var prd Product
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &prd); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Product{}, ErrNotFound
+		}
+		return Product{}, fmt.Errorf("selecting productID[%q]: %w", productID, err)
+	}
+
+	return prd, nil
+}
+
+// QueryByIDForUpdate retrieves the product and locks its row until the
+// surrounding transaction ends. It must be called on a store returned by Tran.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, productID uuid.UUID) (Product, error) {
+	data := struct {
+		ProductID string `db:"product_id"`
+	}{
+		ProductID: productID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			products
+		WHERE
+			product_id = :product_id
+		FOR UPDATE
+		`
+	var prd Product
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &prd); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Product{}, ErrNotFound
+		}
+		return Product{}, fmt.Errorf("selecting productID[%q] for update: %w", productID, err)
+	}
+
+	return prd, nil
+}
+
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Product, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			products
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			product_id
+		`
+	var prds []Product
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &prds); err != nil {
+		return nil, fmt.Errorf("selecting products userID[%q]: %w", userID, err)
+	}
+
+	return prds, nil
+}
+
+// owned reports whether the claims belong to an admin or to the user that
+// owns the product.
+func owned(claims auth.Claims, prd Product) bool {
+	return claims.Authorized(auth.Admin) || claims.Subject == prd.UserID.String()
+}
@@ -0,0 +1,210 @@
+package product_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+
+	"github.com/tcmhoang/sservices/business/data/store/product"
+	"github.com/tcmhoang/sservices/business/data/tests"
+	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/foundation/docker"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = tests.InitDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer tests.StopDB(c)
+
+	m.Run()
+}
+
+func TestProduct(t *testing.T) {
+	t.Run("crud", crud)
+	t.Run("paging", paging)
+}
+
+func crud(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	store := product.NewStore(stest.Log, stest.DB)
+
+	t.Log("Given the need to work with Product records.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen handling a single Product.", testID)
+		{
+			ctx := context.Background()
+
+			np := product.NewProduct{
+				Name:     "Comic Books",
+				Cost:     money.New(10, "USD"),
+				Quantity: 55,
+				UserID:   uuid.MustParse("45b5fbd3-755f-4379-8f07-a58d4a30fa2f"),
+			}
+
+			prd, err := store.Create(ctx, np)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to create a product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to create a product.", tests.Success, testID)
+
+			saved, err := store.QueryByID(ctx, prd.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve product by ID: %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve product by ID.", tests.Success, testID)
+
+			prd.DateCreated = time.Time{}
+			prd.DateUpdated = time.Time{}
+			saved.DateCreated = time.Time{}
+			saved.DateUpdated = time.Time{}
+
+			if diff := cmp.Diff(prd, saved); diff != "" {
+				t.Fatalf("\t%s\tTest %d:\tShould get back the same product. Diff:\n%s", tests.Failed, testID, diff)
+			}
+			t.Logf("\t%s\tTest %d:\tShould get back the same product.", tests.Success, testID)
+
+			other := auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject:   uuid.NewString(),
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+					IssuedAt:  jwt.NewNumericDate(time.Now()),
+				},
+				Roles: []auth.Role{auth.User},
+			}
+
+			upd := product.UpdateProduct{
+				Name: tests.StringPointer("Graphic Novels"),
+			}
+
+			if _, err := store.Update(ctx, other, saved, upd); !errors.Is(err, product.ErrForbidden) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to update a product owned by another user : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to update a product owned by another user.", tests.Success, testID)
+
+			owner := other
+			owner.Subject = np.UserID.String()
+
+			if _, err := store.Update(ctx, owner, saved, upd); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to update product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to update product.", tests.Success, testID)
+
+			saved, err = store.QueryByID(ctx, prd.ID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve updated product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve updated product.", tests.Success, testID)
+
+			if saved.Name != *upd.Name {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, saved.Name)
+				t.Logf("\t\tTest %d:\tExp: %v", testID, *upd.Name)
+				t.Fatalf("\t%s\tTest %d:\tShould be able to see updates to Name.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to see updates to Name.", tests.Success, testID)
+
+			prds, err := store.QueryByUserID(ctx, np.UserID)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve products by user : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve products by user.", tests.Success, testID)
+
+			if len(prds) != 3 {
+				t.Logf("\t\tTest %d:\tGot: %v", testID, len(prds))
+				t.Logf("\t\tTest %d:\tExp: %v", testID, 3)
+				t.Fatalf("\t%s\tTest %d:\tShould have the seeded products plus the new one.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have the seeded products plus the new one.", tests.Success, testID)
+
+			if err := store.Delete(ctx, other, saved); !errors.Is(err, product.ErrForbidden) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to delete a product owned by another user : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to delete a product owned by another user.", tests.Success, testID)
+
+			if err := store.Delete(ctx, owner, saved); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to delete product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to delete product.", tests.Success, testID)
+
+			_, err = store.QueryByID(ctx, saved.ID)
+			if !errors.Is(err, product.ErrNotFound) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT be able to retrieve product : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT be able to retrieve product.", tests.Success, testID)
+		}
+	}
+}
+
+func paging(t *testing.T) {
+	stest := tests.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		stest.Teardown()
+	}()
+
+	store := product.NewStore(stest.Log, stest.DB)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	t.Log("Given the need to page through Product records.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen paging through 2 products.", testID)
+		{
+			prds1, err := store.Query(ctx, 1, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve products for page 1 : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve products for page 1.", tests.Success, testID)
+
+			if len(prds1) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould have a single product for page 1.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have a single product for page 1.", tests.Success, testID)
+
+			prds2, err := store.Query(ctx, 2, 1)
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to retrieve products for page 2 : %s.", tests.Failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to retrieve products for page 2.", tests.Success, testID)
+
+			if len(prds2) != 1 {
+				t.Fatalf("\t%s\tTest %d:\tShould have a single product for page 2.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have a single product for page 2.", tests.Success, testID)
+
+			if prds1[0].ID == prds2[0].ID {
+				t.Logf("\t\tTest %d:\tProduct1: %v", testID, prds1[0].ID)
+				t.Logf("\t\tTest %d:\tProduct2: %v", testID, prds2[0].ID)
+				t.Fatalf("\t%s\tTest %d:\tShould have different products.", tests.Failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould have different products.", tests.Success, testID)
+		}
+	}
+}
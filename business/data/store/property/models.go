@@ -0,0 +1,40 @@
+package property
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Property struct {
+	ID           uuid.UUID `db:"property_id" json:"id"`
+	Name         string    `db:"name" json:"name"`
+	Address      string    `db:"address" json:"address"`
+	City         string    `db:"city" json:"city"`
+	Country      string    `db:"country" json:"country"`
+	TimeZone     string    `db:"time_zone" json:"timeZone"`
+	Currency     string    `db:"currency" json:"currency"`
+	Jurisdiction string    `db:"jurisdiction" json:"jurisdiction"`
+	DateCreated  time.Time `db:"date_created" json:"dateCreated"`
+	DateUpdated  time.Time `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewProperty struct {
+	Name         string `json:"name" validate:"required"`
+	Address      string `json:"address" validate:"required"`
+	City         string `json:"city" validate:"required"`
+	Country      string `json:"country" validate:"required,iso3166_1_alpha2"`
+	TimeZone     string `json:"timeZone" validate:"required,timezone"`
+	Currency     string `json:"currency" validate:"required,iso4217"`
+	Jurisdiction string `json:"jurisdiction"`
+}
+
+type UpdateProperty struct {
+	Name         *string `json:"name"`
+	Address      *string `json:"address"`
+	City         *string `json:"city"`
+	Country      *string `json:"country" validate:"omitempty,iso3166_1_alpha2"`
+	TimeZone     *string `json:"timeZone" validate:"omitempty,timezone"`
+	Currency     *string `json:"currency" validate:"omitempty,iso4217"`
+	Jurisdiction *string `json:"jurisdiction"`
+}
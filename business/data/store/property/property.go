@@ -0,0 +1,205 @@
+// Package property supports CRUD operations on hotel properties
+package property
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound = errors.New("property not found")
+	ErrInUse    = errors.New("property still has reservations")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, np NewProperty) (Property, error) {
+
+	if err := validation.Check(np); err != nil {
+		return Property{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	prop := Property{
+		ID:           uuid.New(),
+		Name:         np.Name,
+		Address:      np.Address,
+		City:         np.City,
+		Country:      np.Country,
+		TimeZone:     np.TimeZone,
+		Currency:     np.Currency,
+		Jurisdiction: np.Jurisdiction,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	const q = `
+		INSERT INTO properties
+			(property_id, name, address, city, country, time_zone, currency, jurisdiction, date_created, date_updated)
+		VALUES
+			(:property_id, :name, :address, :city, :country, :time_zone, :currency, :jurisdiction, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, prop); err != nil {
+		return Property{}, fmt.Errorf("inserting property: %w", err)
+	}
+
+	return prop, nil
+}
+
+func (s *Store) Update(ctx context.Context, prop Property, up UpdateProperty) (Property, error) {
+
+	if err := validation.Check(up); err != nil {
+		return Property{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if up.Name != nil {
+		prop.Name = *up.Name
+	}
+	if up.Address != nil {
+		prop.Address = *up.Address
+	}
+	if up.City != nil {
+		prop.City = *up.City
+	}
+	if up.Country != nil {
+		prop.Country = *up.Country
+	}
+	if up.TimeZone != nil {
+		prop.TimeZone = *up.TimeZone
+	}
+	if up.Currency != nil {
+		prop.Currency = *up.Currency
+	}
+	if up.Jurisdiction != nil {
+		prop.Jurisdiction = *up.Jurisdiction
+	}
+	prop.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			properties
+		SET
+			"name" = :name,
+			"address" = :address,
+			"city" = :city,
+			"country" = :country,
+			"time_zone" = :time_zone,
+			"currency" = :currency,
+			"jurisdiction" = :jurisdiction,
+			"date_updated" = :date_updated
+		WHERE
+			property_id = :property_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, prop); err != nil {
+		return Property{}, fmt.Errorf("updating propertyID[%s]: %w", prop.ID, err)
+	}
+
+	return prop, nil
+}
+
+func (s *Store) Delete(ctx context.Context, prop Property) error {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: prop.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		properties
+	WHERE
+		property_id = :property_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return ErrInUse
+		}
+		return fmt.Errorf("deleting propertyID[%s]: %w", prop.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]Property, error) {
+
+	data := struct {
+		Offset      int `db:"offset"`
+		RowsPerPage int `db:"rows_per_page"`
+	}{
+		Offset:      (pageNumber - 1) * rowsPerPage,
+		RowsPerPage: rowsPerPage,
+	}
+
+	const q = `
+	SELECT
+		*
+	FROM
+		properties
+	ORDER BY
+		property_id
+	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
+	`
+
+	var props []Property
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &props); err != nil {
+		return nil, fmt.Errorf("selecting properties: %w", err)
+	}
+
+	return props, nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, propertyID uuid.UUID) (Property, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			properties
+		WHERE
+			property_id = :property_id
+		`
+	var prop Property
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &prop); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Property{}, ErrNotFound
+		}
+		return Property{}, fmt.Errorf("selecting propertyID[%q]: %w", propertyID, err)
+	}
+
+	return prop, nil
+}
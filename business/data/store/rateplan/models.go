@@ -0,0 +1,78 @@
+package rateplan
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of penalties charged for cancelling a refundable rate once its free
+// cancellation window has closed.
+const (
+	PenaltyFirstNight = "FIRST_NIGHT"
+	PenaltyPercent    = "PERCENT"
+)
+
+// RatePlan is a way of selling a room type, such as a refundable rate or a
+// rate with breakfast included. Its base rate applies to every night that
+// has neither a weekday rate nor a calendar override.
+//
+// Refundable plans can be cancelled for free until FreeCancelHours before
+// arrival, after which PenaltyType applies. Non-refundable plans always
+// forfeit the whole stay.
+type RatePlan struct {
+	ID                uuid.UUID `db:"rate_plan_id" json:"id"`
+	RoomTypeID        uuid.UUID `db:"room_type_id" json:"roomTypeID"`
+	Name              string    `db:"name" json:"name"`
+	Refundable        bool      `db:"refundable" json:"refundable"`
+	BreakfastIncluded bool      `db:"breakfast_included" json:"breakfastIncluded"`
+	BaseRate          int       `db:"base_rate" json:"baseRate"`
+	MinStay           int       `db:"min_stay" json:"minStay"`
+	FreeCancelHours   int       `db:"free_cancel_hours" json:"freeCancelHours"`
+	PenaltyType       string    `db:"penalty_type" json:"penaltyType"`
+	PenaltyPercent    int       `db:"penalty_percent" json:"penaltyPercent"`
+	DateCreated       time.Time `db:"date_created" json:"dateCreated"`
+	DateUpdated       time.Time `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewRatePlan struct {
+	RoomTypeID        uuid.UUID `json:"roomTypeID" validate:"required"`
+	Name              string    `json:"name" validate:"required"`
+	Refundable        bool      `json:"refundable"`
+	BreakfastIncluded bool      `json:"breakfastIncluded"`
+	BaseRate          int       `json:"baseRate" validate:"gte=0"`
+	MinStay           int       `json:"minStay" validate:"omitempty,gte=1"`
+	FreeCancelHours   int       `json:"freeCancelHours" validate:"gte=0"`
+	PenaltyType       string    `json:"penaltyType" validate:"omitempty,oneof=FIRST_NIGHT PERCENT"`
+	PenaltyPercent    int       `json:"penaltyPercent" validate:"gte=0,lte=100"`
+}
+
+type UpdateRatePlan struct {
+	Name              *string `json:"name"`
+	Refundable        *bool   `json:"refundable"`
+	BreakfastIncluded *bool   `json:"breakfastIncluded"`
+	BaseRate          *int    `json:"baseRate" validate:"omitempty,gte=0"`
+	MinStay           *int    `json:"minStay" validate:"omitempty,gte=1"`
+	FreeCancelHours   *int    `json:"freeCancelHours" validate:"omitempty,gte=0"`
+	PenaltyType       *string `json:"penaltyType" validate:"omitempty,oneof=FIRST_NIGHT PERCENT"`
+	PenaltyPercent    *int    `json:"penaltyPercent" validate:"omitempty,gte=0,lte=100"`
+}
+
+// WeekdayRate replaces the base rate of a plan on one day of the week, where
+// Sunday is 0 as in time.Weekday.
+type WeekdayRate struct {
+	RatePlanID uuid.UUID `db:"rate_plan_id" json:"ratePlanID"`
+	Weekday    int       `db:"weekday" json:"weekday" validate:"gte=0,lte=6"`
+	Rate       int       `db:"rate" json:"rate" validate:"gte=0"`
+}
+
+// Override changes a single night of a plan. A nil Rate or MinStay leaves
+// the plan's own value in place. ClosedToArrival forbids stays starting on
+// that night but not stays running through it.
+type Override struct {
+	RatePlanID      uuid.UUID `db:"rate_plan_id" json:"ratePlanID"`
+	Night           time.Time `db:"night" json:"night" validate:"required"`
+	Rate            *int      `db:"rate" json:"rate" validate:"omitempty,gte=0"`
+	MinStay         *int      `db:"min_stay" json:"minStay" validate:"omitempty,gte=1"`
+	ClosedToArrival bool      `db:"closed_to_arrival" json:"closedToArrival"`
+}
@@ -0,0 +1,315 @@
+// Package rateplan supports storing rate plans along with their weekday
+// rates and per-night calendar overrides.
+package rateplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("rate plan not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, nrp NewRatePlan) (RatePlan, error) {
+
+	if err := validation.Check(nrp); err != nil {
+		return RatePlan{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	rp := RatePlan{
+		ID:                uuid.New(),
+		RoomTypeID:        nrp.RoomTypeID,
+		Name:              nrp.Name,
+		Refundable:        nrp.Refundable,
+		BreakfastIncluded: nrp.BreakfastIncluded,
+		BaseRate:          nrp.BaseRate,
+		MinStay:           nrp.MinStay,
+		FreeCancelHours:   nrp.FreeCancelHours,
+		PenaltyType:       nrp.PenaltyType,
+		PenaltyPercent:    nrp.PenaltyPercent,
+		DateCreated:       now,
+		DateUpdated:       now,
+	}
+	if rp.MinStay == 0 {
+		rp.MinStay = 1
+	}
+	if rp.PenaltyType == "" {
+		rp.PenaltyType = PenaltyFirstNight
+	}
+
+	const q = `
+		INSERT INTO rate_plans
+			(rate_plan_id, room_type_id, name, refundable, breakfast_included, base_rate, min_stay, free_cancel_hours, penalty_type, penalty_percent, date_created, date_updated)
+		VALUES
+			(:rate_plan_id, :room_type_id, :name, :refundable, :breakfast_included, :base_rate, :min_stay, :free_cancel_hours, :penalty_type, :penalty_percent, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rp); err != nil {
+		return RatePlan{}, fmt.Errorf("inserting rate plan: %w", err)
+	}
+
+	return rp, nil
+}
+
+func (s *Store) Update(ctx context.Context, rp RatePlan, urp UpdateRatePlan) (RatePlan, error) {
+
+	if err := validation.Check(urp); err != nil {
+		return RatePlan{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if urp.Name != nil {
+		rp.Name = *urp.Name
+	}
+	if urp.Refundable != nil {
+		rp.Refundable = *urp.Refundable
+	}
+	if urp.BreakfastIncluded != nil {
+		rp.BreakfastIncluded = *urp.BreakfastIncluded
+	}
+	if urp.BaseRate != nil {
+		rp.BaseRate = *urp.BaseRate
+	}
+	if urp.MinStay != nil {
+		rp.MinStay = *urp.MinStay
+	}
+	if urp.FreeCancelHours != nil {
+		rp.FreeCancelHours = *urp.FreeCancelHours
+	}
+	if urp.PenaltyType != nil {
+		rp.PenaltyType = *urp.PenaltyType
+	}
+	if urp.PenaltyPercent != nil {
+		rp.PenaltyPercent = *urp.PenaltyPercent
+	}
+	rp.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			rate_plans
+		SET
+			"name" = :name,
+			"refundable" = :refundable,
+			"breakfast_included" = :breakfast_included,
+			"base_rate" = :base_rate,
+			"min_stay" = :min_stay,
+			"free_cancel_hours" = :free_cancel_hours,
+			"penalty_type" = :penalty_type,
+			"penalty_percent" = :penalty_percent,
+			"date_updated" = :date_updated
+		WHERE
+			rate_plan_id = :rate_plan_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rp); err != nil {
+		return RatePlan{}, fmt.Errorf("updating ratePlanID[%s]: %w", rp.ID, err)
+	}
+
+	return rp, nil
+}
+
+func (s *Store) Delete(ctx context.Context, rp RatePlan) error {
+	data := struct {
+		RatePlanID string `db:"rate_plan_id"`
+	}{
+		RatePlanID: rp.ID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			rate_plans
+		WHERE
+			rate_plan_id = :rate_plan_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting ratePlanID[%s]: %w", rp.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, ratePlanID uuid.UUID) (RatePlan, error) {
+	data := struct {
+		RatePlanID string `db:"rate_plan_id"`
+	}{
+		RatePlanID: ratePlanID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rate_plans
+		WHERE
+			rate_plan_id = :rate_plan_id
+		`
+	var rp RatePlan
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rp); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return RatePlan{}, ErrNotFound
+		}
+		return RatePlan{}, fmt.Errorf("selecting ratePlanID[%q]: %w", ratePlanID, err)
+	}
+
+	return rp, nil
+}
+
+func (s *Store) QueryByRoomTypeID(ctx context.Context, roomTypeID uuid.UUID) ([]RatePlan, error) {
+	data := struct {
+		RoomTypeID string `db:"room_type_id"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rate_plans
+		WHERE
+			room_type_id = :room_type_id
+		ORDER BY
+			base_rate, name
+		`
+	var rps []RatePlan
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rps); err != nil {
+		return nil, fmt.Errorf("selecting rate plans roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return rps, nil
+}
+
+// SetWeekdayRate adds or replaces the rate of a plan on one day of the week.
+func (s *Store) SetWeekdayRate(ctx context.Context, wr WeekdayRate) error {
+
+	if err := validation.Check(wr); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	const q = `
+		INSERT INTO rate_plan_weekdays
+			(rate_plan_id, weekday, rate)
+		VALUES
+			(:rate_plan_id, :weekday, :rate)
+		ON CONFLICT (rate_plan_id, weekday) DO UPDATE SET
+			rate = EXCLUDED.rate
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, wr); err != nil {
+		return fmt.Errorf("upserting weekday rate ratePlanID[%s]: %w", wr.RatePlanID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryWeekdayRates(ctx context.Context, ratePlanID uuid.UUID) ([]WeekdayRate, error) {
+	data := struct {
+		RatePlanID string `db:"rate_plan_id"`
+	}{
+		RatePlanID: ratePlanID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rate_plan_weekdays
+		WHERE
+			rate_plan_id = :rate_plan_id
+		ORDER BY
+			weekday
+		`
+	var wrs []WeekdayRate
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &wrs); err != nil {
+		return nil, fmt.Errorf("selecting weekday rates ratePlanID[%q]: %w", ratePlanID, err)
+	}
+
+	return wrs, nil
+}
+
+// SetOverride adds or replaces the calendar entry of a plan for one night.
+func (s *Store) SetOverride(ctx context.Context, o Override) error {
+
+	if err := validation.Check(o); err != nil {
+		return fmt.Errorf("validating data: %w", err)
+	}
+
+	const q = `
+		INSERT INTO rate_plan_calendar
+			(rate_plan_id, night, rate, min_stay, closed_to_arrival)
+		VALUES
+			(:rate_plan_id, :night, :rate, :min_stay, :closed_to_arrival)
+		ON CONFLICT (rate_plan_id, night) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			min_stay = EXCLUDED.min_stay,
+			closed_to_arrival = EXCLUDED.closed_to_arrival
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, o); err != nil {
+		return fmt.Errorf("upserting override ratePlanID[%s]: %w", o.RatePlanID, err)
+	}
+
+	return nil
+}
+
+// QueryOverrides returns the calendar entries of a plan for the nights from
+// the first night up to, but not including, the last.
+func (s *Store) QueryOverrides(ctx context.Context, ratePlanID uuid.UUID, from time.Time, to time.Time) ([]Override, error) {
+	data := struct {
+		RatePlanID string    `db:"rate_plan_id"`
+		From       time.Time `db:"from"`
+		To         time.Time `db:"to"`
+	}{
+		RatePlanID: ratePlanID.String(),
+		From:       from,
+		To:         to,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rate_plan_calendar
+		WHERE
+			rate_plan_id = :rate_plan_id AND
+			night >= CAST(:from AS DATE) AND
+			night < CAST(:to AS DATE)
+		ORDER BY
+			night
+		`
+	var ovs []Override
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ovs); err != nil {
+		return nil, fmt.Errorf("selecting overrides ratePlanID[%q]: %w", ratePlanID, err)
+	}
+
+	return ovs, nil
+}
@@ -0,0 +1,26 @@
+package refresh
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Token is a refresh token as stored: only a hash of what the client holds.
+// Every token is used once, to get the next one of its family, the chain of
+// tokens that started at a login.
+type Token struct {
+	ID          uuid.UUID  `db:"token_id" json:"id"`
+	FamilyID    uuid.UUID  `db:"family_id" json:"familyID"`
+	UserID      uuid.UUID  `db:"user_id" json:"userID"`
+	Hash        string     `db:"token_hash" json:"-"`
+	ExpiresAt   time.Time  `db:"expires_at" json:"expiresAt"`
+	DateUsed    *time.Time `db:"date_used" json:"dateUsed,omitempty"`
+	DateRevoked *time.Time `db:"date_revoked" json:"dateRevoked,omitempty"`
+	DateCreated time.Time  `db:"date_created" json:"dateCreated"`
+}
+
+// Refresh carries the refresh token a client presents.
+type Refresh struct {
+	Token string `json:"refreshToken" validate:"required"`
+}
@@ -0,0 +1,155 @@
+// Package refresh supports storing and revoking the refresh tokens clients
+// trade for new access tokens.
+package refresh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("refresh token not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, t Token) error {
+	const q = `
+		INSERT INTO refresh_tokens
+			(token_id, family_id, user_id, token_hash, expires_at, date_used, date_revoked, date_created)
+		VALUES
+			(:token_id, :family_id, :user_id, :token_hash, :expires_at, :date_used, :date_revoked, :date_created)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, t); err != nil {
+		return fmt.Errorf("inserting refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// MarkUsed records that the token was traded for the next of its family.
+func (s *Store) MarkUsed(ctx context.Context, t Token) error {
+	const q = `
+		UPDATE
+			refresh_tokens
+		SET
+			"date_used" = :date_used
+		WHERE
+			token_id = :token_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, t); err != nil {
+		return fmt.Errorf("updating tokenID[%s]: %w", t.ID, err)
+	}
+
+	return nil
+}
+
+// RevokeFamily revokes every token of the family not revoked yet.
+func (s *Store) RevokeFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	data := struct {
+		FamilyID string    `db:"family_id"`
+		Now      time.Time `db:"now"`
+	}{
+		FamilyID: familyID.String(),
+		Now:      now,
+	}
+
+	const q = `
+		UPDATE
+			refresh_tokens
+		SET
+			"date_revoked" = :now
+		WHERE
+			family_id = :family_id AND
+			date_revoked IS NULL
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("revoking familyID[%s]: %w", familyID, err)
+	}
+
+	return nil
+}
+
+// RevokeUser revokes every token of the user not revoked yet.
+func (s *Store) RevokeUser(ctx context.Context, userID uuid.UUID, now time.Time) error {
+	data := struct {
+		UserID string    `db:"user_id"`
+		Now    time.Time `db:"now"`
+	}{
+		UserID: userID.String(),
+		Now:    now,
+	}
+
+	const q = `
+		UPDATE
+			refresh_tokens
+		SET
+			"date_revoked" = :now
+		WHERE
+			user_id = :user_id AND
+			date_revoked IS NULL
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("revoking userID[%s]: %w", userID, err)
+	}
+
+	return nil
+}
+
+// QueryByHashForUpdate retrieves the token with the hash and locks its row
+// until the surrounding transaction ends, so a token is only traded once. It
+// must be called on a store returned by Tran.
+func (s *Store) QueryByHashForUpdate(ctx context.Context, hash string) (Token, error) {
+	data := struct {
+		Hash string `db:"token_hash"`
+	}{
+		Hash: hash,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			refresh_tokens
+		WHERE
+			token_hash = :token_hash
+		FOR UPDATE
+		`
+
+	var t Token
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &t); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Token{}, ErrNotFound
+		}
+		return Token{}, fmt.Errorf("selecting refresh token: %w", err)
+	}
+
+	return t, nil
+}
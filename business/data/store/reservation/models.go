@@ -0,0 +1,103 @@
+package reservation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses a reservation can be in.
+const (
+	StatusHeld       = "HELD"
+	StatusConfirmed  = "CONFIRMED"
+	StatusCheckedIn  = "CHECKED_IN"
+	StatusCheckedOut = "CHECKED_OUT"
+	StatusCancelled  = "CANCELLED"
+	StatusNoShow     = "NO_SHOW"
+	StatusExpired    = "EXPIRED"
+)
+
+// HoldMinutes is how long a HELD reservation keeps its room before it
+// expires unless confirmed.
+const HoldMinutes = 15
+
+type Reservation struct {
+	ID             uuid.UUID  `db:"reservation_id" json:"id"`
+	PropertyID     uuid.UUID  `db:"property_id" json:"propertyID"`
+	RoomTypeID     uuid.UUID  `db:"room_type_id" json:"roomTypeID"`
+	UserID         uuid.UUID  `db:"user_id" json:"userID"`
+	CheckIn        time.Time  `db:"check_in" json:"checkIn"`
+	CheckOut       time.Time  `db:"check_out" json:"checkOut"`
+	Guests         int        `db:"guests" json:"guests"`
+	Status         string     `db:"status" json:"status"`
+	RatePlanID     *uuid.UUID `db:"rate_plan_id" json:"ratePlanID,omitempty"`
+	Total          int        `db:"total" json:"total"`
+	DateCreated    time.Time  `db:"date_created" json:"dateCreated"`
+	DateUpdated    time.Time  `db:"date_updated" json:"dateUpdated"`
+	DateConfirmed  *time.Time `db:"date_confirmed" json:"dateConfirmed,omitempty"`
+	DateCheckedIn  *time.Time `db:"date_checked_in" json:"dateCheckedIn,omitempty"`
+	DateCheckedOut *time.Time `db:"date_checked_out" json:"dateCheckedOut,omitempty"`
+	DateCancelled  *time.Time `db:"date_cancelled" json:"dateCancelled,omitempty"`
+	DateNoShow     *time.Time `db:"date_no_show" json:"dateNoShow,omitempty"`
+	ExpiresAt      *time.Time `db:"expires_at" json:"expiresAt,omitempty"`
+	CancelPenalty  *int       `db:"cancel_penalty" json:"cancelPenalty,omitempty"`
+	CancelRefund   *int       `db:"cancel_refund" json:"cancelRefund,omitempty"`
+	BlockID        *uuid.UUID `db:"block_id" json:"blockID,omitempty"`
+	RoomID         *uuid.UUID `db:"room_id" json:"roomID,omitempty"`
+	PreferredFloor *int       `db:"preferred_floor" json:"preferredFloor,omitempty"`
+	Accessible     bool       `db:"accessible" json:"accessible"`
+	ConnectWith    *uuid.UUID `db:"connect_with" json:"connectWith,omitempty"`
+}
+
+// NewReservation is what a guest books. PreferredFloor, Accessible and
+// ConnectWith, another reservation the guest wants a connecting room to, are
+// honored when rooms are assigned at arrival.
+type NewReservation struct {
+	RoomTypeID     uuid.UUID  `json:"roomTypeID" validate:"required"`
+	CheckIn        time.Time  `json:"checkIn" validate:"required"`
+	CheckOut       time.Time  `json:"checkOut" validate:"required,gtfield=CheckIn"`
+	Guests         int        `json:"guests" validate:"required,gte=1"`
+	RatePlanID     *uuid.UUID `json:"ratePlanID"`
+	UserID         uuid.UUID  `json:"userID"`
+	PreferredFloor *int       `json:"preferredFloor"`
+	Accessible     bool       `json:"accessible"`
+	ConnectWith    *uuid.UUID `json:"connectWith"`
+}
+
+// ConfirmReservation names the payment source the stay is authorized on
+// when it is confirmed.
+type ConfirmReservation struct {
+	PaymentSource string `json:"paymentSource" validate:"required"`
+}
+
+// CheckInReservation names the room the guest checks in to. Without one the
+// guest gets the room assigned to the reservation, or any free room of its
+// room type ready for guests.
+type CheckInReservation struct {
+	RoomID *uuid.UUID `json:"roomID"`
+}
+
+// AssignRoom names the room a reservation is assigned to ahead of arrival.
+// Without one the reservation is left unassigned.
+type AssignRoom struct {
+	RoomID *uuid.UUID `json:"roomID"`
+}
+
+// History records a single status transition of a reservation and the user
+// who made it.
+type History struct {
+	ID            uuid.UUID `db:"history_id" json:"id"`
+	ReservationID uuid.UUID `db:"reservation_id" json:"reservationID"`
+	FromStatus    string    `db:"from_status" json:"fromStatus"`
+	ToStatus      string    `db:"to_status" json:"toStatus"`
+	UserID        uuid.UUID `db:"user_id" json:"userID"`
+	DateCreated   time.Time `db:"date_created" json:"dateCreated"`
+}
+
+// Night is the price charged for one night of a reservation, fixed when the
+// reservation was booked.
+type Night struct {
+	ReservationID uuid.UUID `db:"reservation_id" json:"reservationID"`
+	Night         time.Time `db:"night" json:"night"`
+	Rate          int       `db:"rate" json:"rate"`
+}
@@ -0,0 +1,397 @@
+// Package reservation supports storing and querying room reservations
+package reservation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound         = errors.New("reservation not found")
+	ErrUnknownCompanion = errors.New("reservation to connect with not found")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, res Reservation) error {
+	const q = `
+		INSERT INTO reservations
+			(reservation_id, property_id, room_type_id, user_id, check_in, check_out, guests, status, rate_plan_id, total, expires_at, block_id, preferred_floor, accessible, connect_with, date_created, date_updated)
+		VALUES
+			(:reservation_id, :property_id, :room_type_id, :user_id, :check_in, :check_out, :guests, :status, :rate_plan_id, :total, :expires_at, :block_id, :preferred_floor, :accessible, :connect_with, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, res); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) && res.ConnectWith != nil {
+			return ErrUnknownCompanion
+		}
+		return fmt.Errorf("inserting reservation: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, reservationID uuid.UUID) (Reservation, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			reservation_id = :reservation_id
+		`
+	var res Reservation
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &res); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Reservation{}, ErrNotFound
+		}
+		return Reservation{}, fmt.Errorf("selecting reservationID[%q]: %w", reservationID, err)
+	}
+
+	return res, nil
+}
+
+// QueryByIDForUpdate gets the specified reservation and locks its row until
+// the surrounding transaction ends.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, reservationID uuid.UUID) (Reservation, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			reservation_id = :reservation_id
+		FOR UPDATE
+		`
+	var res Reservation
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &res); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Reservation{}, ErrNotFound
+		}
+		return Reservation{}, fmt.Errorf("selecting reservationID[%q] for update: %w", reservationID, err)
+	}
+
+	return res, nil
+}
+
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Reservation, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			check_in
+		`
+	var ress []Reservation
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ress); err != nil {
+		return nil, fmt.Errorf("selecting reservations userID[%q]: %w", userID, err)
+	}
+
+	return ress, nil
+}
+
+// QueryByBlockID returns the reservations picked up from the block.
+func (s *Store) QueryByBlockID(ctx context.Context, blockID uuid.UUID) ([]Reservation, error) {
+	data := struct {
+		BlockID string `db:"block_id"`
+	}{
+		BlockID: blockID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			block_id = :block_id
+		ORDER BY
+			check_in, date_created
+		`
+	var ress []Reservation
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ress); err != nil {
+		return nil, fmt.Errorf("selecting reservations blockID[%q]: %w", blockID, err)
+	}
+
+	return ress, nil
+}
+
+// UpdateStatus persists the status of a reservation along with the
+// timestamps of its lifecycle transitions and the outcome of a cancellation.
+func (s *Store) UpdateStatus(ctx context.Context, res Reservation) error {
+	const q = `
+		UPDATE
+			reservations
+		SET
+			"status" = :status,
+			"date_confirmed" = :date_confirmed,
+			"date_checked_in" = :date_checked_in,
+			"date_checked_out" = :date_checked_out,
+			"date_cancelled" = :date_cancelled,
+			"date_no_show" = :date_no_show,
+			"expires_at" = :expires_at,
+			"cancel_penalty" = :cancel_penalty,
+			"cancel_refund" = :cancel_refund,
+			"room_id" = :room_id,
+			"date_updated" = :date_updated
+		WHERE
+			reservation_id = :reservation_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, res); err != nil {
+		return fmt.Errorf("updating reservationID[%s]: %w", res.ID, err)
+	}
+
+	return nil
+}
+
+// UpdateRoom persists the room a reservation is assigned to.
+func (s *Store) UpdateRoom(ctx context.Context, res Reservation) error {
+	const q = `
+		UPDATE
+			reservations
+		SET
+			"room_id" = :room_id,
+			"date_updated" = :date_updated
+		WHERE
+			reservation_id = :reservation_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, res); err != nil {
+		return fmt.Errorf("updating room reservationID[%s]: %w", res.ID, err)
+	}
+
+	return nil
+}
+
+// QueryArrivalsForUpdate retrieves the confirmed reservations arriving at the
+// property on the day and locks their rows until the surrounding transaction
+// ends. It must be called on a store returned by Tran.
+func (s *Store) QueryArrivalsForUpdate(ctx context.Context, propertyID uuid.UUID, day time.Time) ([]Reservation, error) {
+	data := struct {
+		PropertyID string    `db:"property_id"`
+		Day        time.Time `db:"day"`
+	}{
+		PropertyID: propertyID.String(),
+		Day:        day,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			property_id = :property_id AND
+			check_in = :day AND
+			status = 'CONFIRMED'
+		ORDER BY
+			reservation_id
+		FOR UPDATE
+		`
+
+	var ress []Reservation
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ress); err != nil {
+		return nil, fmt.Errorf("selecting arrivals propertyID[%q]: %w", propertyID, err)
+	}
+
+	return ress, nil
+}
+
+// QueryAssigned retrieves the live reservations at the property that are
+// assigned a room and stay any night between from and to.
+func (s *Store) QueryAssigned(ctx context.Context, propertyID uuid.UUID, from time.Time, to time.Time) ([]Reservation, error) {
+	data := struct {
+		PropertyID string    `db:"property_id"`
+		From       time.Time `db:"from"`
+		To         time.Time `db:"to"`
+	}{
+		PropertyID: propertyID.String(),
+		From:       from,
+		To:         to,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservations
+		WHERE
+			property_id = :property_id AND
+			room_id IS NOT NULL AND
+			status IN ('HELD', 'CONFIRMED', 'CHECKED_IN') AND
+			check_in < :to AND
+			check_out > :from
+		ORDER BY
+			check_in, reservation_id
+		`
+
+	var ress []Reservation
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ress); err != nil {
+		return nil, fmt.Errorf("selecting assigned propertyID[%q]: %w", propertyID, err)
+	}
+
+	return ress, nil
+}
+
+// Expire marks every HELD reservation whose hold ran out by now as EXPIRED
+// and returns them.
+func (s *Store) Expire(ctx context.Context, now time.Time) ([]Reservation, error) {
+	data := struct {
+		Status string    `db:"status"`
+		Now    time.Time `db:"now"`
+	}{
+		Status: StatusExpired,
+		Now:    now,
+	}
+
+	const q = `
+		UPDATE
+			reservations
+		SET
+			"status" = :status,
+			"expires_at" = NULL,
+			"date_updated" = :now
+		WHERE
+			status = 'HELD' AND
+			expires_at <= :now
+		RETURNING
+			*
+		`
+
+	var ress []Reservation
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ress); err != nil {
+		return nil, fmt.Errorf("expiring reservations: %w", err)
+	}
+
+	return ress, nil
+}
+
+func (s *Store) AddHistory(ctx context.Context, h History) error {
+	const q = `
+		INSERT INTO reservation_history
+			(history_id, reservation_id, from_status, to_status, user_id, date_created)
+		VALUES
+			(:history_id, :reservation_id, :from_status, :to_status, :user_id, :date_created)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, h); err != nil {
+		return fmt.Errorf("inserting reservation history: %w", err)
+	}
+
+	return nil
+}
+
+// QueryHistory returns the transitions of a reservation, oldest first.
+func (s *Store) QueryHistory(ctx context.Context, reservationID uuid.UUID) ([]History, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservation_history
+		WHERE
+			reservation_id = :reservation_id
+		ORDER BY
+			date_created
+		`
+	var hs []History
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &hs); err != nil {
+		return nil, fmt.Errorf("selecting history reservationID[%q]: %w", reservationID, err)
+	}
+
+	return hs, nil
+}
+
+// CreateNights stores the nightly breakdown of a reservation.
+func (s *Store) CreateNights(ctx context.Context, nights []Night) error {
+	const q = `
+		INSERT INTO reservation_nights
+			(reservation_id, night, rate)
+		VALUES
+			(:reservation_id, :night, :rate)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, nights); err != nil {
+		return fmt.Errorf("inserting reservation nights: %w", err)
+	}
+
+	return nil
+}
+
+// QueryNights returns the nightly breakdown of a reservation in date order.
+func (s *Store) QueryNights(ctx context.Context, reservationID uuid.UUID) ([]Night, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			reservation_nights
+		WHERE
+			reservation_id = :reservation_id
+		ORDER BY
+			night
+		`
+	var nights []Night
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &nights); err != nil {
+		return nil, fmt.Errorf("selecting nights reservationID[%q]: %w", reservationID, err)
+	}
+
+	return nights, nil
+}
@@ -0,0 +1,22 @@
+package revocation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Revoked is an access token revoked before it expired. It is kept until it
+// expires, after which its signature check rejects it anyway.
+type Revoked struct {
+	ID          string    `db:"token_id" json:"id"`
+	UserID      uuid.UUID `db:"user_id" json:"userID"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expiresAt"`
+	DateCreated time.Time `db:"date_created" json:"dateCreated"`
+}
+
+// Cutoff is the moment before which every access token of a user is revoked.
+type Cutoff struct {
+	UserID     uuid.UUID `db:"user_id" json:"userID"`
+	ValidAfter time.Time `db:"tokens_valid_after" json:"validAfter"`
+}
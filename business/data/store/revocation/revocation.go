@@ -0,0 +1,133 @@
+// Package revocation supports revoking access tokens before they expire,
+// one at a time or every token a user was issued up to a moment.
+package revocation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Revoke records the token as revoked. Revoking it again changes nothing.
+func (s *Store) Revoke(ctx context.Context, r Revoked) error {
+	const q = `
+		INSERT INTO revoked_tokens
+			(token_id, user_id, expires_at, date_created)
+		VALUES
+			(:token_id, :user_id, :expires_at, :date_created)
+		ON CONFLICT (token_id) DO NOTHING
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, r); err != nil {
+		return fmt.Errorf("inserting revoked token: %w", err)
+	}
+
+	return nil
+}
+
+// CutOff revokes every token the user was issued before the cutoff. A cutoff
+// never moves back in time.
+func (s *Store) CutOff(ctx context.Context, c Cutoff) error {
+	const q = `
+		INSERT INTO token_cutoffs
+			(user_id, tokens_valid_after)
+		VALUES
+			(:user_id, :tokens_valid_after)
+		ON CONFLICT (user_id) DO UPDATE SET
+			"tokens_valid_after" = GREATEST(token_cutoffs.tokens_valid_after, EXCLUDED.tokens_valid_after)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, c); err != nil {
+		return fmt.Errorf("cutting off userID[%s]: %w", c.UserID, err)
+	}
+
+	return nil
+}
+
+// DeleteExpired forgets the revoked tokens that expired by now.
+func (s *Store) DeleteExpired(ctx context.Context, now time.Time) error {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now,
+	}
+
+	const q = `
+		DELETE FROM
+			revoked_tokens
+		WHERE
+			expires_at <= :now
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting expired revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
+// QueryRevoked retrieves the revoked tokens that have not expired by now.
+func (s *Store) QueryRevoked(ctx context.Context, now time.Time) ([]Revoked, error) {
+	data := struct {
+		Now time.Time `db:"now"`
+	}{
+		Now: now,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			revoked_tokens
+		WHERE
+			expires_at > :now
+		`
+
+	var rs []Revoked
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rs); err != nil {
+		return nil, fmt.Errorf("selecting revoked tokens: %w", err)
+	}
+
+	return rs, nil
+}
+
+// QueryCutoffs retrieves the cutoff of every user that has one.
+func (s *Store) QueryCutoffs(ctx context.Context) ([]Cutoff, error) {
+	const q = `
+		SELECT
+			*
+		FROM
+			token_cutoffs
+		`
+
+	var cs []Cutoff
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, struct{}{}, &cs); err != nil {
+		return nil, fmt.Errorf("selecting cutoffs: %w", err)
+	}
+
+	return cs, nil
+}
@@ -0,0 +1,61 @@
+package room
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses a physical room can be in.
+const (
+	StatusAvailable    = "AVAILABLE"
+	StatusOccupied     = "OCCUPIED"
+	StatusOutOfService = "OUT_OF_SERVICE"
+)
+
+// Set of housekeeping statuses a room can be in. Guests only check in to
+// CLEAN or INSPECTED rooms.
+const (
+	HousekeepingDirty      = "DIRTY"
+	HousekeepingCleaning   = "CLEANING"
+	HousekeepingClean      = "CLEAN"
+	HousekeepingInspected  = "INSPECTED"
+	HousekeepingOutOfOrder = "OUT_OF_ORDER"
+)
+
+type Room struct {
+	ID           uuid.UUID  `db:"room_id" json:"id"`
+	PropertyID   uuid.UUID  `db:"property_id" json:"propertyID"`
+	RoomTypeID   uuid.UUID  `db:"room_type_id" json:"roomTypeID"`
+	Number       string     `db:"number" json:"number"`
+	Floor        int        `db:"floor" json:"floor"`
+	Status       string     `db:"status" json:"status"`
+	Housekeeping string     `db:"housekeeping" json:"housekeeping"`
+	Accessible   bool       `db:"accessible" json:"accessible"`
+	ConnectsTo   *uuid.UUID `db:"connects_to" json:"connectsTo,omitempty"`
+	DateCreated  time.Time  `db:"date_created" json:"dateCreated"`
+	DateUpdated  time.Time  `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewRoom struct {
+	RoomTypeID uuid.UUID  `json:"roomTypeID" validate:"required"`
+	Number     string     `json:"number" validate:"required"`
+	Floor      int        `json:"floor"`
+	Accessible bool       `json:"accessible"`
+	ConnectsTo *uuid.UUID `json:"connectsTo"`
+	PropertyID uuid.UUID  `json:"-"`
+}
+
+type UpdateRoom struct {
+	RoomTypeID *uuid.UUID `json:"roomTypeID"`
+	Number     *string    `json:"number"`
+	Floor      *int       `json:"floor"`
+	Status     *string    `json:"status" validate:"omitempty,oneof=AVAILABLE OCCUPIED OUT_OF_SERVICE"`
+	Accessible *bool      `json:"accessible"`
+	ConnectsTo *uuid.UUID `json:"connectsTo"`
+}
+
+// UpdateHousekeeping moves a room to another housekeeping status.
+type UpdateHousekeeping struct {
+	Status string `json:"status" validate:"required,oneof=DIRTY CLEANING CLEAN INSPECTED OUT_OF_ORDER"`
+}
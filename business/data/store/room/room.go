@@ -0,0 +1,371 @@
+// Package room supports CRUD operations on the physical rooms of a property
+package room
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound          = errors.New("room not found")
+	ErrUniqueNumber      = errors.New("room number is not unique")
+	ErrUnknownConnecting = errors.New("connecting room not found")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, nr NewRoom) (Room, error) {
+
+	if err := validation.Check(nr); err != nil {
+		return Room{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	rm := Room{
+		ID:           uuid.New(),
+		PropertyID:   nr.PropertyID,
+		RoomTypeID:   nr.RoomTypeID,
+		Number:       nr.Number,
+		Floor:        nr.Floor,
+		Status:       StatusAvailable,
+		Housekeeping: HousekeepingClean,
+		Accessible:   nr.Accessible,
+		ConnectsTo:   nr.ConnectsTo,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	const q = `
+		INSERT INTO rooms
+			(room_id, property_id, room_type_id, number, floor, status, housekeeping, accessible, connects_to, date_created, date_updated)
+		VALUES
+			(:room_id, :property_id, :room_type_id, :number, :floor, :status, :housekeeping, :accessible, :connects_to, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rm); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Room{}, fmt.Errorf("create: %w", ErrUniqueNumber)
+		}
+		if errors.Is(err, database.ErrDBForeignKey) && rm.ConnectsTo != nil {
+			return Room{}, ErrUnknownConnecting
+		}
+		return Room{}, fmt.Errorf("inserting room: %w", err)
+	}
+
+	return rm, nil
+}
+
+func (s *Store) Update(ctx context.Context, rm Room, ur UpdateRoom) (Room, error) {
+
+	if err := validation.Check(ur); err != nil {
+		return Room{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if ur.RoomTypeID != nil {
+		rm.RoomTypeID = *ur.RoomTypeID
+	}
+	if ur.Number != nil {
+		rm.Number = *ur.Number
+	}
+	if ur.Floor != nil {
+		rm.Floor = *ur.Floor
+	}
+	if ur.Status != nil {
+		rm.Status = *ur.Status
+	}
+	if ur.Accessible != nil {
+		rm.Accessible = *ur.Accessible
+	}
+	if ur.ConnectsTo != nil {
+		rm.ConnectsTo = ur.ConnectsTo
+	}
+	rm.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			rooms
+		SET
+			"room_type_id" = :room_type_id,
+			"number" = :number,
+			"floor" = :floor,
+			"status" = :status,
+			"accessible" = :accessible,
+			"connects_to" = :connects_to,
+			"date_updated" = :date_updated
+		WHERE
+			room_id = :room_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rm); err != nil {
+		if errors.Is(err, database.ErrDBDuplicatedEntry) {
+			return Room{}, ErrUniqueNumber
+		}
+		if errors.Is(err, database.ErrDBForeignKey) && rm.ConnectsTo != nil {
+			return Room{}, ErrUnknownConnecting
+		}
+		return Room{}, fmt.Errorf("updating roomID[%s]: %w", rm.ID, err)
+	}
+
+	return rm, nil
+}
+
+// UpdateState saves the occupancy and housekeeping statuses of the room.
+func (s *Store) UpdateState(ctx context.Context, rm Room) error {
+	const q = `
+		UPDATE
+			rooms
+		SET
+			"status" = :status,
+			"housekeeping" = :housekeeping,
+			"date_updated" = :date_updated
+		WHERE
+			room_id = :room_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rm); err != nil {
+		return fmt.Errorf("updating state roomID[%s]: %w", rm.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, rm Room) error {
+	data := struct {
+		RoomID string `db:"room_id"`
+	}{
+		RoomID: rm.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		rooms
+	WHERE
+		room_id = :room_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting roomID[%s]: %w", rm.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, roomID uuid.UUID) (Room, error) {
+	data := struct {
+		RoomID string `db:"room_id"`
+	}{
+		RoomID: roomID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			room_id = :room_id
+		`
+	var rm Room
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rm); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Room{}, ErrNotFound
+		}
+		return Room{}, fmt.Errorf("selecting roomID[%q]: %w", roomID, err)
+	}
+
+	return rm, nil
+}
+
+// QueryByIDForUpdate retrieves the room and locks its row until the
+// surrounding transaction ends. It must be called on a store returned by Tran.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, roomID uuid.UUID) (Room, error) {
+	data := struct {
+		RoomID string `db:"room_id"`
+	}{
+		RoomID: roomID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			room_id = :room_id
+		FOR UPDATE
+		`
+	var rm Room
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rm); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Room{}, ErrNotFound
+		}
+		return Room{}, fmt.Errorf("selecting roomID[%q] for update: %w", roomID, err)
+	}
+
+	return rm, nil
+}
+
+// QueryReadyForUpdate retrieves a free room of the room type guests can check
+// in to for the stay of the reservation and locks its row until the
+// surrounding transaction ends. Rooms assigned to another reservation staying
+// any of those nights are left for it, rooms out of order any of those nights
+// are left out, and rooms other transactions hold are skipped. It must be
+// called on a store returned by Tran.
+func (s *Store) QueryReadyForUpdate(ctx context.Context, roomTypeID uuid.UUID, reservationID uuid.UUID, checkIn time.Time, checkOut time.Time) (Room, error) {
+	data := struct {
+		RoomTypeID    string    `db:"room_type_id"`
+		ReservationID string    `db:"reservation_id"`
+		CheckIn       time.Time `db:"check_in"`
+		CheckOut      time.Time `db:"check_out"`
+	}{
+		RoomTypeID:    roomTypeID.String(),
+		ReservationID: reservationID.String(),
+		CheckIn:       checkIn,
+		CheckOut:      checkOut,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			room_type_id = :room_type_id AND
+			status = 'AVAILABLE' AND
+			housekeeping IN ('CLEAN', 'INSPECTED') AND
+			NOT EXISTS (
+				SELECT 1 FROM reservations AS res
+				WHERE res.room_id = rooms.room_id AND
+					res.reservation_id <> :reservation_id AND
+					res.status IN ('HELD', 'CONFIRMED') AND
+					res.check_in < :check_out AND
+					res.check_out > :check_in
+			) AND
+			NOT EXISTS (
+				SELECT 1 FROM out_of_order AS o
+				WHERE o.room_id = rooms.room_id AND
+					o.starts < :check_out AND
+					o.ends > :check_in
+			)
+		ORDER BY
+			floor, number
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+		`
+	var rm Room
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rm); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Room{}, ErrNotFound
+		}
+		return Room{}, fmt.Errorf("selecting ready room roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return rm, nil
+}
+
+func (s *Store) QueryByPropertyID(ctx context.Context, propertyID uuid.UUID) ([]Room, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			property_id = :property_id
+		ORDER BY
+			number
+		`
+	var rms []Room
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rms); err != nil {
+		return nil, fmt.Errorf("selecting rooms propertyID[%q]: %w", propertyID, err)
+	}
+
+	return rms, nil
+}
+
+// QueryByPropertyIDForUpdate retrieves the rooms of the property and locks
+// their rows until the surrounding transaction ends, so rooms are assigned to
+// reservations one transaction at a time. It must be called on a store
+// returned by Tran.
+func (s *Store) QueryByPropertyIDForUpdate(ctx context.Context, propertyID uuid.UUID) ([]Room, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			property_id = :property_id
+		ORDER BY
+			number
+		FOR UPDATE
+		`
+	var rms []Room
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rms); err != nil {
+		return nil, fmt.Errorf("selecting rooms propertyID[%q]: %w", propertyID, err)
+	}
+
+	return rms, nil
+}
+
+func (s *Store) QueryByRoomTypeID(ctx context.Context, roomTypeID uuid.UUID) ([]Room, error) {
+	data := struct {
+		RoomTypeID string `db:"room_type_id"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			rooms
+		WHERE
+			room_type_id = :room_type_id
+		ORDER BY
+			number
+		`
+	var rms []Room
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rms); err != nil {
+		return nil, fmt.Errorf("selecting rooms roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return rms, nil
+}
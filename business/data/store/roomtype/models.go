@@ -0,0 +1,40 @@
+package roomtype
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/sys/database"
+)
+
+type RoomType struct {
+	ID          uuid.UUID            `db:"room_type_id" json:"id"`
+	PropertyID  uuid.UUID            `db:"property_id" json:"propertyID"`
+	Name        string               `db:"name" json:"name"`
+	Description string               `db:"description" json:"description"`
+	Capacity    int                  `db:"capacity" json:"capacity"`
+	BedConfig   string               `db:"bed_config" json:"bedConfig"`
+	Amenities   database.StringArray `db:"amenities" json:"amenities"`
+	BaseRate    int                  `db:"base_rate" json:"baseRate"`
+	DateCreated time.Time            `db:"date_created" json:"dateCreated"`
+	DateUpdated time.Time            `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewRoomType struct {
+	PropertyID  uuid.UUID `json:"propertyID" validate:"required"`
+	Name        string    `json:"name" validate:"required"`
+	Description string    `json:"description"`
+	Capacity    int       `json:"capacity" validate:"required,gte=1"`
+	BedConfig   string    `json:"bedConfig" validate:"required"`
+	Amenities   []string  `json:"amenities"`
+	BaseRate    int       `json:"baseRate" validate:"gte=0"`
+}
+
+type UpdateRoomType struct {
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	Capacity    *int     `json:"capacity" validate:"omitempty,gte=1"`
+	BedConfig   *string  `json:"bedConfig"`
+	Amenities   []string `json:"amenities"`
+	BaseRate    *int     `json:"baseRate" validate:"omitempty,gte=0"`
+}
@@ -0,0 +1,232 @@
+// Package roomtype supports CRUD operations on the room types of a property
+package roomtype
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound = errors.New("room type not found")
+	ErrInUse    = errors.New("room type still has reservations")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, nrt NewRoomType) (RoomType, error) {
+
+	if err := validation.Check(nrt); err != nil {
+		return RoomType{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	now := time.Now()
+
+	rt := RoomType{
+		ID:          uuid.New(),
+		PropertyID:  nrt.PropertyID,
+		Name:        nrt.Name,
+		Description: nrt.Description,
+		Capacity:    nrt.Capacity,
+		BedConfig:   nrt.BedConfig,
+		Amenities:   database.StringArray(nrt.Amenities),
+		BaseRate:    nrt.BaseRate,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+	if rt.Amenities == nil {
+		rt.Amenities = database.StringArray{}
+	}
+
+	const q = `
+		INSERT INTO room_types
+			(room_type_id, property_id, name, description, capacity, bed_config, amenities, base_rate, date_created, date_updated)
+		VALUES
+			(:room_type_id, :property_id, :name, :description, :capacity, :bed_config, :amenities, :base_rate, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rt); err != nil {
+		return RoomType{}, fmt.Errorf("inserting room type: %w", err)
+	}
+
+	return rt, nil
+}
+
+func (s *Store) Update(ctx context.Context, rt RoomType, urt UpdateRoomType) (RoomType, error) {
+
+	if err := validation.Check(urt); err != nil {
+		return RoomType{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if urt.Name != nil {
+		rt.Name = *urt.Name
+	}
+	if urt.Description != nil {
+		rt.Description = *urt.Description
+	}
+	if urt.Capacity != nil {
+		rt.Capacity = *urt.Capacity
+	}
+	if urt.BedConfig != nil {
+		rt.BedConfig = *urt.BedConfig
+	}
+	if urt.Amenities != nil {
+		rt.Amenities = urt.Amenities
+	}
+	if urt.BaseRate != nil {
+		rt.BaseRate = *urt.BaseRate
+	}
+	rt.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			room_types
+		SET
+			"name" = :name,
+			"description" = :description,
+			"capacity" = :capacity,
+			"bed_config" = :bed_config,
+			"amenities" = :amenities,
+			"base_rate" = :base_rate,
+			"date_updated" = :date_updated
+		WHERE
+			room_type_id = :room_type_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, rt); err != nil {
+		return RoomType{}, fmt.Errorf("updating roomTypeID[%s]: %w", rt.ID, err)
+	}
+
+	return rt, nil
+}
+
+func (s *Store) Delete(ctx context.Context, rt RoomType) error {
+	data := struct {
+		RoomTypeID string `db:"room_type_id"`
+	}{
+		RoomTypeID: rt.ID.String(),
+	}
+
+	const q = `
+	DELETE FROM
+		room_types
+	WHERE
+		room_type_id = :room_type_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return ErrInUse
+		}
+		return fmt.Errorf("deleting roomTypeID[%s]: %w", rt.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, roomTypeID uuid.UUID) (RoomType, error) {
+	data := struct {
+		RoomTypeID string `db:"room_type_id"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			room_types
+		WHERE
+			room_type_id = :room_type_id
+		`
+	var rt RoomType
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rt); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return RoomType{}, ErrNotFound
+		}
+		return RoomType{}, fmt.Errorf("selecting roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return rt, nil
+}
+
+// QueryByIDForUpdate retrieves the room type and locks its row until the
+// surrounding transaction ends. Bookings take this lock to serialize the
+// availability check for a room type. It must be called on a store returned
+// by Tran.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, roomTypeID uuid.UUID) (RoomType, error) {
+	data := struct {
+		RoomTypeID string `db:"room_type_id"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			room_types
+		WHERE
+			room_type_id = :room_type_id
+		FOR UPDATE
+		`
+	var rt RoomType
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &rt); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return RoomType{}, ErrNotFound
+		}
+		return RoomType{}, fmt.Errorf("selecting roomTypeID[%q] for update: %w", roomTypeID, err)
+	}
+
+	return rt, nil
+}
+
+func (s *Store) QueryByPropertyID(ctx context.Context, propertyID uuid.UUID) ([]RoomType, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			room_types
+		WHERE
+			property_id = :property_id
+		ORDER BY
+			name
+		`
+	var rts []RoomType
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rts); err != nil {
+		return nil, fmt.Errorf("selecting room types propertyID[%q]: %w", propertyID, err)
+	}
+
+	return rts, nil
+}
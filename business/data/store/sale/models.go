@@ -0,0 +1,27 @@
+package sale
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/sys/money"
+)
+
+type Sale struct {
+	ID          uuid.UUID   `db:"sale_id" json:"id"`
+	UserID      uuid.UUID   `db:"user_id" json:"userID"`
+	ProductID   uuid.UUID   `db:"product_id" json:"productID"`
+	Quantity    int         `db:"quantity" json:"quantity"`
+	Paid        money.Money `db:"paid" json:"paid"`
+	DateCreated time.Time   `db:"date_created" json:"dateCreated"`
+}
+
+// NewSale is what a buyer sends to buy a product. RequestKey is picked by the
+// client for the purchase and sent again when it retries it.
+type NewSale struct {
+	ProductID     uuid.UUID `json:"productID" validate:"required"`
+	Quantity      int       `json:"quantity" validate:"required,gte=1"`
+	UserID        uuid.UUID `json:"userID"`
+	PaymentSource string    `json:"paymentSource" validate:"required"`
+	RequestKey    string    `json:"requestKey" validate:"required"`
+}
@@ -0,0 +1,126 @@
+// Package sale supports recording and querying sales
+package sale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("sale not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, sl Sale) error {
+	const q = `
+		INSERT INTO sales
+			(sale_id, user_id, product_id, quantity, paid, date_created)
+		VALUES
+			(:sale_id, :user_id, :product_id, :quantity, :paid, :date_created)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, sl); err != nil {
+		return fmt.Errorf("inserting sale: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, saleID uuid.UUID) (Sale, error) {
+	data := struct {
+		SaleID string `db:"sale_id"`
+	}{
+		SaleID: saleID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			sales
+		WHERE
+			sale_id = :sale_id
+		`
+	var sl Sale
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &sl); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Sale{}, ErrNotFound
+		}
+		return Sale{}, fmt.Errorf("selecting saleID[%q]: %w", saleID, err)
+	}
+
+	return sl, nil
+}
+
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Sale, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			sales
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			date_created
+		`
+	var sls []Sale
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &sls); err != nil {
+		return nil, fmt.Errorf("selecting sales userID[%q]: %w", userID, err)
+	}
+
+	return sls, nil
+}
+
+func (s *Store) QueryByProductID(ctx context.Context, productID uuid.UUID) ([]Sale, error) {
+	data := struct {
+		ProductID string `db:"product_id"`
+	}{
+		ProductID: productID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			sales
+		WHERE
+			product_id = :product_id
+		ORDER BY
+			date_created
+		`
+	var sls []Sale
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &sls); err != nil {
+		return nil, fmt.Errorf("selecting sales productID[%q]: %w", productID, err)
+	}
+
+	return sls, nil
+}
@@ -0,0 +1,63 @@
+package taxrule
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of categories of rule. TAX rules are levied by a jurisdiction, FEE
+// rules are charged by the property itself, such as a resort fee.
+const (
+	CategoryTax = "TAX"
+	CategoryFee = "FEE"
+)
+
+// Set of ways a rule computes what it charges for a stay. A PERCENT rule
+// charges Amount basis points of the room charges, the others charge Amount
+// per guest per night, per night or once per stay.
+const (
+	KindPercent        = "PERCENT"
+	KindPerPersonNight = "PER_PERSON_NIGHT"
+	KindPerNight       = "PER_NIGHT"
+	KindPerStay        = "PER_STAY"
+)
+
+// Set of ways a PERCENT rule rounds to a whole amount.
+const (
+	RoundHalfUp   = "HALF_UP"
+	RoundHalfEven = "HALF_EVEN"
+	RoundUp       = "UP"
+	RoundDown     = "DOWN"
+)
+
+// Rule is a tax or fee charged on stays. It applies either to a single
+// property or to every property in a jurisdiction.
+type Rule struct {
+	ID           uuid.UUID  `db:"tax_rule_id" json:"id"`
+	PropertyID   *uuid.UUID `db:"property_id" json:"propertyID,omitempty"`
+	Jurisdiction *string    `db:"jurisdiction" json:"jurisdiction,omitempty"`
+	Name         string     `db:"name" json:"name"`
+	Category     string     `db:"category" json:"category"`
+	Kind         string     `db:"kind" json:"kind"`
+	Amount       int        `db:"amount" json:"amount"`
+	Rounding     string     `db:"rounding" json:"rounding"`
+	DateCreated  time.Time  `db:"date_created" json:"dateCreated"`
+	DateUpdated  time.Time  `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewRule struct {
+	PropertyID   *uuid.UUID `json:"propertyID" validate:"required_without=Jurisdiction,excluded_with=Jurisdiction"`
+	Jurisdiction *string    `json:"jurisdiction" validate:"required_without=PropertyID,omitempty,min=1"`
+	Name         string     `json:"name" validate:"required"`
+	Category     string     `json:"category" validate:"required,oneof=TAX FEE"`
+	Kind         string     `json:"kind" validate:"required,oneof=PERCENT PER_PERSON_NIGHT PER_NIGHT PER_STAY"`
+	Amount       int        `json:"amount" validate:"gte=0"`
+	Rounding     string     `json:"rounding" validate:"omitempty,oneof=HALF_UP HALF_EVEN UP DOWN"`
+}
+
+type UpdateRule struct {
+	Name     *string `json:"name" validate:"omitempty,min=1"`
+	Amount   *int    `json:"amount" validate:"omitempty,gte=0"`
+	Rounding *string `json:"rounding" validate:"omitempty,oneof=HALF_UP HALF_EVEN UP DOWN"`
+}
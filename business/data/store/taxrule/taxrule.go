@@ -0,0 +1,223 @@
+// Package taxrule supports CRUD operations on the tax and fee rules charged on
+// stays.
+package taxrule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrNotFound        = errors.New("tax rule not found")
+	ErrUnknownProperty = errors.New("tax rule property not found")
+)
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Create adds a rule. Rules round half up unless told otherwise.
+func (s *Store) Create(ctx context.Context, nr NewRule) (Rule, error) {
+	if err := validation.Check(nr); err != nil {
+		return Rule{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	rounding := nr.Rounding
+	if rounding == "" {
+		rounding = RoundHalfUp
+	}
+
+	now := time.Now()
+	r := Rule{
+		ID:           uuid.New(),
+		PropertyID:   nr.PropertyID,
+		Jurisdiction: nr.Jurisdiction,
+		Name:         nr.Name,
+		Category:     nr.Category,
+		Kind:         nr.Kind,
+		Amount:       nr.Amount,
+		Rounding:     rounding,
+		DateCreated:  now,
+		DateUpdated:  now,
+	}
+
+	const q = `
+		INSERT INTO tax_rules
+			(tax_rule_id, property_id, jurisdiction, name, category, kind, amount, rounding, date_created, date_updated)
+		VALUES
+			(:tax_rule_id, :property_id, :jurisdiction, :name, :category, :kind, :amount, :rounding, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, r); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return Rule{}, ErrUnknownProperty
+		}
+		return Rule{}, fmt.Errorf("inserting tax rule: %w", err)
+	}
+
+	return r, nil
+}
+
+func (s *Store) Update(ctx context.Context, r Rule, ur UpdateRule) (Rule, error) {
+	if err := validation.Check(ur); err != nil {
+		return Rule{}, fmt.Errorf("validating data: %w", err)
+	}
+
+	if ur.Name != nil {
+		r.Name = *ur.Name
+	}
+	if ur.Amount != nil {
+		r.Amount = *ur.Amount
+	}
+	if ur.Rounding != nil {
+		r.Rounding = *ur.Rounding
+	}
+	r.DateUpdated = time.Now()
+
+	const q = `
+		UPDATE
+			tax_rules
+		SET
+			"name" = :name,
+			"amount" = :amount,
+			"rounding" = :rounding,
+			"date_updated" = :date_updated
+		WHERE
+			tax_rule_id = :tax_rule_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, r); err != nil {
+		return Rule{}, fmt.Errorf("updating taxRuleID[%s]: %w", r.ID, err)
+	}
+
+	return r, nil
+}
+
+func (s *Store) Delete(ctx context.Context, r Rule) error {
+	data := struct {
+		TaxRuleID string `db:"tax_rule_id"`
+	}{
+		TaxRuleID: r.ID.String(),
+	}
+
+	const q = `
+		DELETE FROM
+			tax_rules
+		WHERE
+			tax_rule_id = :tax_rule_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("deleting taxRuleID[%s]: %w", r.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, taxRuleID uuid.UUID) (Rule, error) {
+	data := struct {
+		TaxRuleID string `db:"tax_rule_id"`
+	}{
+		TaxRuleID: taxRuleID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			tax_rules
+		WHERE
+			tax_rule_id = :tax_rule_id
+		`
+
+	var r Rule
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &r); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Rule{}, ErrNotFound
+		}
+		return Rule{}, fmt.Errorf("selecting taxRuleID[%q]: %w", taxRuleID, err)
+	}
+
+	return r, nil
+}
+
+// QueryByPropertyID returns every rule charged on stays at the property:
+// its own rules and those of its jurisdiction, in the order they were made.
+func (s *Store) QueryByPropertyID(ctx context.Context, propertyID uuid.UUID) ([]Rule, error) {
+	data := struct {
+		PropertyID string `db:"property_id"`
+	}{
+		PropertyID: propertyID.String(),
+	}
+
+	const q = `
+		SELECT
+			r.*
+		FROM
+			tax_rules AS r
+		JOIN
+			properties AS p ON p.property_id = :property_id
+		WHERE
+			r.property_id = p.property_id OR r.jurisdiction = p.jurisdiction
+		ORDER BY
+			r.date_created, r.tax_rule_id
+		`
+
+	var rs []Rule
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rs); err != nil {
+		return nil, fmt.Errorf("selecting tax rules propertyID[%q]: %w", propertyID, err)
+	}
+
+	return rs, nil
+}
+
+// QueryByJurisdiction returns the rules of the jurisdiction.
+func (s *Store) QueryByJurisdiction(ctx context.Context, jurisdiction string) ([]Rule, error) {
+	data := struct {
+		Jurisdiction string `db:"jurisdiction"`
+	}{
+		Jurisdiction: jurisdiction,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			tax_rules
+		WHERE
+			jurisdiction = :jurisdiction
+		ORDER BY
+			date_created, tax_rule_id
+		`
+
+	var rs []Rule
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &rs); err != nil {
+		return nil, fmt.Errorf("selecting tax rules jurisdiction[%q]: %w", jurisdiction, err)
+	}
+
+	return rs, nil
+}
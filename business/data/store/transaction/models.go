@@ -0,0 +1,31 @@
+package transaction
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of kinds of request sent to the payment gateway.
+const (
+	KindAuthorize = "AUTHORIZE"
+	KindCapture   = "CAPTURE"
+	KindVoid      = "VOID"
+	KindRefund    = "REFUND"
+)
+
+// Transaction is a request the payment gateway accepted, on behalf of either
+// a reservation or a sale. GatewayRef is the gateway's reference for it and
+// IdempotencyKey the key it was sent with.
+type Transaction struct {
+	ID             uuid.UUID  `db:"transaction_id" json:"id"`
+	ReservationID  *uuid.UUID `db:"reservation_id" json:"reservationID,omitempty"`
+	SaleID         *uuid.UUID `db:"sale_id" json:"saleID,omitempty"`
+	Kind           string     `db:"kind" json:"kind"`
+	Amount         int        `db:"amount" json:"amount"`
+	Currency       string     `db:"currency" json:"currency"`
+	GatewayRef     string     `db:"gateway_ref" json:"gatewayRef"`
+	IdempotencyKey string     `db:"idempotency_key" json:"-"`
+	UserID         uuid.UUID  `db:"user_id" json:"userID"`
+	DateCreated    time.Time  `db:"date_created" json:"dateCreated"`
+}
@@ -0,0 +1,172 @@
+// Package transaction supports storing the payment transactions sent to the
+// payment gateway.
+package transaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("payment transaction not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+// Create records a transaction. Retrying a gateway request replays its
+// idempotency key, so a transaction already recorded under the same key is
+// left as it is.
+func (s *Store) Create(ctx context.Context, t Transaction) error {
+	const q = `
+		INSERT INTO payment_transactions
+			(transaction_id, reservation_id, sale_id, kind, amount, currency, gateway_ref, idempotency_key, user_id, date_created)
+		VALUES
+			(:transaction_id, :reservation_id, :sale_id, :kind, :amount, :currency, :gateway_ref, :idempotency_key, :user_id, :date_created)
+		ON CONFLICT (idempotency_key) DO NOTHING
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, t); err != nil {
+		return fmt.Errorf("inserting transaction: %w", err)
+	}
+
+	return nil
+}
+
+// QueryByIdempotencyKey returns the transaction recorded under the key.
+func (s *Store) QueryByIdempotencyKey(ctx context.Context, key string) (Transaction, error) {
+	data := struct {
+		Key string `db:"idempotency_key"`
+	}{
+		Key: key,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			payment_transactions
+		WHERE
+			idempotency_key = :idempotency_key
+		`
+
+	var t Transaction
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &t); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Transaction{}, ErrNotFound
+		}
+		return Transaction{}, fmt.Errorf("selecting transaction key[%q]: %w", key, err)
+	}
+
+	return t, nil
+}
+
+// QueryLatest returns the most recent transaction of the given kind for the
+// reservation.
+func (s *Store) QueryLatest(ctx context.Context, reservationID uuid.UUID, kind string) (Transaction, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+		Kind          string `db:"kind"`
+	}{
+		ReservationID: reservationID.String(),
+		Kind:          kind,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			payment_transactions
+		WHERE
+			reservation_id = :reservation_id AND kind = :kind
+		ORDER BY
+			date_created DESC
+		LIMIT 1
+		`
+
+	var t Transaction
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &t); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Transaction{}, ErrNotFound
+		}
+		return Transaction{}, fmt.Errorf("selecting %s transaction reservationID[%q]: %w", kind, reservationID, err)
+	}
+
+	return t, nil
+}
+
+// QueryByReservationID returns every transaction of the reservation, oldest
+// first.
+func (s *Store) QueryByReservationID(ctx context.Context, reservationID uuid.UUID) ([]Transaction, error) {
+	data := struct {
+		ReservationID string `db:"reservation_id"`
+	}{
+		ReservationID: reservationID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			payment_transactions
+		WHERE
+			reservation_id = :reservation_id
+		ORDER BY
+			date_created
+		`
+
+	var ts []Transaction
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ts); err != nil {
+		return nil, fmt.Errorf("selecting transactions reservationID[%q]: %w", reservationID, err)
+	}
+
+	return ts, nil
+}
+
+// QueryBySaleID returns every transaction of the sale, oldest first.
+func (s *Store) QueryBySaleID(ctx context.Context, saleID uuid.UUID) ([]Transaction, error) {
+	data := struct {
+		SaleID string `db:"sale_id"`
+	}{
+		SaleID: saleID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			payment_transactions
+		WHERE
+			sale_id = :sale_id
+		ORDER BY
+			date_created
+		`
+
+	var ts []Transaction
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &ts); err != nil {
+		return nil, fmt.Errorf("selecting transactions saleID[%q]: %w", saleID, err)
+	}
+
+	return ts, nil
+}
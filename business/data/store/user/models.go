@@ -1,12 +1,17 @@
 package user
 
 import (
+	"database/sql"
 	"net/mail"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/tcmhoang/sservices/business/sys/database"
 )
 
+// User is an account. FailedLogins counts the wrong passwords given since the
+// last login, LockedUntil is set while the account is locked out for giving
+// too many.
 type User struct {
 	ID           uuid.UUID    `json:"id"`
 	Name         string       `json:"name"`
@@ -15,6 +20,8 @@ type User struct {
 	PasswordHash []byte       `json:"-"`
 	Department   string       `json:"department"`
 	Enabled      bool         `json:"enabled"`
+	FailedLogins int          `json:"failedLogins"`
+	LockedUntil  *time.Time   `json:"lockedUntil,omitempty"`
 	DateCreated  time.Time    `json:"dateCreated"`
 	DateUpdated  time.Time    `json:"dateUpdated"`
 }
@@ -37,3 +44,61 @@ type UpdateUser struct {
 	PasswordConfirm *string       `json:"passwordConfirm" validate:"omitempty,eqfield=Password"`
 	Enabled         *bool         `json:"enabled"`
 }
+
+// dbUser is a user as stored, in types the driver can scan.
+type dbUser struct {
+	ID           uuid.UUID            `db:"user_id"`
+	Name         string               `db:"name"`
+	Email        string               `db:"email"`
+	Roles        database.StringArray `db:"roles"`
+	PasswordHash []byte               `db:"password_hash"`
+	Department   sql.NullString       `db:"department"`
+	Enabled      bool                 `db:"enabled"`
+	FailedLogins int                  `db:"failed_logins"`
+	LockedUntil  *time.Time           `db:"locked_until"`
+	DateCreated  time.Time            `db:"date_created"`
+	DateUpdated  time.Time            `db:"date_updated"`
+}
+
+func toDBUser(usr User) dbUser {
+	return dbUser{
+		ID:           usr.ID,
+		Name:         usr.Name,
+		Email:        usr.Email.Address,
+		Roles:        database.StringArray(usr.Roles),
+		PasswordHash: usr.PasswordHash,
+		Department: sql.NullString{
+			String: usr.Department,
+			Valid:  usr.Department != "",
+		},
+		Enabled:      usr.Enabled,
+		FailedLogins: usr.FailedLogins,
+		LockedUntil:  usr.LockedUntil,
+		DateCreated:  usr.DateCreated,
+		DateUpdated:  usr.DateUpdated,
+	}
+}
+
+func toUser(dbu dbUser) User {
+	return User{
+		ID:           dbu.ID,
+		Name:         dbu.Name,
+		Email:        mail.Address{Address: dbu.Email},
+		Roles:        []string(dbu.Roles),
+		PasswordHash: dbu.PasswordHash,
+		Department:   dbu.Department.String,
+		Enabled:      dbu.Enabled,
+		FailedLogins: dbu.FailedLogins,
+		LockedUntil:  dbu.LockedUntil,
+		DateCreated:  dbu.DateCreated,
+		DateUpdated:  dbu.DateUpdated,
+	}
+}
+
+func toUsers(dbus []dbUser) []User {
+	usrs := make([]User, len(dbus))
+	for i, dbu := range dbus {
+		usrs[i] = toUser(dbu)
+	}
+	return usrs
+}
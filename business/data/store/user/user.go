@@ -22,11 +22,12 @@ var (
 	ErrInvalidEmail = errors.New("email is not valid")
 	ErrUniqueEmail  = errors.New("email is not unique")
 	ErrForbidden    = errors.New("forbidden operation")
+	ErrInUse        = errors.New("user still has reservations")
 )
 
 type Store struct {
 	log *zap.SugaredLogger
-	db  *sqlx.DB
+	db  sqlx.ExtContext
 }
 
 func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
@@ -36,6 +37,14 @@ func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
 	}
 }
 
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
 func (s *Store) Create(ctx context.Context, nu NewUser) (User, error) {
 
 	if err := validation.Check(nu); err != nil {
@@ -63,12 +72,12 @@ func (s *Store) Create(ctx context.Context, nu NewUser) (User, error) {
 
 	const q = `
 		INSERT INTO users
-			(user_id, name, email, password_hash, roles, enabled, date_created, date_updated)
+			(user_id, name, email, password_hash, roles, department, enabled, failed_logins, locked_until, date_created, date_updated)
 		VALUES
-			(:user_id, :name, :email, :password_hash, :roles, :enabled, :date_created, :date_updated)
+			(:user_id, :name, :email, :password_hash, :roles, :department, :enabled, :failed_logins, :locked_until, :date_created, :date_updated)
 		`
 
-	if err := database.NamedExecContext(ctx, s.log, s.db, q, usr); err != nil {
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, database.ErrDBDuplicatedEntry) {
 			return User{}, fmt.Errorf("create: %w", ErrUniqueEmail)
 		}
@@ -116,12 +125,14 @@ func (s *Store) Update(ctx context.Context, usr User, uu UpdateUser) (User, erro
 			"email" = :email,
 			"roles" = :roles,
 			"password_hash" = :password_hash,
+			"department" = :department,
+			"enabled" = :enabled,
 			"date_updated" = :date_updated
 		WHERE
 			user_id = :user_id
 		`
 
-	if err := database.NamedExecContext(ctx, s.log, s.db, q, usr); err != nil {
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
 		if errors.Is(err, database.ErrDBDuplicatedEntry) {
 			return User{}, ErrUniqueEmail
 		}
@@ -151,6 +162,9 @@ func (s *Store) Delete(ctx context.Context, claims auth.Claims, usr User) error
 		`
 
 	if err := database.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		if errors.Is(err, database.ErrDBForeignKey) {
+			return ErrInUse
+		}
 		return fmt.Errorf("deleting userID[%s]: %w", usr.ID, err)
 	}
 
@@ -177,12 +191,12 @@ func (s *Store) Query(ctx context.Context, pageNumber int, rowsPerPage int) ([]U
 	OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY
 	`
 
-	var usrs []User
-	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &usrs); err != nil {
+	var dbus []dbUser
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &dbus); err != nil {
 		return nil, fmt.Errorf("selecting users: %w", err)
 	}
 
-	return usrs, nil
+	return toUsers(dbus), nil
 }
 
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
@@ -200,24 +214,34 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (User, error) {
 		WHERE 
 			user_id = :user_id
 		`
-	var usr User
-	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &usr); err != nil {
+	var dbu dbUser
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &dbu); err != nil {
 		if errors.Is(err, database.ErrDBNotFound) {
 			return User{}, ErrNotFound
 		}
 		return User{}, fmt.Errorf("selecting userID[%q]: %w", userID, err)
 	}
 
-	return usr, nil
+	return toUser(dbu), nil
 }
 
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (User, error) {
-	data := struct {
-		Email string `db:"email"`
-	}{
-		Email: email.Address,
-	}
+	const q = `
+		SELECT
+			*
+		FROM
+			users
+		WHERE
+			email = :email
+		`
+
+	return s.queryByEmail(ctx, q, email)
+}
 
+// QueryByEmailForUpdate retrieves the user with the email and locks its row
+// until the surrounding transaction ends, so concurrent logins are counted
+// one after the other. It must be called on a store returned by Tran.
+func (s *Store) QueryByEmailForUpdate(ctx context.Context, email mail.Address) (User, error) {
 	const q = `
 		SELECT
 			*
@@ -225,15 +249,45 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (User, err
 			users
 		WHERE
 			email = :email
+		FOR UPDATE
 		`
-	var usr User
-	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &usr); err != nil {
+
+	return s.queryByEmail(ctx, q, email)
+}
+
+// UpdateLogins records the failed logins and lockout of the user.
+func (s *Store) UpdateLogins(ctx context.Context, usr User) error {
+	const q = `
+		UPDATE
+			users
+		SET
+			"failed_logins" = :failed_logins,
+			"locked_until" = :locked_until
+		WHERE
+			user_id = :user_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, toDBUser(usr)); err != nil {
+		return fmt.Errorf("updating logins userID[%s]: %w", usr.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) queryByEmail(ctx context.Context, q string, email mail.Address) (User, error) {
+	data := struct {
+		Email string `db:"email"`
+	}{
+		Email: email.Address,
+	}
+
+	var dbu dbUser
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &dbu); err != nil {
 		if errors.Is(err, database.ErrDBNotFound) {
 			return User{}, ErrNotFound
 		}
 		return User{}, fmt.Errorf("selecting email[%q]: %w", email, err)
 	}
 
-	return usr, nil
-
+	return toUser(dbu), nil
 }
@@ -0,0 +1,46 @@
+package waitlist
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Set of statuses a waitlist entry can be in. Only WAITING entries are
+// offered rooms as they free up.
+const (
+	StatusWaiting   = "WAITING"
+	StatusOffered   = "OFFERED"
+	StatusFulfilled = "FULFILLED"
+	StatusExpired   = "EXPIRED"
+	StatusCancelled = "CANCELLED"
+)
+
+// OfferMinutes is how long the hold offered to a waitlisted guest keeps its
+// room for them.
+const OfferMinutes = 60
+
+// Entry is a guest waiting for a room type to free up for a stay. Once a
+// room frees up the entry is OFFERED a hold on it, HoldID, which the guest
+// confirms like any other hold.
+type Entry struct {
+	ID          uuid.UUID  `db:"waitlist_id" json:"id"`
+	PropertyID  uuid.UUID  `db:"property_id" json:"propertyID"`
+	RoomTypeID  uuid.UUID  `db:"room_type_id" json:"roomTypeID"`
+	UserID      uuid.UUID  `db:"user_id" json:"userID"`
+	CheckIn     time.Time  `db:"check_in" json:"checkIn"`
+	CheckOut    time.Time  `db:"check_out" json:"checkOut"`
+	Guests      int        `db:"guests" json:"guests"`
+	Status      string     `db:"status" json:"status"`
+	HoldID      *uuid.UUID `db:"hold_id" json:"holdID,omitempty"`
+	DateCreated time.Time  `db:"date_created" json:"dateCreated"`
+	DateUpdated time.Time  `db:"date_updated" json:"dateUpdated"`
+}
+
+type NewEntry struct {
+	RoomTypeID uuid.UUID `json:"roomTypeID" validate:"required"`
+	CheckIn    time.Time `json:"checkIn" validate:"required"`
+	CheckOut   time.Time `json:"checkOut" validate:"required,gtfield=CheckIn"`
+	Guests     int       `json:"guests" validate:"required,gte=1"`
+	UserID     uuid.UUID `json:"userID"`
+}
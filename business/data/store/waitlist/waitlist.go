@@ -0,0 +1,217 @@
+// Package waitlist supports storing the guests waiting for sold out room
+// types to free up.
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/tcmhoang/sservices/business/sys/database"
+	"go.uber.org/zap"
+)
+
+var ErrNotFound = errors.New("waitlist entry not found")
+
+type Store struct {
+	log *zap.SugaredLogger
+	db  sqlx.ExtContext
+}
+
+func NewStore(log *zap.SugaredLogger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Tran returns a copy of the store that runs its queries inside tx.
+func (s *Store) Tran(tx sqlx.ExtContext) Store {
+	return Store{
+		log: s.log,
+		db:  tx,
+	}
+}
+
+func (s *Store) Create(ctx context.Context, e Entry) error {
+	const q = `
+		INSERT INTO waitlist
+			(waitlist_id, property_id, room_type_id, user_id, check_in, check_out, guests, status, hold_id, date_created, date_updated)
+		VALUES
+			(:waitlist_id, :property_id, :room_type_id, :user_id, :check_in, :check_out, :guests, :status, :hold_id, :date_created, :date_updated)
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, e); err != nil {
+		return fmt.Errorf("inserting waitlist entry: %w", err)
+	}
+
+	return nil
+}
+
+// Update persists the status and offered hold of an entry.
+func (s *Store) Update(ctx context.Context, e Entry) error {
+	const q = `
+		UPDATE
+			waitlist
+		SET
+			"status" = :status,
+			"hold_id" = :hold_id,
+			"date_updated" = :date_updated
+		WHERE
+			waitlist_id = :waitlist_id
+		`
+
+	if err := database.NamedExecContext(ctx, s.log, s.db, q, e); err != nil {
+		return fmt.Errorf("updating waitlistID[%s]: %w", e.ID, err)
+	}
+
+	return nil
+}
+
+func (s *Store) QueryByID(ctx context.Context, waitlistID uuid.UUID) (Entry, error) {
+	data := struct {
+		WaitlistID string `db:"waitlist_id"`
+	}{
+		WaitlistID: waitlistID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			waitlist
+		WHERE
+			waitlist_id = :waitlist_id
+		`
+	var e Entry
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &e); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Entry{}, ErrNotFound
+		}
+		return Entry{}, fmt.Errorf("selecting waitlistID[%q]: %w", waitlistID, err)
+	}
+
+	return e, nil
+}
+
+// QueryByIDForUpdate gets the specified entry and locks its row until the
+// surrounding transaction ends.
+func (s *Store) QueryByIDForUpdate(ctx context.Context, waitlistID uuid.UUID) (Entry, error) {
+	data := struct {
+		WaitlistID string `db:"waitlist_id"`
+	}{
+		WaitlistID: waitlistID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			waitlist
+		WHERE
+			waitlist_id = :waitlist_id
+		FOR UPDATE
+		`
+	var e Entry
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &e); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Entry{}, ErrNotFound
+		}
+		return Entry{}, fmt.Errorf("selecting waitlistID[%q] for update: %w", waitlistID, err)
+	}
+
+	return e, nil
+}
+
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]Entry, error) {
+	data := struct {
+		UserID string `db:"user_id"`
+	}{
+		UserID: userID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			waitlist
+		WHERE
+			user_id = :user_id
+		ORDER BY
+			date_created
+		`
+	var es []Entry
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &es); err != nil {
+		return nil, fmt.Errorf("selecting waitlist userID[%q]: %w", userID, err)
+	}
+
+	return es, nil
+}
+
+// QueryByHoldIDForUpdate gets the entry that was offered the specified hold
+// and locks its row until the surrounding transaction ends.
+func (s *Store) QueryByHoldIDForUpdate(ctx context.Context, holdID uuid.UUID) (Entry, error) {
+	data := struct {
+		HoldID string `db:"hold_id"`
+	}{
+		HoldID: holdID.String(),
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			waitlist
+		WHERE
+			hold_id = :hold_id
+		FOR UPDATE
+		`
+	var e Entry
+	if err := database.NamedQueryScalar(ctx, s.log, s.db, q, data, &e); err != nil {
+		if errors.Is(err, database.ErrDBNotFound) {
+			return Entry{}, ErrNotFound
+		}
+		return Entry{}, fmt.Errorf("selecting holdID[%q] for update: %w", holdID, err)
+	}
+
+	return e, nil
+}
+
+// QueryWaiting returns the WAITING entries for the room type whose stay
+// overlaps [checkIn, checkOut), oldest first, and locks their rows until the
+// surrounding transaction ends.
+func (s *Store) QueryWaiting(ctx context.Context, roomTypeID uuid.UUID, checkIn time.Time, checkOut time.Time) ([]Entry, error) {
+	data := struct {
+		RoomTypeID string    `db:"room_type_id"`
+		CheckIn    time.Time `db:"check_in"`
+		CheckOut   time.Time `db:"check_out"`
+	}{
+		RoomTypeID: roomTypeID.String(),
+		CheckIn:    checkIn,
+		CheckOut:   checkOut,
+	}
+
+	const q = `
+		SELECT
+			*
+		FROM
+			waitlist
+		WHERE
+			room_type_id = :room_type_id AND
+			status = 'WAITING' AND
+			check_in < :check_out AND
+			check_out > :check_in
+		ORDER BY
+			date_created, waitlist_id
+		FOR UPDATE
+		`
+	var es []Entry
+	if err := database.NamedQueryAggregation(ctx, s.log, s.db, q, data, &es); err != nil {
+		return nil, fmt.Errorf("selecting waiting entries roomTypeID[%q]: %w", roomTypeID, err)
+	}
+
+	return es, nil
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/tcmhoang/sservices/business/data/schema"
 	"github.com/tcmhoang/sservices/business/data/store/user"
@@ -184,6 +185,8 @@ func (s *State) Token(email string, pass string) string {
 			roles = append(roles, auth.Admin)
 		case "user":
 			roles = append(roles, auth.User)
+		case "housekeeping":
+			roles = append(roles, auth.Housekeeping)
 
 		default:
 
@@ -191,6 +194,7 @@ func (s *State) Token(email string, pass string) string {
 	}
 	claims := auth.Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			Subject:   user.ID.String(),
 			Issuer:    "service project",
 			ExpiresAt: jwt.NewNumericDate(time.Now().UTC().Add(time.Hour)),
@@ -2,9 +2,11 @@
 package auth
 
 import (
+	"context"
 	"crypto/rsa"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -14,17 +16,37 @@ type KeyLookup interface {
 	PublicKey(kid string) (*rsa.PublicKey, error)
 }
 
+// ErrRevoked is what a Revoker returns for a token that is no longer valid.
+var ErrRevoked = errors.New("token was revoked")
+
+// Revoker tells whether a token with a valid signature was revoked before it
+// expired.
+type Revoker interface {
+	Revoked(ctx context.Context, claims Claims) error
+}
+
 type Auth struct {
+	mu        sync.RWMutex
 	activeKID string
 	KeyLookup
 	method  jwt.SigningMethod
 	keyFunc func(t *jwt.Token) (interface{}, error)
 	parser  *jwt.Parser
+	revoker Revoker
 }
 
+// ErrNoSigningKey is returned when a token is generated by an Auth built to
+// only verify them.
+var ErrNoSigningKey = errors.New("auth has no active key to sign tokens with")
+
+// New builds an Auth that signs tokens with the key of activeKID and verifies
+// them with any key of kup. With an empty activeKID it only verifies them, so
+// kup needs no private keys at all.
 func New(activeKID string, kup KeyLookup) (*Auth, error) {
-	if _, err := kup.PrivateKey(activeKID); err != nil {
-		return nil, errors.New("active KID doesn't exist in store")
+	if activeKID != "" {
+		if _, err := kup.PrivateKey(activeKID); err != nil {
+			return nil, errors.New("active KID doesn't exist in store")
+		}
 	}
 
 	method := jwt.GetSigningMethod(jwt.SigningMethodRS256.Name)
@@ -56,11 +78,40 @@ func New(activeKID string, kup KeyLookup) (*Auth, error) {
 	return &out, nil
 }
 
+// ActiveKID returns the kid of the key new tokens are signed with.
+func (a *Auth) ActiveKID() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.activeKID
+}
+
+// SetActiveKID switches the key new tokens are signed with. Tokens signed
+// with the previous key stay valid for as long as the KeyLookup still has its
+// public key.
+func (a *Auth) SetActiveKID(kid string) error {
+	if _, err := a.PrivateKey(kid); err != nil {
+		return fmt.Errorf("kid[%s] cannot sign: %w", kid, err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.activeKID = kid
+
+	return nil
+}
+
 func (a *Auth) GenerateToken(c Claims) (string, error) {
+	activeKID := a.ActiveKID()
+	if activeKID == "" {
+		return "", ErrNoSigningKey
+	}
+
 	token := jwt.NewWithClaims(a.method, c)
-	token.Header["kid"] = a.activeKID
+	token.Header["kid"] = activeKID
 
-	privkey, err := a.PrivateKey(a.activeKID)
+	privkey, err := a.PrivateKey(activeKID)
 	if err != nil {
 		return "", errors.New("kid lookup private failed")
 	}
@@ -86,3 +137,18 @@ func (a *Auth) ValidateToken(tokenstr string) (Claims, error) {
 	}
 	return claims, nil
 }
+
+// SetRevoker makes Revoked consult r. It must be called before the Auth is
+// shared between goroutines.
+func (a *Auth) SetRevoker(r Revoker) {
+	a.revoker = r
+}
+
+// Revoked returns an error when the token the claims came from was revoked.
+// Without a revoker no token is.
+func (a *Auth) Revoked(ctx context.Context, claims Claims) error {
+	if a.revoker == nil {
+		return nil
+	}
+	return a.revoker.Revoked(ctx, claims)
+}
@@ -1,13 +1,20 @@
 package auth_test
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/tcmhoang/sservices/business/sys/auth"
+	"github.com/tcmhoang/sservices/foundation/keystore"
 )
 
 const (
@@ -73,6 +80,182 @@ func TestAuth(t *testing.T) {
 	}
 }
 
+func TestVerifyOnly(t *testing.T) {
+	t.Log("Given the need to verify tokens without the private keys.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen verifying with the published public keys.", testID)
+		{
+			const keyID = "TEST"
+			privkey, err := rsa.GenerateKey(rand.Reader, 2048)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to create private key: %v", failed, testID, err)
+			}
+
+			ks := keystore.NewMap(map[string]*rsa.PrivateKey{keyID: privkey})
+
+			signer, err := auth.New(keyID, ks)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to create a signing authenticator: %v", failed, testID, err)
+			}
+
+			var doc bytes.Buffer
+			if err := json.NewEncoder(&doc).Encode(ks.JWKS()); err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to encode the JWKS: %v", failed, testID, err)
+			}
+
+			pks, err := keystore.NewPublicJWKS(&doc)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to load the JWKS: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould be able to load the JWKS.", success, testID)
+
+			if _, err := auth.New(keyID, pks); err == nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould NOT sign with a store of public keys.", failed, testID)
+			}
+
+			verifier, err := auth.New("", pks)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to create a verifying authenticator: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould be able to create a verifying authenticator.", success, testID)
+
+			claims := auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    "test",
+					Subject:   "TEST",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour).UTC()),
+					IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+				},
+				Roles: []auth.Role{auth.User},
+			}
+
+			token, err := signer.GenerateToken(claims)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to generate the claims: %v", failed, testID, err)
+			}
+
+			parsedClaims, err := verifier.ValidateToken(token)
+			if err != nil || parsedClaims.Subject != claims.Subject {
+				t.Fatalf("\t%s\tTest: %d:\tShould verify the token with the public key: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould verify the token with the public key.", success, testID)
+
+			if _, err := verifier.GenerateToken(claims); !errors.Is(err, auth.ErrNoSigningKey) {
+				t.Fatalf("\t%s\tTest: %d:\tShould NOT be able to sign tokens: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould NOT be able to sign tokens.", success, testID)
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	t.Log("Given the need to rotate the keys tokens are signed with.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen switching to a new key and retiring the old one.", testID)
+		{
+			keyFile := func() []byte {
+				privkey, err := rsa.GenerateKey(rand.Reader, 2048)
+				if err != nil {
+					t.Fatalf("\t%s\tTest: %d:\tShould be able to create private key: %v", failed, testID, err)
+				}
+				return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privkey)})
+			}
+
+			fsys := fstest.MapFS{
+				"old.pem": {Data: keyFile()},
+			}
+
+			ks, err := keystore.NewFS(fsys)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to load the keys: %v", failed, testID, err)
+			}
+
+			a, err := auth.New("old", ks)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to create an authenticator: %v", failed, testID, err)
+			}
+
+			claims := auth.Claims{
+				RegisteredClaims: jwt.RegisteredClaims{
+					Issuer:    "test",
+					Subject:   "TEST",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour).UTC()),
+					IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
+				},
+			}
+
+			oldToken, err := a.GenerateToken(claims)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to generate the claims: %v", failed, testID, err)
+			}
+
+			fsys["new.pem"] = &fstest.MapFile{Data: keyFile()}
+			fsys[keystore.ManifestFile] = &fstest.MapFile{Data: []byte(`{"active":"new","retired":{"old":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}}`)}
+
+			if err := ks.Reload(fsys, a.ActiveKID()); err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould be able to reload the keys: %v", failed, testID, err)
+			}
+
+			if err := a.SetActiveKID(ks.Active()); err != nil || a.ActiveKID() != "new" {
+				t.Fatalf("\t%s\tTest: %d:\tShould switch to the key the manifest names: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould switch to the key the manifest names.", success, testID)
+
+			newToken, err := a.GenerateToken(claims)
+			if err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould sign with the new key: %v", failed, testID, err)
+			}
+
+			for _, tkn := range []string{oldToken, newToken} {
+				if _, err := a.ValidateToken(tkn); err != nil {
+					t.Fatalf("\t%s\tTest: %d:\tShould verify tokens of both keys while they overlap: %v", failed, testID, err)
+				}
+			}
+			t.Logf("\t%s\tTest: %d:\tShould verify tokens of both keys while they overlap.", success, testID)
+
+			if err := a.SetActiveKID("old"); err == nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould NOT sign with a retired key.", failed, testID)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould NOT sign with a retired key.", success, testID)
+
+			ks.Retire("old", time.Now())
+
+			if _, err := a.ValidateToken(oldToken); err == nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould NOT verify with a key past its deadline.", failed, testID)
+			}
+			if _, err := a.ValidateToken(newToken); err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould still verify with the new key: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould NOT verify with a key past its deadline.", success, testID)
+
+			if got := ks.JWKS(); len(got.Keys) != 1 || got.Keys[0].Kid != "new" {
+				t.Fatalf("\t%s\tTest: %d:\tShould only publish the keys that verify: %+v", failed, testID, got)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould only publish the keys that verify.", success, testID)
+
+			fsys[keystore.ManifestFile] = &fstest.MapFile{Data: []byte(`{"active":"gone"}`)}
+			if err := ks.Reload(fsys, a.ActiveKID()); err == nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould refuse a manifest naming a missing key.", failed, testID)
+			}
+			if _, err := a.ValidateToken(newToken); err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould keep the keys after a failed reload: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould keep the keys after a failed reload.", success, testID)
+
+			fsys[keystore.ManifestFile] = &fstest.MapFile{Data: []byte(`{"retired":{"new":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}}`)}
+			if err := ks.Reload(fsys, a.ActiveKID()); err == nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould refuse a manifest retiring the active key without naming another.", failed, testID)
+			}
+			if _, err := a.GenerateToken(claims); err != nil {
+				t.Fatalf("\t%s\tTest: %d:\tShould keep signing with the active key: %v", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest: %d:\tShould refuse a manifest retiring the active key without naming another.", success, testID)
+		}
+	}
+}
+
 type keyStore struct {
 	pk *rsa.PrivateKey
 }
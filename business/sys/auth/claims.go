@@ -13,6 +13,7 @@ type Role int
 const (
 	Admin Role = iota
 	User
+	Housekeeping
 )
 
 type Claims struct {
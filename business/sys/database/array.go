@@ -0,0 +1,40 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// StringArray adapts a string slice to a Postgres TEXT[] column so it can be
+// used directly in models handled by sqlx.
+type StringArray []string
+
+func (a *StringArray) Scan(src any) error {
+	if src == nil {
+		*a = nil
+		return nil
+	}
+
+	var fa pgtype.FlatArray[string]
+	if err := pgtype.NewMap().SQLScanner(&fa).Scan(src); err != nil {
+		return fmt.Errorf("scanning text array: %w", err)
+	}
+	*a = StringArray(fa)
+
+	return nil
+}
+
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	buf, err := pgtype.NewMap().Encode(pgtype.TextArrayOID, pgtype.TextFormatCode, []string(a), nil)
+	if err != nil {
+		return nil, fmt.Errorf("encoding text array: %w", err)
+	}
+
+	return string(buf), nil
+}
@@ -0,0 +1,30 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tcmhoang/sservices/business/sys/database"
+)
+
+func TestStringArray(t *testing.T) {
+	in := database.StringArray{"wifi", "sea view", `say "hi"`, "a,b"}
+
+	v, err := in.Value()
+	if err != nil {
+		t.Fatalf("Should be able to encode the array : %s", err)
+	}
+
+	var out database.StringArray
+	if err := out.Scan(v); err != nil {
+		t.Fatalf("Should be able to decode the array : %s", err)
+	}
+
+	if diff := cmp.Diff(in, out); diff != "" {
+		t.Fatalf("Should get back the same array, diff:\n%s", diff)
+	}
+
+	if err := out.Scan([]byte("{}")); err != nil || len(out) != 0 {
+		t.Fatalf("Should decode an empty array : %v %s", out, err)
+	}
+}
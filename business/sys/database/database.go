@@ -19,13 +19,15 @@ import (
 )
 
 const (
-	uniqueViolationCode = "23505"
-	undefinedTableCode  = "42P01"
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+	undefinedTableCode      = "42P01"
 )
 
 var (
 	ErrDBNotFound        = sql.ErrNoRows
 	ErrDBDuplicatedEntry = errors.New("duplicated entry")
+	ErrDBForeignKey      = errors.New("foreign key violation")
 	ErrUndefinedTable    = errors.New("undefined table")
 )
 
@@ -101,6 +103,40 @@ func StatusCheck(ctx context.Context, db *sqlx.DB) error {
 	return db.QueryRowContext(ctx, qraw).Scan(&tmp)
 }
 
+// WithinTran runs fn inside a transaction. The transaction is committed when
+// fn succeeds and rolled back otherwise.
+func WithinTran(ctx context.Context, log *zap.SugaredLogger, db *sqlx.DB, fn func(tx sqlx.ExtContext) error) (rerr error) {
+	traceID := web.GetTraceID(ctx)
+
+	log.Infow("database.WithinTran", "traceid", traceID, "status", "begin tran")
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tran: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil {
+			if errors.Is(err, sql.ErrTxDone) {
+				return
+			}
+			rerr = fmt.Errorf("rollback tran: %w", err)
+			return
+		}
+		log.Infow("database.WithinTran", "traceid", traceID, "status", "rollback tran")
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tran: %w", err)
+	}
+	log.Infow("database.WithinTran", "traceid", traceID, "status", "commit tran")
+
+	return nil
+}
+
 func NamedExecContext[A any](ctx context.Context, log *zap.SugaredLogger, db sqlx.ExtContext, query string, data A) error {
 	q := queryString(query, data)
 	log.Infow("database.NamedExecuteContext", "traceid", web.GetTraceID(ctx), "query", q)
@@ -115,6 +151,8 @@ func NamedExecContext[A any](ctx context.Context, log *zap.SugaredLogger, db sql
 				return ErrUndefinedTable
 			case uniqueViolationCode:
 				return ErrDBDuplicatedEntry
+			case foreignKeyViolationCode:
+				return ErrDBForeignKey
 			}
 		}
 		return err
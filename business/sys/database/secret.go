@@ -0,0 +1,19 @@
+package database
+
+import "database/sql/driver"
+
+// Secret is a string column that must not show up in logs or traces, such as
+// an identity document number. It is stored and sent to clients as is, but
+// prints masked, so queries logged by this package never reveal it.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "****"
+}
+
+func (s Secret) Value() (driver.Value, error) {
+	return string(s), nil
+}
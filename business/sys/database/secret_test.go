@@ -0,0 +1,36 @@
+package database_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/tcmhoang/sservices/business/sys/database"
+)
+
+func TestSecret(t *testing.T) {
+	data := struct {
+		DocumentNumber database.Secret `json:"documentNumber"`
+	}{
+		DocumentNumber: "B1234567",
+	}
+
+	if s := fmt.Sprintf("'%v' %+v", data.DocumentNumber, data); strings.Contains(s, "B1234567") {
+		t.Fatalf("Should mask the secret when printed : %s", s)
+	}
+
+	v, err := data.DocumentNumber.Value()
+	if err != nil || v != "B1234567" {
+		t.Fatalf("Should store the secret as is : %v %v", v, err)
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil || string(b) != `{"documentNumber":"B1234567"}` {
+		t.Fatalf("Should encode the secret as is : %s %v", b, err)
+	}
+
+	if s := database.Secret("").String(); s != "" {
+		t.Fatalf("Should print an empty secret as empty : %q", s)
+	}
+}
@@ -0,0 +1,15 @@
+// Package date provides the calendar days stays, periods and schedules are
+// counted in.
+package date
+
+import "time"
+
+// Layout is the format of a calendar day in requests and documents.
+const Layout = "2006-01-02"
+
+// Truncate drops the time of day of t, returning midnight UTC of its
+// calendar day, so stays are always counted in whole nights.
+func Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,164 @@
+// Package money provides an amount of money that carries its currency, so
+// amounts in different currencies are never mixed up.
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// exponents holds the ISO 4217 currencies whose minor unit is not a
+// hundredth of the major unit.
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0,
+	"XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyError is returned when arithmetic mixes amounts in different
+// currencies.
+type CurrencyError struct {
+	Want string
+	Got  string
+}
+
+func (e *CurrencyError) Error() string {
+	return fmt.Sprintf("currency mismatch: %s and %s", e.Want, e.Got)
+}
+
+// Money is an amount in the minor units of an ISO 4217 currency, such as
+// cents for USD. Money entered through the API is never negative.
+//
+// It is stored in a Postgres money_amount column, a composite of the amount
+// and the currency.
+type Money struct {
+	Amount   int    `json:"amount" validate:"gte=0"`
+	Currency string `json:"currency" validate:"required,iso4217"`
+}
+
+func New(amount int, currency string) Money {
+	return Money{
+		Amount:   amount,
+		Currency: currency,
+	}
+}
+
+// Add returns the sum of m and o, which must be in the same currency.
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.same(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount+o.Amount, m.Currency), nil
+}
+
+// Sub returns m less o, which must be in the same currency.
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.same(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount-o.Amount, m.Currency), nil
+}
+
+// Mul returns m n times over, like the price of n units.
+func (m Money) Mul(n int) Money {
+	return New(m.Amount*n, m.Currency)
+}
+
+// Convert returns m in another currency at rate, the number of major units of
+// that currency one major unit of m's currency is worth. The result is
+// rounded to the nearest minor unit, halves away from zero.
+func (m Money) Convert(currency string, rate *big.Rat) Money {
+	v := new(big.Rat).SetInt64(int64(m.Amount))
+	v.Mul(v, rate)
+	v.Mul(v, pow10(Exponent(currency)-Exponent(m.Currency)))
+
+	q, r := new(big.Int).QuoRem(v.Num(), v.Denom(), new(big.Int))
+	if r.Sign() != 0 {
+		r.Abs(r).Lsh(r, 1)
+		if r.Cmp(v.Denom()) >= 0 {
+			q.Add(q, big.NewInt(int64(v.Sign())))
+		}
+	}
+
+	return New(int(q.Int64()), currency)
+}
+
+// Exponent is the number of decimal digits of the minor unit of the currency,
+// 2 for cents.
+func Exponent(currency string) int {
+	if e, ok := exponents[currency]; ok {
+		return e
+	}
+	return 2
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+}
+
+func (m *Money) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("scanning money: unsupported type %T", src)
+	}
+
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return fmt.Errorf("scanning money: malformed value %q", s)
+	}
+
+	amount, currency, ok := strings.Cut(s[1:len(s)-1], ",")
+	if !ok {
+		return fmt.Errorf("scanning money: malformed value %q", s)
+	}
+
+	n, err := strconv.Atoi(amount)
+	if err != nil {
+		return fmt.Errorf("scanning money: amount: %w", err)
+	}
+
+	*m = New(n, currency)
+
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return fmt.Sprintf("(%d,%s)", m.Amount, m.Currency), nil
+}
+
+// pow10 is 10 to the power of n, n may be negative.
+func pow10(n int) *big.Rat {
+	p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n))), nil)
+	if n < 0 {
+		return new(big.Rat).SetFrac(big.NewInt(1), p)
+	}
+	return new(big.Rat).SetInt(p)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// same checks that o is in the currency of m.
+func (m Money) same(o Money) error {
+	if m.Currency != o.Currency {
+		return &CurrencyError{Want: m.Currency, Got: o.Currency}
+	}
+	return nil
+}
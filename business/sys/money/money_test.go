@@ -0,0 +1,155 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/validation"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestArithmetic(t *testing.T) {
+	usd := money.New(150, "USD")
+
+	table := []struct {
+		name string
+		fn   func() (money.Money, error)
+		exp  money.Money
+		err  bool
+	}{
+		{"add", func() (money.Money, error) { return usd.Add(money.New(50, "USD")) }, money.New(200, "USD"), false},
+		{"sub", func() (money.Money, error) { return usd.Sub(money.New(200, "USD")) }, money.New(-50, "USD"), false},
+		{"mul", func() (money.Money, error) { return usd.Mul(3), nil }, money.New(450, "USD"), false},
+		{"add across currencies", func() (money.Money, error) { return usd.Add(money.New(50, "EUR")) }, money.Money{}, true},
+		{"sub across currencies", func() (money.Money, error) { return usd.Sub(money.New(50, "VND")) }, money.Money{}, true},
+	}
+
+	t.Log("Given the need to do arithmetic on money.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got, err := tt.fn()
+
+				var ce *money.CurrencyError
+				if tt.err != errors.As(err, &ce) {
+					t.Fatalf("\t%s\tTest %d:\tShould reject only mismatched currencies : %v.", failed, testID, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould reject only mismatched currencies.", success, testID)
+
+				if got != tt.exp {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould get the expected amount.", failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould get the expected amount.", success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rate := func(s string) *big.Rat {
+		r, _ := new(big.Rat).SetString(s)
+		return r
+	}
+
+	table := []struct {
+		name string
+		in   money.Money
+		to   string
+		rate string
+		exp  money.Money
+	}{
+		{"cents to cents", money.New(10000, "USD"), "EUR", "0.92", money.New(9200, "EUR")},
+		{"rounds to the nearest cent", money.New(1999, "USD"), "EUR", "0.9137", money.New(1826, "EUR")},
+		{"rounds halves up", money.New(1, "USD"), "EUR", "0.5", money.New(1, "EUR")},
+		{"rounds below half down", money.New(1, "USD"), "EUR", "0.49", money.New(0, "EUR")},
+		{"rounds negative halves away from zero", money.New(-1, "USD"), "EUR", "0.5", money.New(-1, "EUR")},
+		{"cents to a currency without minor units", money.New(1050, "USD"), "VND", "25000", money.New(262500, "VND")},
+		{"a currency without minor units to cents", money.New(262500, "VND"), "USD", "0.00004", money.New(1050, "USD")},
+		{"cents to thousandths", money.New(100, "USD"), "KWD", "0.307", money.New(307, "KWD")},
+		{"thousandths to yen", money.New(1000, "KWD"), "JPY", "485.5", money.New(486, "JPY")},
+		{"zero", money.New(0, "USD"), "EUR", "0.92", money.New(0, "EUR")},
+	}
+
+	t.Log("Given the need to convert money between currencies.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got := tt.in.Convert(tt.to, rate(tt.rate))
+
+				if got != tt.exp {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould convert %v at %s.", failed, testID, tt.in, tt.rate)
+				}
+				t.Logf("\t%s\tTest %d:\tShould convert %v at %s.", success, testID, tt.in, tt.rate)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	in := money.New(1999, "EUR")
+
+	t.Log("Given the need to store and send money.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen encoding and decoding an amount.", testID)
+		{
+			v, err := in.Value()
+			if err != nil || v != "(1999,EUR)" {
+				t.Fatalf("\t%s\tTest %d:\tShould encode the amount for the database : %v %v.", failed, testID, v, err)
+			}
+
+			var out money.Money
+			if err := out.Scan([]byte(v.(string))); err != nil || out != in {
+				t.Fatalf("\t%s\tTest %d:\tShould scan back the same amount : %v %v.", failed, testID, out, err)
+			}
+			if err := out.Scan("(-5,USD)"); err != nil || out != money.New(-5, "USD") {
+				t.Fatalf("\t%s\tTest %d:\tShould scan a negative amount : %v %v.", failed, testID, out, err)
+			}
+			if err := out.Scan("1999"); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT scan a bare amount.", failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould round trip through the database.", success, testID)
+
+			data, err := json.Marshal(in)
+			if err != nil || string(data) != `{"amount":1999,"currency":"EUR"}` {
+				t.Fatalf("\t%s\tTest %d:\tShould marshal to JSON : %s %v.", failed, testID, data, err)
+			}
+
+			var back money.Money
+			if err := json.Unmarshal(data, &back); err != nil || back != in {
+				t.Fatalf("\t%s\tTest %d:\tShould unmarshal from JSON : %v %v.", failed, testID, back, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould round trip through JSON.", success, testID)
+		}
+
+		testID++
+		t.Logf("\tTest %d:\tWhen validating amounts.", testID)
+		{
+			if err := validation.Check(in); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould accept a valid amount : %s.", failed, testID, err)
+			}
+			if err := validation.Check(money.New(-1, "USD")); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT accept a negative amount.", failed, testID)
+			}
+			if err := validation.Check(money.New(1, "XYZ")); err == nil {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT accept an unknown currency.", failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould validate amounts.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Payment sources the fake gateway knows. Any other source is approved.
+const (
+	SourceApproved     = "tok_approved"
+	SourceDeclined     = "tok_declined"
+	SourceInsufficient = "tok_insufficient_funds"
+)
+
+// authorization is money the fake gateway set aside on a source.
+type authorization struct {
+	amount   int
+	captured bool
+	voided   bool
+}
+
+// capture is money the fake gateway collected.
+type capture struct {
+	amount   int
+	refunded int
+}
+
+// outcome is what the fake gateway answered to an idempotency key.
+type outcome struct {
+	res Result
+	err error
+}
+
+// Fake is a gateway that keeps everything in memory. It behaves like a real
+// provider for the checks the service relies on: declines, idempotency keys,
+// capturing no more than was authorized and refunding no more than was
+// captured.
+type Fake struct {
+	mu       sync.Mutex
+	seq      int
+	outcomes map[string]outcome
+	auths    map[string]*authorization
+	captures map[string]*capture
+}
+
+func NewFake() *Fake {
+	return &Fake{
+		outcomes: make(map[string]outcome),
+		auths:    make(map[string]*authorization),
+		captures: make(map[string]*capture),
+	}
+}
+
+func (f *Fake) Authorize(ctx context.Context, key string, source string, amount int, currency string) (Result, error) {
+	return f.do(key, func() (Result, error) {
+		switch source {
+		case SourceDeclined:
+			return Result{}, &DeclineError{Code: "card_declined", Reason: "the card was declined"}
+		case SourceInsufficient:
+			return Result{}, &DeclineError{Code: "insufficient_funds", Reason: "the card has insufficient funds"}
+		}
+
+		if amount < 1 || currency == "" {
+			return Result{}, ErrInvalidAmount
+		}
+
+		id := f.id("auth")
+		f.auths[id] = &authorization{amount: amount}
+
+		return Result{ID: id, Amount: amount}, nil
+	})
+}
+
+func (f *Fake) Capture(ctx context.Context, key string, authorizationID string, amount int) (Result, error) {
+	return f.do(key, func() (Result, error) {
+		a, ok := f.auths[authorizationID]
+		if !ok || a.captured || a.voided {
+			return Result{}, ErrUnknownAuthorization
+		}
+
+		if amount < 1 || amount > a.amount {
+			return Result{}, ErrInvalidAmount
+		}
+
+		a.captured = true
+
+		id := f.id("cap")
+		f.captures[id] = &capture{amount: amount}
+
+		return Result{ID: id, Amount: amount}, nil
+	})
+}
+
+func (f *Fake) Void(ctx context.Context, key string, authorizationID string) (Result, error) {
+	return f.do(key, func() (Result, error) {
+		a, ok := f.auths[authorizationID]
+		if !ok || a.captured || a.voided {
+			return Result{}, ErrUnknownAuthorization
+		}
+
+		a.voided = true
+
+		return Result{ID: f.id("void"), Amount: a.amount}, nil
+	})
+}
+
+func (f *Fake) Refund(ctx context.Context, key string, captureID string, amount int) (Result, error) {
+	return f.do(key, func() (Result, error) {
+		c, ok := f.captures[captureID]
+		if !ok {
+			return Result{}, ErrUnknownCapture
+		}
+
+		if amount < 1 || c.refunded+amount > c.amount {
+			return Result{}, ErrInvalidAmount
+		}
+
+		c.refunded += amount
+
+		return Result{ID: f.id("ref"), Amount: amount}, nil
+	})
+}
+
+// do runs fn once per idempotency key and replays its outcome afterwards.
+func (f *Fake) do(key string, fn func() (Result, error)) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if o, ok := f.outcomes[key]; ok {
+		return o.res, o.err
+	}
+
+	res, err := fn()
+	f.outcomes[key] = outcome{res: res, err: err}
+
+	return res, err
+}
+
+// id hands out the next reference of the given kind.
+func (f *Fake) id(kind string) string {
+	f.seq++
+	return fmt.Sprintf("fake_%s_%d", kind, f.seq)
+}
@@ -0,0 +1,99 @@
+package payment_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/tcmhoang/sservices/business/sys/payment"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestFakeDecline(t *testing.T) {
+	table := []struct {
+		name   string
+		source string
+		code   string
+	}{
+		{"approved", payment.SourceApproved, ""},
+		{"declined", payment.SourceDeclined, "card_declined"},
+		{"insufficient", payment.SourceInsufficient, "insufficient_funds"},
+	}
+
+	t.Log("Given the need to authorize payment sources.")
+	{
+		for testID, tt := range table {
+			t.Logf("\tTest %d:\tWhen authorizing with the %s source.", testID, tt.name)
+			{
+				gw := payment.NewFake()
+				_, err := gw.Authorize(context.Background(), "k", tt.source, 100, "USD")
+
+				var de *payment.DeclineError
+				switch {
+				case tt.code == "" && err != nil:
+					t.Fatalf("\t%s\tTest %d:\tShould approve the source : %s.", failed, testID, err)
+				case tt.code != "" && (!errors.As(err, &de) || de.Code != tt.code):
+					t.Fatalf("\t%s\tTest %d:\tShould decline with %s : %v.", failed, testID, tt.code, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould answer with the expected outcome.", success, testID)
+			}
+		}
+	}
+}
+
+func TestFakeLifecycle(t *testing.T) {
+	ctx := context.Background()
+	gw := payment.NewFake()
+
+	t.Log("Given the need to move money through the gateway.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen authorizing, capturing and refunding.", testID)
+		{
+			auth, err := gw.Authorize(ctx, "auth-1", payment.SourceApproved, 300, "USD")
+			if err != nil || auth.Amount != 300 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to authorize : %+v %v.", failed, testID, auth, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to authorize.", success, testID)
+
+			again, err := gw.Authorize(ctx, "auth-1", payment.SourceApproved, 300, "USD")
+			if err != nil || again.ID != auth.ID {
+				t.Fatalf("\t%s\tTest %d:\tShould replay a repeated idempotency key : %+v %v.", failed, testID, again, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould replay a repeated idempotency key.", success, testID)
+
+			if _, err := gw.Capture(ctx, "cap-0", auth.ID, 301); !errors.Is(err, payment.ErrInvalidAmount) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT capture more than was authorized : %v.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT capture more than was authorized.", success, testID)
+
+			cpt, err := gw.Capture(ctx, "cap-1", auth.ID, 250)
+			if err != nil || cpt.Amount != 250 {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to capture part of the authorization : %+v %v.", failed, testID, cpt, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to capture part of the authorization.", success, testID)
+
+			if _, err := gw.Void(ctx, "void-1", auth.ID); !errors.Is(err, payment.ErrUnknownAuthorization) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT void a captured authorization : %v.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT void a captured authorization.", success, testID)
+
+			if _, err := gw.Refund(ctx, "ref-1", cpt.ID, 200); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to refund : %v.", failed, testID, err)
+			}
+			if _, err := gw.Refund(ctx, "ref-1", cpt.ID, 200); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould replay a repeated refund : %v.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould refund once per idempotency key.", success, testID)
+
+			if _, err := gw.Refund(ctx, "ref-2", cpt.ID, 51); !errors.Is(err, payment.ErrInvalidAmount) {
+				t.Fatalf("\t%s\tTest %d:\tShould NOT refund more than was captured : %v.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould NOT refund more than was captured.", success, testID)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package payment defines what the service needs from a payment provider and
+// ships an in-process fake of one for development and tests.
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownAuthorization = errors.New("authorization is unknown to the gateway")
+	ErrUnknownCapture       = errors.New("capture is unknown to the gateway")
+	ErrInvalidAmount        = errors.New("amount is outside what the gateway can settle")
+)
+
+// DeclineError is returned when the provider refuses to move the money, such
+// as a card with insufficient funds. It is the guest's problem to solve, not
+// a failure of the service.
+type DeclineError struct {
+	Code   string
+	Reason string
+}
+
+func (e *DeclineError) Error() string {
+	return fmt.Sprintf("payment declined: %s: %s", e.Code, e.Reason)
+}
+
+// Result is what the provider recorded for one request. ID is the provider's
+// reference, used to capture, void or refund what the request created.
+type Result struct {
+	ID     string
+	Amount int
+}
+
+// Gateway is a payment provider. Money is first authorized on the guest's
+// payment source, then either captured or voided. Captured money can be
+// refunded.
+//
+// Every call carries an idempotency key. Repeating a call with a key the
+// gateway already saw returns the outcome of the first call without moving
+// any money again, so a request that failed halfway can be safely retried.
+type Gateway interface {
+	Authorize(ctx context.Context, key string, source string, amount int, currency string) (Result, error)
+	Capture(ctx context.Context, key string, authorizationID string, amount int) (Result, error)
+	Void(ctx context.Context, key string, authorizationID string) (Result, error)
+	Refund(ctx context.Context, key string, captureID string, amount int) (Result, error)
+}
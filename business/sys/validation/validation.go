@@ -16,7 +16,7 @@ var validate *validator.Validate
 var translator ut.Translator
 
 func init() {
-	validate := validator.New()
+	validate = validator.New()
 	translator, _ = ut.New(en.New(), en.New()).GetTranslator("en")
 
 	en_translations.RegisterDefaultTranslations(validate, translator)
@@ -31,6 +31,13 @@ func Authenticate(a *auth.Auth) web.Middleware {
 				return validation.NewRequestError(err, http.StatusUnauthorized)
 			}
 
+			if err := a.Revoked(ctx, claims); err != nil {
+				if errors.Is(err, auth.ErrRevoked) {
+					return validation.NewRequestError(err, http.StatusUnauthorized)
+				}
+				return fmt.Errorf("revoked: %w", err)
+			}
+
 			ctx = auth.SetClaims(ctx, claims)
 
 			return handler(ctx, w, r)
@@ -4,6 +4,11 @@ import (
 	"context"
 	"net/http"
 
+	hkcore "github.com/tcmhoang/sservices/business/core/housekeeping"
+	rescore "github.com/tcmhoang/sservices/business/core/reservation"
+	salecore "github.com/tcmhoang/sservices/business/core/sale"
+	"github.com/tcmhoang/sservices/business/sys/money"
+	"github.com/tcmhoang/sservices/business/sys/payment"
 	"github.com/tcmhoang/sservices/business/sys/validation"
 	"github.com/tcmhoang/sservices/foundation/web"
 	"go.uber.org/zap"
@@ -31,6 +36,31 @@ func Errors(log *zap.SugaredLogger) web.Middleware {
 						Error: act.Error(),
 					}
 					statuscode = act.Status
+				case *salecore.OversellError:
+					er = validation.ErrorResponse{
+						Error: act.Error(),
+					}
+					statuscode = http.StatusConflict
+				case *rescore.TransitionError:
+					er = validation.ErrorResponse{
+						Error: act.Error(),
+					}
+					statuscode = http.StatusConflict
+				case *hkcore.TransitionError:
+					er = validation.ErrorResponse{
+						Error: act.Error(),
+					}
+					statuscode = http.StatusConflict
+				case *money.CurrencyError:
+					er = validation.ErrorResponse{
+						Error: act.Error(),
+					}
+					statuscode = http.StatusConflict
+				case *payment.DeclineError:
+					er = validation.ErrorResponse{
+						Error: act.Error(),
+					}
+					statuscode = http.StatusPaymentRequired
 				default:
 					er = validation.ErrorResponse{
 						Error: http.StatusText(http.StatusInternalServerError),
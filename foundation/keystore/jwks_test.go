@@ -0,0 +1,126 @@
+package keystore_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/tcmhoang/sservices/foundation/keystore"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestJWK(t *testing.T) {
+	privkey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to create private key : %s", err)
+	}
+
+	valid := keystore.NewJWK("TEST", &privkey.PublicKey)
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	with := func(fn func(k *keystore.JWK)) keystore.JWK {
+		k := valid
+		fn(&k)
+		return k
+	}
+
+	table := []struct {
+		name string
+		jwk  keystore.JWK
+		err  bool
+	}{
+		{"valid key", valid, false},
+		{"wrong key type", with(func(k *keystore.JWK) { k.Kty = "EC" }), true},
+		{"modulus not base64url", with(func(k *keystore.JWK) { k.N = "not+base64/" }), true},
+		{"exponent not base64url", with(func(k *keystore.JWK) { k.E = "AQ==" }), true},
+		{"empty modulus", with(func(k *keystore.JWK) { k.N = "" }), true},
+		{"zero exponent", with(func(k *keystore.JWK) { k.E = b64([]byte{0}) }), true},
+		{"exponent too small", with(func(k *keystore.JWK) { k.E = b64([]byte{1}) }), true},
+		{"exponent too large", with(func(k *keystore.JWK) { k.E = b64([]byte{0x80, 0, 0, 0}) }), true},
+		{"largest exponent", with(func(k *keystore.JWK) { k.E = b64([]byte{0x7f, 0xff, 0xff, 0xff}) }), false},
+	}
+
+	t.Log("Given the need to decode the public keys of a JWKS.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				pub, err := tt.jwk.PublicKey()
+				if tt.err != (err != nil) {
+					t.Fatalf("\t%s\tTest %d:\tShould reject only invalid keys : %v.", failed, testID, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould reject only invalid keys.", success, testID)
+
+				if tt.jwk == valid && !pub.Equal(&privkey.PublicKey) {
+					t.Fatalf("\t%s\tTest %d:\tShould decode the key it was built from.", failed, testID)
+				}
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestReloadJWKS(t *testing.T) {
+	privkey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to create private key : %s", err)
+	}
+
+	sig := keystore.NewJWK("sig", &privkey.PublicKey)
+	unset := keystore.NewJWK("unset", &privkey.PublicKey)
+	unset.Use = ""
+	enc := keystore.JWK{Kty: "oct", Use: "enc", Kid: "enc"}
+
+	table := []struct {
+		name string
+		keys []keystore.JWK
+		kids []string
+		err  bool
+	}{
+		{"signing keys", []keystore.JWK{sig, unset}, []string{"sig", "unset"}, false},
+		{"skips keys of another use", []keystore.JWK{sig, enc}, []string{"sig"}, false},
+		{"invalid signing key", []keystore.JWK{sig, {Kty: "oct", Use: "sig", Kid: "bad"}}, nil, true},
+	}
+
+	t.Log("Given the need to load the keys of a JWKS document.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				doc, err := json.Marshal(keystore.JWKS{Keys: tt.keys})
+				if err != nil {
+					t.Fatalf("\t%s\tTest %d:\tShould be able to encode the JWKS : %s.", failed, testID, err)
+				}
+
+				ks, err := keystore.NewPublicJWKS(bytes.NewReader(doc))
+				if tt.err != (err != nil) {
+					t.Fatalf("\t%s\tTest %d:\tShould reject only documents with invalid signing keys : %v.", failed, testID, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould reject only documents with invalid signing keys.", success, testID)
+
+				if err != nil {
+					return
+				}
+
+				got := ks.JWKS()
+				if len(got.Keys) != len(tt.kids) {
+					t.Fatalf("\t%s\tTest %d:\tShould load only the signing keys : %+v.", failed, testID, got)
+				}
+				for i, kid := range tt.kids {
+					if got.Keys[i].Kid != kid {
+						t.Fatalf("\t%s\tTest %d:\tShould load only the signing keys : %+v.", failed, testID, got)
+					}
+				}
+				t.Logf("\t%s\tTest %d:\tShould load only the signing keys.", success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 // Package keystore implements the auth.KeyLookup interface. This implements
 // an in-memory keystore for JWT support.
+//
+// Keys rotate through the manifest of the keys folder: a new key is added to
+// the folder, made active so new tokens are signed with it, and the previous
+// one is retired. A retired key no longer signs but still verifies the tokens
+// it signed until its deadline.
 package keystore
 
 import (
@@ -11,30 +16,50 @@ import (
 	"path"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type KeyStore struct {
-	lock  sync.RWMutex
-	store map[string]*rsa.PrivateKey
+	lock    sync.RWMutex
+	store   map[string]*rsa.PrivateKey
+	active  string
+	retired map[string]time.Time
 }
 
 func New() *KeyStore {
 	return &KeyStore{
-		store: make(map[string]*rsa.PrivateKey),
+		store:   make(map[string]*rsa.PrivateKey),
+		retired: make(map[string]time.Time),
 	}
 }
 
 func NewMap(store map[string]*rsa.PrivateKey) *KeyStore {
 	return &KeyStore{
-		store: store,
+		store:   store,
+		retired: make(map[string]time.Time),
 	}
 }
 
+// NewFS loads every private key PEM file of fsys along with its manifest. A
+// key's kid is its file name without the .pem extension.
 func NewFS(fsys fs.FS) (*KeyStore, error) {
 	ks := New()
 
+	if err := ks.Reload(fsys, ""); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Reload replaces the keys and manifest of the store with those of fsys. When
+// the manifest names no active key, current keeps signing new tokens, so the
+// keys must still hold it unretired. On error the store is left as it was.
+func (ks *KeyStore) Reload(fsys fs.FS, current string) error {
+	store := make(map[string]*rsa.PrivateKey)
+
 	traverse := func(p string, de fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("walkdir failure: %w", err)
@@ -62,16 +87,55 @@ func NewFS(fsys fs.FS) (*KeyStore, error) {
 		if err != nil {
 			return fmt.Errorf("parsing auth private key: %w", err)
 		}
-		ks.store[strings.TrimSuffix(de.Name(), ".pem")] = parsedPrivKey
+		store[strings.TrimSuffix(de.Name(), ".pem")] = parsedPrivKey
 
 		return nil
 	}
 
 	if err := fs.WalkDir(fsys, ".", traverse); err != nil {
-		return nil, fmt.Errorf("walking dir: %w", err)
+		return fmt.Errorf("walking dir: %w", err)
 	}
 
-	return ks, nil
+	m, err := ReadManifest(fsys)
+	if err != nil {
+		return err
+	}
+
+	active := m.Active
+	if active == "" {
+		active = current
+	}
+
+	if active != "" {
+		if _, ok := store[active]; !ok {
+			return fmt.Errorf("active kid[%s] has no key file", active)
+		}
+		if _, ok := m.Retired[active]; ok {
+			return fmt.Errorf("active kid[%s] is retired", active)
+		}
+	}
+
+	retired := make(map[string]time.Time, len(m.Retired))
+	for kid, until := range m.Retired {
+		retired[kid] = until
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.store = store
+	ks.active = m.Active
+	ks.retired = retired
+
+	return nil
+}
+
+// Active returns the kid the manifest says signs new tokens, if it says so.
+func (ks *KeyStore) Active() string {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	return ks.active
 }
 
 func (ks *KeyStore) Add(privateKey *rsa.PrivateKey, kid string) {
@@ -79,6 +143,15 @@ func (ks *KeyStore) Add(privateKey *rsa.PrivateKey, kid string) {
 	defer ks.lock.Unlock()
 
 	ks.store[kid] = privateKey
+	delete(ks.retired, kid)
+}
+
+// Retire stops the key from signing. It verifies tokens until the deadline.
+func (ks *KeyStore) Retire(kid string, until time.Time) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.retired[kid] = until
 }
 
 func (ks *KeyStore) Remove(kid string) {
@@ -86,25 +159,62 @@ func (ks *KeyStore) Remove(kid string) {
 	defer ks.lock.Unlock()
 
 	delete(ks.store, kid)
+	delete(ks.retired, kid)
 }
 
+// PrivateKey returns the key to sign with. Retired keys sign nothing.
 func (ks *KeyStore) PrivateKey(kid string) (*rsa.PrivateKey, error) {
-	ks.lock.Lock()
-	defer ks.lock.Unlock()
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
 
 	privKey, found := ks.store[kid]
 	if !found {
 		return nil, errors.New("kid lookup failed")
 	}
 
+	if _, retired := ks.retired[kid]; retired {
+		return nil, fmt.Errorf("kid[%s] is retired", kid)
+	}
+
 	return privKey, nil
 }
 
+// PublicKey returns the key to verify with, as long as it is not retired past
+// its deadline.
 func (ks *KeyStore) PublicKey(kid string) (*rsa.PublicKey, error) {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
 
-	privKey, err := ks.PrivateKey(kid)
-	if err != nil {
-		return nil, err
+	privKey, found := ks.store[kid]
+	if !found || ks.expired(kid, time.Now()) {
+		return nil, errors.New("kid lookup failed")
 	}
+
 	return &privKey.PublicKey, nil
 }
+
+// JWKS describes the public halves of the keys of the store that still
+// verify tokens.
+func (ks *KeyStore) JWKS() JWKS {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	now := time.Now()
+
+	pubs := make(map[string]*rsa.PublicKey, len(ks.store))
+	for kid, privKey := range ks.store {
+		if ks.expired(kid, now) {
+			continue
+		}
+		pubs[kid] = &privKey.PublicKey
+	}
+
+	return newJWKS(pubs)
+}
+
+// expired tells whether the key is retired past its deadline. It must be
+// called holding the lock.
+func (ks *KeyStore) expired(kid string, now time.Time) bool {
+	until, retired := ks.retired[kid]
+	return retired && !now.Before(until)
+}
@@ -0,0 +1,76 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestFile is the file of a keys folder saying which key signs new tokens
+// and until when each retired key still verifies the tokens it signed.
+const ManifestFile = "keys.json"
+
+type Manifest struct {
+	Active  string               `json:"active,omitempty"`
+	Retired map[string]time.Time `json:"retired,omitempty"`
+}
+
+// ReadManifest reads the manifest of the keys folder. A folder without one
+// has an empty manifest.
+func ReadManifest(fsys fs.FS) (Manifest, error) {
+	m := Manifest{
+		Retired: make(map[string]time.Time),
+	}
+
+	file, err := fsys.Open(ManifestFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+		return Manifest{}, fmt.Errorf("opening manifest: %w", err)
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(io.LimitReader(file, 1024*1024)).Decode(&m); err != nil {
+		return Manifest{}, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	if m.Retired == nil {
+		m.Retired = make(map[string]time.Time)
+	}
+
+	return m, nil
+}
+
+// WriteManifest replaces the manifest of the keys folder. The file is
+// swapped in whole so a service reloading the folder never reads half of it.
+func WriteManifest(folder string, m Manifest) error {
+	tmp, err := os.CreateTemp(folder, ManifestFile+".*")
+	if err != nil {
+		return fmt.Errorf("creating manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "\t")
+
+	if err := enc.Encode(m); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encoding manifest: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(folder, ManifestFile)); err != nil {
+		return fmt.Errorf("replacing manifest: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package keystore
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestReadManifest(t *testing.T) {
+	until := time.Date(2030, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	table := []struct {
+		name string
+		fsys fstest.MapFS
+		exp  Manifest
+		err  bool
+	}{
+		{"missing manifest", fstest.MapFS{}, Manifest{Retired: map[string]time.Time{}}, false},
+		{"empty manifest", fstest.MapFS{ManifestFile: {Data: []byte(`{}`)}}, Manifest{Retired: map[string]time.Time{}}, false},
+		{"active and retired keys", fstest.MapFS{ManifestFile: {Data: []byte(`{"active":"new","retired":{"old":"2030-03-10T12:00:00Z"}}`)}}, Manifest{Active: "new", Retired: map[string]time.Time{"old": until}}, false},
+		{"malformed json", fstest.MapFS{ManifestFile: {Data: []byte(`{"active":`)}}, Manifest{}, true},
+		{"malformed deadline", fstest.MapFS{ManifestFile: {Data: []byte(`{"retired":{"old":"tomorrow"}}`)}}, Manifest{}, true},
+	}
+
+	t.Log("Given the need to read the manifest of a keys folder.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				got, err := ReadManifest(tt.fsys)
+				if tt.err != (err != nil) {
+					t.Fatalf("\t%s\tTest %d:\tShould reject only malformed manifests : %v.", failed, testID, err)
+				}
+				t.Logf("\t%s\tTest %d:\tShould reject only malformed manifests.", success, testID)
+
+				if !equal(got, tt.exp) {
+					t.Logf("\t\tTest %d:\tGot: %+v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %+v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould get the expected manifest.", failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould get the expected manifest.", success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	t.Log("Given the need to write the manifest of a keys folder.")
+	{
+		testID := 0
+		t.Logf("\tTest %d:\tWhen writing a manifest and reading it back.", testID)
+		{
+			folder := t.TempDir()
+
+			m := Manifest{
+				Active:  "new",
+				Retired: map[string]time.Time{"old": time.Date(2030, time.March, 10, 12, 0, 0, 0, time.UTC)},
+			}
+
+			if err := WriteManifest(folder, m); err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to write the manifest : %s.", failed, testID, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould be able to write the manifest.", success, testID)
+
+			got, err := ReadManifest(os.DirFS(folder))
+			if err != nil {
+				t.Fatalf("\t%s\tTest %d:\tShould be able to read the manifest : %s.", failed, testID, err)
+			}
+
+			if !equal(got, m) {
+				t.Logf("\t\tTest %d:\tGot: %+v", testID, got)
+				t.Logf("\t\tTest %d:\tExp: %+v", testID, m)
+				t.Fatalf("\t%s\tTest %d:\tShould read back what was written.", failed, testID)
+			}
+			t.Logf("\t%s\tTest %d:\tShould read back what was written.", success, testID)
+
+			entries, err := os.ReadDir(folder)
+			if err != nil || len(entries) != 1 || entries[0].Name() != ManifestFile {
+				t.Fatalf("\t%s\tTest %d:\tShould leave only the manifest in the folder : %v %v.", failed, testID, entries, err)
+			}
+			t.Logf("\t%s\tTest %d:\tShould leave only the manifest in the folder.", success, testID)
+		}
+	}
+}
+
+func TestExpired(t *testing.T) {
+	until := time.Date(2030, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	ks := New()
+	ks.Retire("old", until)
+
+	table := []struct {
+		name string
+		kid  string
+		now  time.Time
+		exp  bool
+	}{
+		{"before the deadline", "old", until.Add(-time.Nanosecond), false},
+		{"at the deadline", "old", until, true},
+		{"after the deadline", "old", until.Add(time.Nanosecond), true},
+		{"key not retired", "new", until.Add(time.Hour), false},
+	}
+
+	t.Log("Given the need to stop verifying with retired keys past their deadline.")
+	{
+		for testID, tt := range table {
+			tf := func(t *testing.T) {
+				if got := ks.expired(tt.kid, tt.now); got != tt.exp {
+					t.Logf("\t\tTest %d:\tGot: %v", testID, got)
+					t.Logf("\t\tTest %d:\tExp: %v", testID, tt.exp)
+					t.Fatalf("\t%s\tTest %d:\tShould tell whether the key expired.", failed, testID)
+				}
+				t.Logf("\t%s\tTest %d:\tShould tell whether the key expired.", success, testID)
+			}
+
+			t.Run(tt.name, tf)
+		}
+	}
+}
+
+// equal compares manifests with their deadlines as instants.
+func equal(a Manifest, b Manifest) bool {
+	if a.Active != b.Active || len(a.Retired) != len(b.Retired) {
+		return false
+	}
+
+	for kid, until := range a.Retired {
+		if !until.Equal(b.Retired[kid]) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,173 @@
+package keystore
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrVerifyOnly is returned when a private key is asked of a store that only
+// holds public keys.
+var ErrVerifyOnly = errors.New("keystore only holds public keys")
+
+// PublicKeyStore implements the auth.KeyLookup interface with public keys
+// alone. It verifies tokens but cannot sign them, for services that trust
+// the tokens another one issues.
+type PublicKeyStore struct {
+	lock  sync.RWMutex
+	store map[string]*rsa.PublicKey
+}
+
+func NewPublic() *PublicKeyStore {
+	return &PublicKeyStore{
+		store: make(map[string]*rsa.PublicKey),
+	}
+}
+
+// NewPublicFS loads every public key PEM file of fsys. A key's kid is its
+// file name without the .pem extension.
+func NewPublicFS(fsys fs.FS) (*PublicKeyStore, error) {
+	ks := NewPublic()
+
+	if err := ks.Reload(fsys); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// NewPublicJWKS loads the keys of a JWKS document, such as one fetched from
+// the well-known endpoint of the service issuing the tokens.
+func NewPublicJWKS(r io.Reader) (*PublicKeyStore, error) {
+	ks := NewPublic()
+
+	if err := ks.ReloadJWKS(r); err != nil {
+		return nil, err
+	}
+
+	return ks, nil
+}
+
+// Reload replaces the keys of the store with the public key PEM files of
+// fsys. On error the store is left as it was.
+func (ks *PublicKeyStore) Reload(fsys fs.FS) error {
+	store := make(map[string]*rsa.PublicKey)
+
+	traverse := func(p string, de fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walkdir failure: %w", err)
+		}
+
+		if de.IsDir() {
+			return nil
+		}
+		if path.Ext(p) != ".pem" {
+			return nil
+		}
+
+		file, err := fsys.Open(p)
+		if err != nil {
+			return fmt.Errorf("opening key file: %w", err)
+		}
+		defer file.Close()
+
+		pubKey, err := io.ReadAll(io.LimitReader(file, 1024*1024))
+		if err != nil {
+			return fmt.Errorf("reading auth public key: %w", err)
+		}
+
+		parsedPubKey, err := jwt.ParseRSAPublicKeyFromPEM(pubKey)
+		if err != nil {
+			return fmt.Errorf("parsing auth public key %s: %w", p, err)
+		}
+		store[strings.TrimSuffix(de.Name(), ".pem")] = parsedPubKey
+
+		return nil
+	}
+
+	if err := fs.WalkDir(fsys, ".", traverse); err != nil {
+		return fmt.Errorf("walking dir: %w", err)
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.store = store
+
+	return nil
+}
+
+// ReloadJWKS replaces the keys of the store with those of a JWKS document.
+// On error the store is left as it was.
+func (ks *PublicKeyStore) ReloadJWKS(r io.Reader) error {
+	var jwks JWKS
+	if err := json.NewDecoder(io.LimitReader(r, 1024*1024)).Decode(&jwks); err != nil {
+		return fmt.Errorf("decoding jwks: %w", err)
+	}
+
+	store := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		pub, err := k.PublicKey()
+		if err != nil {
+			return err
+		}
+		store[k.Kid] = pub
+	}
+
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.store = store
+
+	return nil
+}
+
+func (ks *PublicKeyStore) Add(publicKey *rsa.PublicKey, kid string) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	ks.store[kid] = publicKey
+}
+
+func (ks *PublicKeyStore) Remove(kid string) {
+	ks.lock.Lock()
+	defer ks.lock.Unlock()
+
+	delete(ks.store, kid)
+}
+
+func (ks *PublicKeyStore) PrivateKey(kid string) (*rsa.PrivateKey, error) {
+	return nil, ErrVerifyOnly
+}
+
+func (ks *PublicKeyStore) PublicKey(kid string) (*rsa.PublicKey, error) {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	pubKey, found := ks.store[kid]
+	if !found {
+		return nil, errors.New("kid lookup failed")
+	}
+
+	return pubKey, nil
+}
+
+// JWKS describes the public keys of the store.
+func (ks *PublicKeyStore) JWKS() JWKS {
+	ks.lock.RLock()
+	defer ks.lock.RUnlock()
+
+	return newJWKS(ks.store)
+}
@@ -31,3 +31,20 @@ func Respond[A any](ctx context.Context, w http.ResponseWriter, data A, statusCo
 
 	return nil
 }
+
+// RespondText sends text to the client as plain text, for responses meant to
+// be read or printed as is.
+func RespondText(ctx context.Context, w http.ResponseWriter, text string, statusCode int) error {
+
+	SetStatusCode(ctx, statusCode)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	w.WriteHeader(statusCode)
+
+	if _, err := w.Write([]byte(text)); err != nil {
+		return err
+	}
+
+	return nil
+}